@@ -4,6 +4,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"io"
 	"math"
 )
 
@@ -151,7 +152,9 @@ type Module interface {
 	// definitions in this module, keyed on export name.
 	ExportedFunctionDefinitions() map[string]FunctionDefinition
 
-	// TODO: Table
+	// ExportedTableDefinitions returns all the exported table definitions
+	// in this module, keyed on export name.
+	ExportedTableDefinitions() map[string]TableDefinition
 
 	// ExportedMemory returns a memory exported from this module or nil if it wasn't.
 	//
@@ -238,6 +241,21 @@ type MemoryDefinition interface {
 	Max() (uint32, bool)
 }
 
+// TableDefinition is a WebAssembly table exported in a module
+// (wazero.CompiledModule). Units are in elements.
+//
+// See https://www.w3.org/TR/2019/REC-wasm-core-1-20191205/#exports%E2%91%A0
+type TableDefinition interface {
+	ExportDefinition
+
+	// Min returns the possibly zero initial count of elements.
+	Min() uint32
+
+	// Max returns the possibly zero max count of elements, or false if
+	// unbounded.
+	Max() (uint32, bool)
+}
+
 // FunctionDefinition is a WebAssembly function exported in a module
 // (wazero.CompiledModule).
 //
@@ -525,9 +543,26 @@ type Memory interface {
 	// memory capacity, ex via "memory.grow"), the host slice is no longer
 	// shared. Those who need a stable view must set Wasm memory min=max, or
 	// use wazero.RuntimeConfig WithMemoryCapacityPages to ensure max is always
-	// allocated.
+	// allocated. Otherwise, use ReadInto to retain a copy.
 	Read(offset, byteCount uint32) ([]byte, bool)
 
+	// ReadInto copies up to len(dst) bytes from the underlying buffer at the
+	// offset into dst, stopping at the end of memory. This returns the count
+	// of bytes copied, or false if the offset is out of range.
+	//
+	// Unlike Read, dst doesn't alias memory, so it is safe to retain even if
+	// memory grows.
+	ReadInto(offset uint32, dst []byte) (uint32, bool)
+
+	// Reader returns an io.Reader of byteCount bytes starting at the offset,
+	// for example to stream a guest buffer to a file with io.Copy. Reads past
+	// the region return io.EOF.
+	//
+	// Each read copies from the current memory, so the reader remains valid
+	// if memory grows. If the region extends past the end of memory, reading
+	// stops there with io.ErrUnexpectedEOF.
+	Reader(offset, byteCount uint32) io.Reader
+
 	// WriteByte writes a single byte to the underlying buffer at the offset in or returns false if out of range.
 	WriteByte(offset uint32, v byte) bool
 
@@ -558,6 +593,10 @@ type Memory interface {
 	// Write writes the slice to the underlying buffer at the offset or returns false if out of range.
 	Write(offset uint32, v []byte) bool
 
+	// WritePartial writes as much of the slice as fits in the underlying buffer at the offset, returning the count of
+	// bytes written. Unlike Write, this returns zero instead of false when the offset is out of range.
+	WritePartial(offset uint32, v []byte) uint32
+
 	// WriteString writes the string to the underlying buffer at the offset or returns false if out of range.
 	WriteString(offset uint32, v string) bool
 }
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/fs"
 	"math"
+	"syscall"
 	"time"
 
 	"github.com/tetratelabs/wazero/api"
@@ -103,6 +104,92 @@ type RuntimeConfig interface {
 	// optimization flags passed to the compiler.
 	WithDebugInfoEnabled(bool) RuntimeConfig
 
+	// WithStackTrace toggles whether errors from trapped function calls carry
+	// the wasm call stack as an experimental.StackTraceError. Defaults to
+	// false.
+	//
+	// For example, when a guest calls A->B->C and C traps, the error can be
+	// inspected like so:
+	//
+	//	var stErr *experimental.StackTraceError
+	//	if errors.As(err, &stErr) {
+	//		for _, def := range stErr.Stack { // C, B, A
+	//			println(def.DebugName())
+	//		}
+	//	}
+	//
+	// Note: The error message includes a textual stack trace regardless of
+	// this setting. This is for callers who need the function definitions.
+	WithStackTrace(bool) RuntimeConfig
+
+	// WithZeroOnClose toggles whether closing a module zeroes the memory and
+	// globals it defined. Defaults to false.
+	//
+	// This is a security measure for modules which hold secrets in linear
+	// memory, ensuring they aren't readable after the module is closed, for
+	// example via a retained api.Memory.
+	//
+	// # Notes
+	//
+	//   - Imported memory and globals are not zeroed, as they are owned by
+	//     the module that exported them.
+	//   - Exported memory and globals are not zeroed while another module
+	//     imports them, as that module may still be running. They are also
+	//     not zeroed when that module closes later.
+	WithZeroOnClose(bool) RuntimeConfig
+
+	// WithMaxInstances limits the count of modules instantiated at the same
+	// time by the Runtime, including host modules. Defaults to zero, which is
+	// unlimited.
+	//
+	// Instantiating beyond the limit returns an error. Closing a module makes
+	// room for another. This protects hosts that instantiate modules on
+	// demand from unbounded resource use.
+	WithMaxInstances(n int) RuntimeConfig
+
+	// WithMemoryAllocator sets the function which allocates the linear
+	// memory defined by modules. Defaults to nil, which uses make.
+	//
+	// The allocator is called with the minimum length in bytes, on
+	// instantiation and when growing past the capacity of the current
+	// buffer, and the maximum length the memory can grow to. It must return
+	// a zeroed buffer of at least the minimum length, or instantiation fails
+	// and `memory.grow` returns -1 respectively. Returning a buffer with
+	// a larger capacity avoids calls on subsequent grows. For example, this
+	// allows reusing pooled buffers across instances or custom alignment.
+	//
+	// Note: The buffer is used until the module is closed or the memory
+	// grows past its capacity. Don't reuse it before then.
+	WithMemoryAllocator(func(min, max uint64) []byte) RuntimeConfig
+
+	// WithStrictClocks toggles whether clocks not configured with
+	// ModuleConfig.WithWalltime or ModuleConfig.WithNanotime (or their
+	// WithSys variants) are unsupported. Defaults to false, which uses
+	// deterministic fake clocks.
+	//
+	// When enabled, WASI functions return ENOTSUP instead of using an
+	// unconfigured clock, surfacing missing configuration early instead of
+	// silently returning fake time. This applies to "clock_res_get",
+	// "clock_time_get", clock subscriptions of "poll_oneoff", and the *_NOW
+	// flags of "fd_filestat_set_times" and "path_filestat_set_times".
+	//
+	// Note: This has no effect on "imports/go", as the Go runtime has no way
+	// to report an unsupported clock, so uses the fake clocks.
+	WithStrictClocks(bool) RuntimeConfig
+
+	// WithInterruptOnContextDone toggles whether a call to a guest function
+	// is interrupted when its context.Context is canceled or its deadline
+	// passes. Defaults to false, which runs guest code until it returns.
+	//
+	// When enabled, the interrupted call fails with an error that satisfies
+	// errors.Is with the context error, e.g. context.DeadlineExceeded.
+	//
+	// Note: Only the interpreter supports this, as compiled code doesn't
+	// check the context. NewRuntimeWithConfig panics when this is enabled on
+	// a configuration that isn't from NewRuntimeConfigInterpreter, including
+	// NewRuntimeConfig on platforms that support the compiler.
+	WithInterruptOnContextDone(bool) RuntimeConfig
+
 	// WithCompilationCache configures how runtime caches the compiled modules. In the default configuration, compilation results are
 	// only in-memory until Runtime.Close is closed, and not shareable by multiple Runtime.
 	//
@@ -133,6 +220,12 @@ type runtimeConfig struct {
 	memoryCapacityFromMax bool
 	engineKind            engineKind
 	dwarfDisabled         bool // negative as defaults to enabled
+	stackTrace            bool
+	zeroOnClose           bool
+	maxInstances          int
+	strictClocks          bool
+	interruptOnDone       bool
+	memoryAllocator       func(min, max uint64) []byte
 	newEngine             newEngine
 	cache                 CompilationCache
 }
@@ -227,6 +320,48 @@ func (c *runtimeConfig) WithDebugInfoEnabled(dwarfEnabled bool) RuntimeConfig {
 	return ret
 }
 
+// WithStackTrace implements RuntimeConfig.WithStackTrace
+func (c *runtimeConfig) WithStackTrace(stackTrace bool) RuntimeConfig {
+	ret := c.clone()
+	ret.stackTrace = stackTrace
+	return ret
+}
+
+// WithZeroOnClose implements RuntimeConfig.WithZeroOnClose
+func (c *runtimeConfig) WithZeroOnClose(zeroOnClose bool) RuntimeConfig {
+	ret := c.clone()
+	ret.zeroOnClose = zeroOnClose
+	return ret
+}
+
+// WithMaxInstances implements RuntimeConfig.WithMaxInstances
+func (c *runtimeConfig) WithMaxInstances(n int) RuntimeConfig {
+	ret := c.clone()
+	ret.maxInstances = n
+	return ret
+}
+
+// WithMemoryAllocator implements RuntimeConfig.WithMemoryAllocator
+func (c *runtimeConfig) WithMemoryAllocator(allocator func(min, max uint64) []byte) RuntimeConfig {
+	ret := c.clone()
+	ret.memoryAllocator = allocator
+	return ret
+}
+
+// WithStrictClocks implements RuntimeConfig.WithStrictClocks
+func (c *runtimeConfig) WithStrictClocks(strictClocks bool) RuntimeConfig {
+	ret := c.clone()
+	ret.strictClocks = strictClocks
+	return ret
+}
+
+// WithInterruptOnContextDone implements RuntimeConfig.WithInterruptOnContextDone
+func (c *runtimeConfig) WithInterruptOnContextDone(interruptOnDone bool) RuntimeConfig {
+	ret := c.clone()
+	ret.interruptOnDone = interruptOnDone
+	return ret
+}
+
 // CompiledModule is a WebAssembly module ready to be instantiated (Runtime.InstantiateModule) as an api.Module.
 //
 // In WebAssembly terminology, this is a decoded, validated, and possibly also compiled module. wazero avoids using
@@ -265,6 +400,21 @@ type CompiledModule interface {
 	// memory.
 	ExportedMemories() map[string]api.MemoryDefinition
 
+	// CustomSections returns all the custom sections in this module, in the
+	// order they were encoded, or nil if there are none. This excludes the
+	// "name" section, which is decoded into Name and function definitions.
+	//
+	// Note: Data is shared with the module, so must not be modified.
+	CustomSections() []CustomSection
+
+	// TrailingData returns the bytes after the last section of the binary,
+	// or nil if there are none.
+	//
+	// Note: This is always nil unless Runtime.CompileModule was called with
+	// a context from experimental.WithModuleLength, and the data is shared
+	// with the module, so must not be modified.
+	TrailingData() []byte
+
 	// Close releases all the allocated resources for this CompiledModule.
 	//
 	// Note: It is safe to call Close while having outstanding calls from an
@@ -272,6 +422,18 @@ type CompiledModule interface {
 	Close(context.Context) error
 }
 
+// CustomSection is a custom section of a CompiledModule, such as "producers"
+// or one an embedder uses to store metadata like build info or a signature.
+//
+// See https://www.w3.org/TR/2019/REC-wasm-core-1-20191205/#custom-section%E2%91%A0
+type CustomSection struct {
+	// Name is the name of the custom section, e.g. "producers".
+	Name string
+
+	// Data is the content of the custom section, excluding its name.
+	Data []byte
+}
+
 // compile-time check to ensure compiledModule implements CompiledModule
 var _ CompiledModule = &compiledModule{}
 
@@ -318,6 +480,19 @@ func (c *compiledModule) ExportedMemories() map[string]api.MemoryDefinition {
 	return c.module.ExportedMemories()
 }
 
+// CustomSections implements CompiledModule.CustomSections
+func (c *compiledModule) CustomSections() (ret []CustomSection) {
+	for _, cs := range c.module.CustomSections {
+		ret = append(ret, CustomSection{Name: cs.Name, Data: cs.Data})
+	}
+	return
+}
+
+// TrailingData implements CompiledModule.TrailingData
+func (c *compiledModule) TrailingData() []byte {
+	return c.module.TrailingData
+}
+
 // ModuleConfig configures resources needed by functions that have low-level interactions with the host operating
 // system. Using this, resources such as STDIN can be isolated, so that the same module can be safely instantiated
 // multiple times.
@@ -343,13 +518,22 @@ type ModuleConfig interface {
 	//
 	// Similar to os.Args and exec.Cmd Env, many implementations would expect a program name to be argv[0]. However, neither
 	// WebAssembly nor WebAssembly System Interfaces (WASI) define this. Regardless, you may choose to set the first
-	// argument to the same value set via WithName.
+	// argument to the same value set via WithName, or set it separately via WithProgramName.
 	//
 	// Note: This does not default to os.Args as that violates sandboxing.
 	//
 	// See https://linux.die.net/man/3/argv and https://en.wikipedia.org/wiki/Null-terminated_string
 	WithArgs(...string) ModuleConfig
 
+	// WithProgramName sets argv[0], the program name, which is prepended to
+	// any arguments set with WithArgs. Defaults to none, in which case argv
+	// is only what WithArgs sets.
+	//
+	// For example, the below results in argv of ["rotate", "angle=90"]:
+	//
+	//	config = config.WithProgramName("rotate").WithArgs("angle=90")
+	WithProgramName(string) ModuleConfig
+
 	// WithEnv sets an environment variable visible to a Module that imports functions. Defaults to none.
 	// Runtime.InstantiateModule errs if the key is empty or contains a NULL(0) or equals("") character.
 	//
@@ -397,6 +581,42 @@ type ModuleConfig interface {
 	// otherwise, is compiler-specific. See /RATIONALE.md for notes.
 	WithFS(fs.FS) ModuleConfig
 
+	// WithFSErrnoMapper assigns a function which maps errors returned by the
+	// file system configured by WithFS to a syscall.Errno. When it returns
+	// false, the default mapping applies, which maps unknown errors to EIO.
+	//
+	// This allows file systems with custom errors, such as network or object
+	// stores, to surface meaningful errors to the guest. For example:
+	//
+	//	config := wazero.NewModuleConfig().WithFS(bucketFS).
+	//		WithFSErrnoMapper(func(err error) (syscall.Errno, bool) {
+	//			if errors.Is(err, errBucketFull) {
+	//				return syscall.ENOSPC, true
+	//			}
+	//			return 0, false
+	//		})
+	//
+	// Note: The guest sees the errno of its ABI corresponding to the
+	// syscall.Errno, e.g. wasi_snapshot_preview1 ErrnoNospc for ENOSPC.
+	WithFSErrnoMapper(func(error) (syscall.Errno, bool)) ModuleConfig
+
+	// WithFSReadChunkSize limits the size of each read from a file, including
+	// stdin, to the given count of bytes. Defaults to zero, which reads each
+	// buffer the guest supplies in one call.
+	//
+	// This is useful for slow sources, such as network-backed readers, as
+	// large guest reads are split into bounded reads, and the host can stop
+	// reading between them when the context is done.
+	WithFSReadChunkSize(uint32) ModuleConfig
+
+	// WithPollSubscriptionLimit limits the count of subscriptions a single
+	// call to functions like "poll_oneoff" in "wasi_snapshot_preview1"
+	// accepts. Defaults to zero, which uses a limit of 1024.
+	//
+	// Calls with more subscriptions fail with EINVAL before allocating memory
+	// to process them, which prevents a guest from exhausting host memory.
+	WithPollSubscriptionLimit(uint32) ModuleConfig
+
 	// WithName configures the module name. Defaults to what was decoded from the name section.
 	WithName(string) ModuleConfig
 
@@ -433,6 +653,8 @@ type ModuleConfig interface {
 	//
 	//   - The caller is responsible to close any io.Reader they supply: It is not closed on api.Module Close.
 	//   - This does not default to os.Stdin as that both violates sandboxing and prevents concurrent modules.
+	//   - A reader which is a terminal, or implements Stat reporting fs.ModeCharDevice, is a character device to
+	//     functions like "fd_fdstat_get".
 	//
 	// See https://linux.die.net/man/3/stdin
 	WithStdin(io.Reader) ModuleConfig
@@ -460,8 +682,12 @@ type ModuleConfig interface {
 	//			return clock.walltime()
 	//		}, sys.ClockResolution(time.Microsecond.Nanoseconds()))
 	//
-	// Note: This does not default to time.Now as that violates sandboxing. Use
-	// WithSysWalltime for a usable implementation.
+	// # Notes
+	//
+	//   - This does not default to time.Now as that violates sandboxing. Use
+	//     WithSysWalltime for a usable implementation.
+	//   - Use sys.NewWalltime to share a time source with a host function,
+	//     such as "env.now".
 	WithWalltime(sys.Walltime, sys.ClockResolution) ModuleConfig
 
 	// WithSysWalltime uses time.Now for sys.Walltime with a resolution of 1us
@@ -510,10 +736,16 @@ type ModuleConfig interface {
 	//   - Some compilers implement sleep by looping on sys.Nanotime (e.g. Go).
 	//   - If you set this, you should probably set WithNanotime also.
 	//   - Use WithSysNanosleep for a usable implementation.
+	//   - A custom sys.Nanosleep can't be interrupted. If the context is done
+	//     first, `poll_oneoff` returns and the sleep finishes in the
+	//     background.
 	WithNanosleep(sys.Nanosleep) ModuleConfig
 
 	// WithSysNanosleep uses time.Sleep for sys.Nanosleep.
 	//
+	// Unlike WithNanosleep, `poll_oneoff` sleeps with a timer, which is
+	// stopped if the context is done first.
+	//
 	// See WithNanosleep
 	WithSysNanosleep() ModuleConfig
 
@@ -524,9 +756,67 @@ type ModuleConfig interface {
 	// "wasi_snapshot_preview1", "seed" in AssemblyScript standard "env", and
 	// "getRandomData" when runtime.GOOS is "js".
 	//
+	// To emulate a source that would block until there is enough entropy,
+	// return an error wrapping syscall.EAGAIN. "random_get" returns this to
+	// the guest as ErrnoAgain, so that it can retry.
+	//
+	// Guests usually derive temp-file names, such as the suffix mkstemp
+	// replaces "XXXXXX" with, from this source. Keeping the default or
+	// another deterministic reader makes those names reproducible in tests.
+	//
 	// Note: The caller is responsible to close any io.Reader they supply: It
 	// is not closed on api.Module Close.
 	WithRandSource(io.Reader) ModuleConfig
+
+	// WithExitCodePolicy configures how an exit code, such as from the
+	// "proc_exit" function in "wasi_snapshot_preview1", is returned to the
+	// caller. Defaults to return a sys.ExitError for any exit code.
+	//
+	// When set, the policy is called with the exit code instead, and its
+	// result is returned from api.Function Call and Runtime.InstantiateModule.
+	// Return nil to treat the exit code as success. In that case, Call returns
+	// zero for each result of the function, as it exited before returning.
+	//
+	// This example only treats exit code zero as success:
+	//	moduleConfig = moduleConfig.
+	//		WithExitCodePolicy(func(exitCode uint32) error {
+	//			if exitCode == 0 {
+	//				return nil
+	//			}
+	//			return fmt.Errorf("exit code %d", exitCode)
+	//		})
+	//
+	// Note: The module is closed regardless of the policy result.
+	WithExitCodePolicy(func(exitCode uint32) error) ModuleConfig
+
+	// WithExecutionBudget limits the cumulative wall-clock time of calls to
+	// exported functions of the module, for the lifetime of the module.
+	// Defaults to zero, which is unlimited.
+	//
+	// Once calls have run for the budget in total, the call that crossed it
+	// and any later call fail with an "execution budget exhausted" error.
+	// Time spent in start functions counts towards the budget.
+	//
+	// Note: Unless RuntimeConfig.WithInterruptOnContextDone is enabled, this
+	// doesn't interrupt a call in progress. Then, the call which crosses the
+	// budget runs to completion, so an infinite loop can't be stopped.
+	WithExecutionBudget(time.Duration) ModuleConfig
+
+	// WithMemoryGrowthLimit limits the memory of the module to grow by at
+	// most the given count of pages within each window of time. Defaults to
+	// zero pages, which is unlimited.
+	//
+	// Grows which would exceed the limit fail, as if the memory reached its
+	// maximum, until the window resets. For example, "memory.grow" returns -1.
+	// This throttles guests which rapidly grow memory.
+	//
+	// # Notes
+	//
+	//   - Instantiation fails when the module imports its memory, as the limit
+	//     would otherwise change the memory of the exporting module.
+	//   - This doesn't apply to a start function defined in the module's
+	//     start section, as it runs before the limit can be attached.
+	WithMemoryGrowthLimit(pages uint32, window time.Duration) ModuleConfig
 }
 
 type moduleConfig struct {
@@ -541,13 +831,35 @@ type moduleConfig struct {
 	nanotime           *sys.Nanotime
 	nanotimeResolution sys.ClockResolution
 	nanosleep          *sys.Nanosleep
-	args               [][]byte
+	// sysNanosleep is true when nanosleep was set by WithSysNanosleep.
+	sysNanosleep bool
+	args         [][]byte
+	// programName when non-nil is prepended to args as argv[0].
+	programName []byte
 	// environ is pair-indexed to retain order similar to os.Environ.
 	environ [][]byte
 	// environKeys allow overwriting of existing values.
 	environKeys map[string]int
 	// fs is the file system to open files with
 	fs fs.FS
+	// fsErrnoMapper when non-nil maps file system errors to a syscall.Errno.
+	fsErrnoMapper func(error) (syscall.Errno, bool)
+	// fsReadChunkSize when positive limits the size of each read from a file.
+	fsReadChunkSize uint32
+	// pollSubscriptionLimit when positive overrides the default limit of
+	// subscriptions per poll.
+	pollSubscriptionLimit uint32
+	// exitCodePolicy when non-nil converts an exit code to the error returned.
+	exitCodePolicy func(exitCode uint32) error
+	// executionBudget when positive limits the cumulative wall-clock time of
+	// calls.
+	executionBudget time.Duration
+	// memoryGrowthLimitPages when positive limits the pages grown within
+	// memoryGrowthLimitWindow.
+	memoryGrowthLimitPages  uint32
+	memoryGrowthLimitWindow time.Duration
+	// memoryGrowthNanotime when non-nil overrides platform.Nanotime for tests.
+	memoryGrowthNanotime func() int64
 }
 
 // NewModuleConfig returns a ModuleConfig that can be used for configuring module instantiation.
@@ -575,6 +887,13 @@ func (c *moduleConfig) WithArgs(args ...string) ModuleConfig {
 	return ret
 }
 
+// WithProgramName implements ModuleConfig.WithProgramName
+func (c *moduleConfig) WithProgramName(programName string) ModuleConfig {
+	ret := c.clone()
+	ret.programName = []byte(programName)
+	return ret
+}
+
 func toByteSlices(strings []string) (result [][]byte) {
 	if len(strings) == 0 {
 		return
@@ -675,12 +994,15 @@ func (c *moduleConfig) WithSysNanotime() ModuleConfig {
 func (c *moduleConfig) WithNanosleep(nanosleep sys.Nanosleep) ModuleConfig {
 	ret := *c // copy
 	ret.nanosleep = &nanosleep
+	ret.sysNanosleep = false
 	return &ret
 }
 
 // WithSysNanosleep implements ModuleConfig.WithSysNanosleep
 func (c *moduleConfig) WithSysNanosleep() ModuleConfig {
-	return c.WithNanosleep(platform.Nanosleep)
+	ret := c.WithNanosleep(platform.Nanosleep).(*moduleConfig)
+	ret.sysNanosleep = true
+	return ret
 }
 
 // WithRandSource implements ModuleConfig.WithRandSource
@@ -690,6 +1012,49 @@ func (c *moduleConfig) WithRandSource(source io.Reader) ModuleConfig {
 	return ret
 }
 
+// WithFSErrnoMapper implements ModuleConfig.WithFSErrnoMapper
+func (c *moduleConfig) WithFSErrnoMapper(mapper func(error) (syscall.Errno, bool)) ModuleConfig {
+	ret := c.clone()
+	ret.fsErrnoMapper = mapper
+	return ret
+}
+
+// WithFSReadChunkSize implements ModuleConfig.WithFSReadChunkSize
+func (c *moduleConfig) WithFSReadChunkSize(chunkSize uint32) ModuleConfig {
+	ret := c.clone()
+	ret.fsReadChunkSize = chunkSize
+	return ret
+}
+
+// WithPollSubscriptionLimit implements ModuleConfig.WithPollSubscriptionLimit
+func (c *moduleConfig) WithPollSubscriptionLimit(limit uint32) ModuleConfig {
+	ret := c.clone()
+	ret.pollSubscriptionLimit = limit
+	return ret
+}
+
+// WithExitCodePolicy implements ModuleConfig.WithExitCodePolicy
+func (c *moduleConfig) WithExitCodePolicy(policy func(exitCode uint32) error) ModuleConfig {
+	ret := c.clone()
+	ret.exitCodePolicy = policy
+	return ret
+}
+
+// WithExecutionBudget implements ModuleConfig.WithExecutionBudget
+func (c *moduleConfig) WithExecutionBudget(budget time.Duration) ModuleConfig {
+	ret := c.clone()
+	ret.executionBudget = budget
+	return ret
+}
+
+// WithMemoryGrowthLimit implements ModuleConfig.WithMemoryGrowthLimit
+func (c *moduleConfig) WithMemoryGrowthLimit(pages uint32, window time.Duration) ModuleConfig {
+	ret := c.clone()
+	ret.memoryGrowthLimitPages = pages
+	ret.memoryGrowthLimitWindow = window
+	return ret
+}
+
 // toSysContext creates a baseline wasm.Context configured by ModuleConfig.
 func (c *moduleConfig) toSysContext() (sysCtx *internalsys.Context, err error) {
 	var environ [][]byte // Intentionally doesn't pre-allocate to reduce logic to default to nil.
@@ -717,9 +1082,14 @@ func (c *moduleConfig) toSysContext() (sysCtx *internalsys.Context, err error) {
 		environ = append(environ, result)
 	}
 
-	return internalsys.NewContext(
+	args := c.args
+	if c.programName != nil {
+		args = append([][]byte{c.programName}, c.args...)
+	}
+
+	if sysCtx, err = internalsys.NewContext(
 		math.MaxUint32,
-		c.args,
+		args,
 		environ,
 		c.stdin,
 		c.stdout,
@@ -729,5 +1099,12 @@ func (c *moduleConfig) toSysContext() (sysCtx *internalsys.Context, err error) {
 		c.nanotime, c.nanotimeResolution,
 		c.nanosleep,
 		c.fs,
-	)
+	); err != nil {
+		return
+	}
+	sysCtx.FS().ErrnoMapper = c.fsErrnoMapper
+	sysCtx.FS().ReadChunkSize = c.fsReadChunkSize
+	sysCtx.PollSubscriptionLimit = c.pollSubscriptionLimit
+	sysCtx.SysNanosleep = c.sysNanosleep
+	return
 }
@@ -60,6 +60,51 @@ func TestRuntimeConfig(t *testing.T) {
 				dwarfDisabled: true, // dwarf is a more technical name and ok here.
 			},
 		},
+		{
+			name: "WithStackTrace",
+			with: func(c RuntimeConfig) RuntimeConfig {
+				return c.WithStackTrace(true)
+			},
+			expected: &runtimeConfig{
+				stackTrace: true,
+			},
+		},
+		{
+			name: "WithZeroOnClose",
+			with: func(c RuntimeConfig) RuntimeConfig {
+				return c.WithZeroOnClose(true)
+			},
+			expected: &runtimeConfig{
+				zeroOnClose: true,
+			},
+		},
+		{
+			name: "WithMaxInstances",
+			with: func(c RuntimeConfig) RuntimeConfig {
+				return c.WithMaxInstances(2)
+			},
+			expected: &runtimeConfig{
+				maxInstances: 2,
+			},
+		},
+		{
+			name: "WithStrictClocks",
+			with: func(c RuntimeConfig) RuntimeConfig {
+				return c.WithStrictClocks(true)
+			},
+			expected: &runtimeConfig{
+				strictClocks: true,
+			},
+		},
+		{
+			name: "WithInterruptOnContextDone",
+			with: func(c RuntimeConfig) RuntimeConfig {
+				return c.WithInterruptOnContextDone(true)
+			},
+			expected: &runtimeConfig{
+				interruptOnDone: true,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -198,6 +243,23 @@ func TestModuleConfig_toSysContext(t *testing.T) {
 				nil, // fs
 			),
 		},
+		{
+			name:  "WithProgramName",
+			input: base.WithArgs("a", "bc").WithProgramName("prog"),
+			expected: requireSysContext(t,
+				math.MaxUint32,              // max
+				[]string{"prog", "a", "bc"}, // args
+				nil,                         // environ
+				nil,                         // stdin
+				nil,                         // stdout
+				nil,                         // stderr
+				nil,                         // randSource
+				&wt, 1,                      // walltime, walltimeResolution
+				&nt, 1, // nanotime, nanotimeResolution
+				nil, // nanosleep
+				nil, // fs
+			),
+		},
 		{
 			name:  "WithArgs second call overwrites",
 			input: base.WithArgs("a", "bc").WithArgs("bc", "a"),
@@ -505,6 +567,19 @@ func TestModuleConfig_toSysContext_WithNanosleep(t *testing.T) {
 		}).(*moduleConfig).toSysContext()
 	require.NoError(t, err)
 	sysCtx.Nanosleep(2)
+	require.False(t, sysCtx.SysNanosleep)
+}
+
+func TestModuleConfig_toSysContext_WithSysNanosleep(t *testing.T) {
+	config := NewModuleConfig().WithSysNanosleep()
+	sysCtx, err := config.(*moduleConfig).toSysContext()
+	require.NoError(t, err)
+	require.True(t, sysCtx.SysNanosleep)
+
+	// Overriding the sleep function clears the flag.
+	sysCtx, err = config.WithNanosleep(func(int64) {}).(*moduleConfig).toSysContext()
+	require.NoError(t, err)
+	require.False(t, sysCtx.SysNanosleep)
 }
 
 func TestModuleConfig_toSysContext_Errors(t *testing.T) {
@@ -0,0 +1,78 @@
+package experimental
+
+import "context"
+
+// debuggerKey is a context.Context Value key. Its associated value is a
+// *Debugger.
+type debuggerKey struct{}
+
+// Debugger is returned by WithDebugger to observe and resume guests paused
+// by Break.
+type Debugger struct {
+	paused chan StackSnapshot
+	resume chan struct{}
+}
+
+// Paused receives the stack of the Wasm caller each time a host function
+// calls Break. The guest remains paused until Resume is called.
+func (d *Debugger) Paused() <-chan StackSnapshot {
+	return d.paused
+}
+
+// Resume continues the guest paused by Break. Only call it after receiving
+// from Paused.
+//
+// This doesn't block, so it is safe to call after the paused guest returned
+// because its context is done. Such a resume is discarded by the next Break.
+func (d *Debugger) Resume() {
+	select {
+	case d.resume <- struct{}{}:
+	default: // already resumed
+	}
+}
+
+// WithDebugger returns a context which allows host functions called with it
+// to pause the guest via Break, and the debugger to observe and resume it.
+//
+// The returned context also enables WithStackSnapshots, so that each pause
+// includes the caller's stack where the engine supports it.
+func WithDebugger(ctx context.Context) (context.Context, *Debugger) {
+	d := &Debugger{paused: make(chan StackSnapshot), resume: make(chan struct{}, 1)}
+	ctx = WithStackSnapshots(ctx)
+	return context.WithValue(ctx, debuggerKey{}, d), d
+}
+
+// Break pauses the guest until Debugger.Resume is called, effectively a
+// breakpoint at the current host function. This returns false without
+// pausing if the context was not derived from WithDebugger.
+//
+// # Notes
+//
+//   - This is only valid inside a host function call, using the context it
+//     was called with.
+//   - A guest only pauses at host function boundaries: the debugger cannot
+//     interrupt a function that doesn't call the host.
+//   - If the context is done before the pause is observed or resumed, this
+//     returns without waiting further.
+func Break(ctx context.Context) bool {
+	d, ok := ctx.Value(debuggerKey{}).(*Debugger)
+	if !ok {
+		return false
+	}
+	snapshot, _ := Stack(ctx)
+	// Discard any resume of a previous pause whose context was done.
+	select {
+	case <-d.resume:
+	default:
+	}
+	select {
+	case d.paused <- snapshot:
+	case <-ctx.Done():
+		return true
+	}
+	select {
+	case <-d.resume:
+	case <-ctx.Done():
+	}
+	return true
+}
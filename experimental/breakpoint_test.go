@@ -0,0 +1,150 @@
+package experimental_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	. "github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+)
+
+func TestBreak(t *testing.T) {
+	ctx := context.Background()
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter())
+	defer r.Close(ctx)
+
+	var paused bool
+	_, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, v uint32) {
+			paused = Break(ctx)
+		}).
+		Export("breakpoint").
+		Instantiate(ctx)
+	require.NoError(t, err)
+
+	// Define a function which hits a breakpoint, then returns a constant.
+	bin := binary.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{
+			{Params: []wasm.ValueType{wasm.ValueTypeI32}},
+			{Results: []wasm.ValueType{wasm.ValueTypeI32}},
+		},
+		ImportSection: []*wasm.Import{
+			{Module: "env", Name: "breakpoint", Type: wasm.ExternTypeFunc, DescFunc: 0},
+		},
+		FunctionSection: []wasm.Index{1},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{
+				wasm.OpcodeI32Const, 7,
+				wasm.OpcodeCall, 0,
+				wasm.OpcodeI32Const, 42,
+				wasm.OpcodeEnd,
+			}},
+		},
+		ExportSection: []*wasm.Export{{Name: "run", Type: wasm.ExternTypeFunc, Index: 1}},
+	})
+
+	mod, err := r.InstantiateModuleFromBinary(ctx, bin)
+	require.NoError(t, err)
+	run := mod.ExportedFunction("run")
+
+	t.Run("disabled", func(t *testing.T) {
+		results, err := run.Call(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{42}, results)
+		require.False(t, paused)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		debugCtx, debugger := WithDebugger(ctx)
+
+		type result struct {
+			results []uint64
+			err     error
+		}
+		done := make(chan result, 1)
+		go func() {
+			results, err := run.Call(debugCtx)
+			done <- result{results, err}
+		}()
+
+		// Inspect the guest while it is paused.
+		snapshot := <-debugger.Paused()
+		require.Equal(t, []string{"run"}, snapshot.Caller.ExportNames())
+		values := snapshot.Values
+		require.True(t, len(values) >= 1, "unexpected stack: %v", values)
+		require.Equal(t, uint64(7), values[len(values)-1])
+
+		select {
+		case <-done:
+			t.Fatal("guest completed while paused")
+		default:
+		}
+
+		debugger.Resume()
+		res := <-done
+		require.NoError(t, res.err)
+		require.Equal(t, []uint64{42}, res.results)
+		require.True(t, paused)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		debugCtx, _ := WithDebugger(ctx)
+		debugCtx, cancel := context.WithCancel(debugCtx)
+		cancel()
+
+		// Nothing observes the pause, so the done context releases the guest
+		// instead of blocking forever.
+		results, err := run.Call(debugCtx)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{42}, results)
+	})
+
+	t.Run("canceled after pause", func(t *testing.T) {
+		debugCtx, debugger := WithDebugger(ctx)
+		callCtx, cancel := context.WithCancel(debugCtx)
+		defer cancel()
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := run.Call(callCtx)
+			done <- err
+		}()
+
+		// Cancel once the pause is observed, which releases the guest.
+		<-debugger.Paused()
+		cancel()
+		require.NoError(t, <-done)
+
+		// Resume must not block, even though no guest is paused anymore.
+		resumed := make(chan struct{})
+		go func() {
+			debugger.Resume()
+			close(resumed)
+		}()
+		select {
+		case <-resumed:
+		case <-time.After(time.Second):
+			t.Fatal("Resume blocked")
+		}
+
+		// The stale resume doesn't release the next pause.
+		go func() {
+			_, err := run.Call(debugCtx)
+			done <- err
+		}()
+		<-debugger.Paused()
+		select {
+		case <-done:
+			t.Fatal("guest completed while paused")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		debugger.Resume()
+		require.NoError(t, <-done)
+	})
+}
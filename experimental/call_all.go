@@ -0,0 +1,45 @@
+package experimental
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// CallResult is the outcome of calling an exported function with CallAll.
+type CallResult struct {
+	// Name is the name the function is exported as.
+	Name string
+
+	// Results are the results of the call, or nil if it failed.
+	Results []uint64
+
+	// Err is the error the call returned, such as a trap.
+	Err error
+}
+
+// CallAll calls each function exported by the module with zero-valued
+// parameters, in order of export name, and returns the outcome of each call.
+//
+// This is for smoke testing or fuzzing modules: a failed call is reported in
+// its CallResult, and doesn't prevent calling the remaining functions.
+//
+// Note: A function which exits, such as via "proc_exit", closes the module.
+// Functions called after that report a sys.ExitError.
+func CallAll(ctx context.Context, mod api.Module) []CallResult {
+	defs := mod.ExportedFunctionDefinitions()
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]CallResult, 0, len(names))
+	for _, name := range names {
+		params := make([]uint64, len(defs[name].ParamTypes()))
+		res, err := mod.ExportedFunction(name).Call(ctx, params...)
+		results = append(results, CallResult{Name: name, Results: res, Err: err})
+	}
+	return results
+}
@@ -0,0 +1,59 @@
+package experimental_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	. "github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+)
+
+func TestCallAll(t *testing.T) {
+	// Define a module which exports a function that adds its parameters and
+	// another that traps.
+	bin := binary.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{
+			{
+				Params:  []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32},
+				Results: []wasm.ValueType{wasm.ValueTypeI32},
+			},
+			{},
+		},
+		FunctionSection: []wasm.Index{0, 1},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{
+				wasm.OpcodeLocalGet, 0,
+				wasm.OpcodeLocalGet, 1,
+				wasm.OpcodeI32Add,
+				wasm.OpcodeEnd,
+			}},
+			{Body: []byte{wasm.OpcodeUnreachable, wasm.OpcodeEnd}},
+		},
+		ExportSection: []*wasm.Export{
+			{Name: "trap", Type: wasm.ExternTypeFunc, Index: 1},
+			{Name: "add", Type: wasm.ExternTypeFunc, Index: 0},
+		},
+	})
+
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	mod, err := r.InstantiateModuleFromBinary(ctx, bin)
+	require.NoError(t, err)
+
+	results := CallAll(ctx, mod)
+	require.Equal(t, 2, len(results))
+
+	// Both functions are attempted, in order of name.
+	require.Equal(t, "add", results[0].Name)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, []uint64{0}, results[0].Results)
+
+	require.Equal(t, "trap", results[1].Name)
+	require.Nil(t, results[1].Results)
+	require.Contains(t, results[1].Err.Error(), "unreachable")
+}
@@ -0,0 +1,37 @@
+package experimental
+
+import (
+	"context"
+
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
+)
+
+// InstructionCounter is returned by WithInstructionCounter to read how many
+// instructions function calls made with its context executed.
+type InstructionCounter struct {
+	count uint64
+}
+
+// Count returns the total count of instructions executed so far. Read this
+// after api.Function Call returns.
+func (c *InstructionCounter) Count() uint64 {
+	return c.count
+}
+
+// WithInstructionCounter returns a context which counts the instructions
+// executed by api.Function Call, and the counter to read the total from.
+//
+// Unlike a fuel or gas limit, this is passive: it never stops execution.
+//
+// # Notes
+//
+//   - This is only implemented by the interpreter. Other engines leave the
+//     count at zero.
+//   - Instructions are counted after translation to the engine's internal
+//     representation, so the count approximates the Wasm instructions
+//     executed.
+//   - The counter must not be shared across concurrent calls.
+func WithInstructionCounter(ctx context.Context) (context.Context, *InstructionCounter) {
+	c := &InstructionCounter{}
+	return context.WithValue(ctx, wasmruntime.InstructionCounterKey{}, &c.count), c
+}
@@ -0,0 +1,58 @@
+package experimental_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	. "github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+)
+
+func TestWithInstructionCounter(t *testing.T) {
+	// Define a function which loops its i32 parameter times.
+	bin := binary.EncodeModule(&wasm.Module{
+		TypeSection:     []*wasm.FunctionType{{Params: []wasm.ValueType{wasm.ValueTypeI32}}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{
+				wasm.OpcodeLoop, 0x40,
+				wasm.OpcodeLocalGet, 0,
+				wasm.OpcodeI32Const, 1,
+				wasm.OpcodeI32Sub,
+				wasm.OpcodeLocalTee, 0,
+				wasm.OpcodeBrIf, 0,
+				wasm.OpcodeEnd,
+				wasm.OpcodeEnd,
+			}},
+		},
+		ExportSection: []*wasm.Export{{Name: "loop", Type: wasm.ExternTypeFunc, Index: 0}},
+	})
+
+	ctx := context.Background()
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter())
+	defer r.Close(ctx)
+
+	mod, err := r.InstantiateModuleFromBinary(ctx, bin)
+	require.NoError(t, err)
+	loop := mod.ExportedFunction("loop")
+
+	count := func(n uint64) uint64 {
+		ctx, counter := WithInstructionCounter(ctx)
+		_, err := loop.Call(ctx, n)
+		require.NoError(t, err)
+		return counter.Count()
+	}
+
+	// Each iteration executes five Wasm instructions, which may translate to
+	// a different count of operations.
+	n := uint64(100)
+	actual := count(n)
+	require.True(t, actual >= 3*n && actual <= 10*n, "count %d out of range", actual)
+
+	// The count grows with the iterations.
+	actual10 := count(10 * n)
+	require.True(t, actual10 >= 9*actual && actual10 <= 11*actual, "count %d out of range", actual10)
+}
@@ -0,0 +1,25 @@
+package experimental
+
+// FileListenerKey is a context.Context Value key. Its associated value should
+// be a FileListener.
+//
+// The key is read when a module is instantiated, so it must be present in the
+// context passed to wazero.Runtime InstantiateModule.
+type FileListenerKey struct{}
+
+// FileListener is notified when a guest opens or closes a file descriptor,
+// for example to collect metrics on file descriptor churn.
+//
+// # Params
+//
+//   - fd: the file descriptor opened or closed.
+//   - path: the path the file was opened with, relative to the root, e.g.
+//     "sub/test.txt". This is empty for the root directory.
+//   - opened: true when fd was opened, false when it was closed.
+//
+// # Notes
+//
+//   - This is not called for stdio or pre-opened directories, nor for files
+//     still open when the module is closed.
+//   - The listener is called synchronously, so should not block.
+type FileListener func(fd uint32, path string, opened bool)
@@ -0,0 +1,35 @@
+package experimental
+
+import (
+	"os"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// TouchMemory reads and writes back one byte per host page across the
+// current linear memory of the module, if any. This warms the memory, so that
+// the first guest access to a page doesn't incur a page fault.
+//
+// Call this after instantiation, but before the first latency-sensitive call.
+// The contents of memory are unchanged.
+//
+// # Notes
+//
+//   - Memory grown after this call isn't warmed. Call this again if needed.
+//   - This must not be called concurrently with functions in the module, as
+//     it could overwrite their writes.
+func TouchMemory(mod api.Module) {
+	mem := mod.Memory()
+	if mem == nil {
+		return
+	}
+	size := mem.Size()
+	stride := uint32(os.Getpagesize())
+	for offset := uint32(0); offset < size; offset += stride {
+		b, _ := mem.ReadByte(offset)
+		mem.WriteByte(offset, b)
+		if offset+stride < offset { // overflow
+			break
+		}
+	}
+}
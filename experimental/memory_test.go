@@ -0,0 +1,137 @@
+package experimental_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	. "github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+)
+
+// memoryPages is large enough that touching memory is measurable.
+const memoryPages = 160 // 10MiB
+
+// memoryBin exports a function "fill", which writes one byte per 4KiB across
+// all memory.
+var memoryBin = binary.EncodeModule(&wasm.Module{
+	TypeSection:     []*wasm.FunctionType{{}},
+	FunctionSection: []wasm.Index{0},
+	MemorySection:   &wasm.Memory{Min: memoryPages, Max: memoryPages, IsMaxEncoded: true},
+	CodeSection: []*wasm.Code{{
+		LocalTypes: []wasm.ValueType{wasm.ValueTypeI32},
+		Body: []byte{
+			wasm.OpcodeLoop, 0x40,
+			// memory[offset] = 1
+			wasm.OpcodeLocalGet, 0,
+			wasm.OpcodeI32Const, 1,
+			wasm.OpcodeI32Store8, 0, 0,
+			// offset += 4096
+			wasm.OpcodeLocalGet, 0,
+			wasm.OpcodeI32Const, 0x80, 0x20, // 4096
+			wasm.OpcodeI32Add,
+			wasm.OpcodeLocalTee, 0,
+			// continue if offset < memory size
+			wasm.OpcodeMemorySize, 0,
+			wasm.OpcodeI32Const, 16,
+			wasm.OpcodeI32Shl,
+			wasm.OpcodeI32LtU,
+			wasm.OpcodeBrIf, 0,
+			wasm.OpcodeEnd,
+			wasm.OpcodeEnd,
+		},
+	}},
+	ExportSection: []*wasm.Export{{Name: "fill", Type: wasm.ExternTypeFunc, Index: 0}},
+})
+
+func TestTouchMemory(t *testing.T) {
+	ctx := context.Background()
+
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	mod, err := r.InstantiateModuleFromBinary(ctx, memoryBin)
+	require.NoError(t, err)
+
+	// Write a pattern, so that we can tell if anything was overwritten.
+	mem := mod.Memory()
+	expected := make([]byte, mem.Size())
+	for i := range expected {
+		expected[i] = byte(i)
+	}
+	require.True(t, mem.Write(0, expected))
+
+	TouchMemory(mod)
+
+	actual, ok := mem.Read(0, mem.Size())
+	require.True(t, ok)
+	require.Equal(t, expected, actual)
+}
+
+func TestTouchMemory_noMemory(t *testing.T) {
+	ctx := context.Background()
+
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	mod, err := r.InstantiateModuleFromBinary(ctx, binary.EncodeModule(&wasm.Module{}))
+	require.NoError(t, err)
+
+	TouchMemory(mod) // doesn't panic
+}
+
+// BenchmarkTouchMemory compares the latency of the first call to a function
+// that writes across all memory, with and without warming memory.
+func BenchmarkTouchMemory(b *testing.B) {
+	ctx := context.Background()
+
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	compiled, err := r.CompileModule(ctx, memoryBin)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, bc := range []struct {
+		name  string
+		touch bool
+	}{
+		{name: "cold"},
+		{name: "warm", touch: true},
+	} {
+		bc := bc
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				mod := instantiateMemory(b, ctx, r, compiled)
+				if bc.touch {
+					TouchMemory(mod)
+				}
+				fill := mod.ExportedFunction("fill")
+
+				b.StartTimer()
+				if _, err = fill.Call(ctx); err != nil {
+					b.Fatal(err)
+				}
+				b.StopTimer()
+
+				if err = mod.Close(ctx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func instantiateMemory(b *testing.B, ctx context.Context, r wazero.Runtime, compiled wazero.CompiledModule) api.Module {
+	// Use an anonymous module, so that each instance can coexist.
+	mod, err := r.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return mod
+}
@@ -0,0 +1,21 @@
+package experimental
+
+import (
+	"context"
+
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
+)
+
+// WithModuleLength returns a context which makes Runtime.CompileModule decode
+// only the first length bytes of the binary as the module. Any bytes after
+// them, such as a signature appended by a toolchain, are available via
+// CompiledModule.TrailingData instead of being rejected as malformed.
+//
+// The WebAssembly binary format has no length header, so appended bytes can't
+// be told apart from sections. The caller must know where the module ends,
+// for example from the trailer's own format.
+//
+// Note: Runtime.CompileModule fails if length exceeds the binary.
+func WithModuleLength(ctx context.Context, length int) context.Context {
+	return context.WithValue(ctx, wasmruntime.ModuleLengthKey{}, length)
+}
@@ -0,0 +1,58 @@
+package experimental
+
+import (
+	"context"
+	"sort"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
+)
+
+// ProfileSample is the count of times a function was executing when sampled.
+type ProfileSample struct {
+	Function api.FunctionDefinition
+	Count    uint64
+}
+
+// Profiler is returned by WithProfiler to read the samples taken during
+// function calls made with its context.
+type Profiler struct {
+	p wasmruntime.Profiler
+}
+
+// Profile returns a histogram of the sampled functions, most sampled first.
+// Read this after api.Function Call returns.
+func (p *Profiler) Profile() []ProfileSample {
+	ret := make([]ProfileSample, 0, len(p.p.Samples))
+	for fn, count := range p.p.Samples {
+		ret = append(ret, ProfileSample{Function: fn, Count: count})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count != ret[j].Count {
+			return ret[i].Count > ret[j].Count
+		}
+		return ret[i].Function.DebugName() < ret[j].Function.DebugName()
+	})
+	return ret
+}
+
+// WithProfiler returns a context which records the function executing every
+// interval instructions during api.Function Call, and the profiler to read
+// the resulting histogram from. An interval of zero samples every
+// instruction.
+//
+// # Notes
+//
+//   - This is only implemented by the interpreter. Other engines take no
+//     samples.
+//   - The interval counts instructions after translation to the engine's
+//     internal representation, like WithInstructionCounter, not time.
+//   - Host functions aren't sampled, as they have no instructions.
+//   - The profiler must not be shared across concurrent calls.
+func WithProfiler(ctx context.Context, interval uint64) (context.Context, *Profiler) {
+	p := &Profiler{p: wasmruntime.Profiler{
+		Interval: interval,
+		Samples:  map[api.FunctionDefinition]uint64{},
+	}}
+	return context.WithValue(ctx, wasmruntime.ProfilerKey{}, &p.p), p
+}
@@ -0,0 +1,75 @@
+package experimental_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	. "github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+)
+
+func TestWithProfiler(t *testing.T) {
+	// loop loops its i32 parameter times.
+	loop := []byte{
+		wasm.OpcodeLoop, 0x40,
+		wasm.OpcodeLocalGet, 0,
+		wasm.OpcodeI32Const, 1,
+		wasm.OpcodeI32Sub,
+		wasm.OpcodeLocalTee, 0,
+		wasm.OpcodeBrIf, 0,
+		wasm.OpcodeEnd,
+		wasm.OpcodeEnd,
+	}
+
+	// main calls cold, which loops a few times, then hot, which loops many.
+	bin := binary.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{
+			{},
+			{Params: []wasm.ValueType{wasm.ValueTypeI32}},
+		},
+		FunctionSection: []wasm.Index{0, 1, 1},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{
+				wasm.OpcodeI32Const, 10,
+				wasm.OpcodeCall, 1,
+				wasm.OpcodeI32Const, 0x90, 0xce, 0x00, // 10000
+				wasm.OpcodeCall, 2,
+				wasm.OpcodeEnd,
+			}},
+			{Body: loop},
+			{Body: loop},
+		},
+		ExportSection: []*wasm.Export{{Name: "main", Type: wasm.ExternTypeFunc, Index: 0}},
+		NameSection: &wasm.NameSection{FunctionNames: wasm.NameMap{
+			{Index: 0, Name: "main"},
+			{Index: 1, Name: "cold"},
+			{Index: 2, Name: "hot"},
+		}},
+	})
+
+	ctx := context.Background()
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter())
+	defer r.Close(ctx)
+
+	mod, err := r.InstantiateModuleFromBinary(ctx, bin)
+	require.NoError(t, err)
+
+	ctx, profiler := WithProfiler(ctx, 10)
+	_, err = mod.ExportedFunction("main").Call(ctx)
+	require.NoError(t, err)
+
+	profile := profiler.Profile()
+	require.True(t, len(profile) >= 1)
+
+	var total uint64
+	for _, s := range profile {
+		total += s.Count
+	}
+
+	// hot dominates the histogram.
+	require.Equal(t, ".hot", profile[0].Function.DebugName())
+	require.True(t, profile[0].Count*100 >= total*95, "hot has %d of %d samples", profile[0].Count, total)
+}
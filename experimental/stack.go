@@ -0,0 +1,47 @@
+package experimental
+
+import (
+	"context"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
+)
+
+// StackSnapshot is returned by Stack to describe the Wasm caller of a host
+// function.
+type StackSnapshot struct {
+	// Caller is the definition of the calling function, or nil when the host
+	// function was called directly by api.Function Call.
+	Caller api.FunctionDefinition
+
+	// Values are the operand stack values, bottom first. The last values are
+	// the parameters of the host function.
+	Values []uint64
+}
+
+// WithStackSnapshots returns a context which allows host functions called
+// with it to read their caller's stack via Stack.
+//
+// This is for debugging: enabling it copies the operand stack on each host
+// function call.
+func WithStackSnapshots(ctx context.Context) context.Context {
+	return context.WithValue(ctx, wasmruntime.StackKey{}, &wasmruntime.Stack{})
+}
+
+// Stack returns the stack of the Wasm caller of the current host function,
+// or false if unavailable.
+//
+// # Notes
+//
+//   - This is only valid inside a host function call, using the context it
+//     was called with.
+//   - The context passed to api.Function Call must have been derived from
+//     WithStackSnapshots.
+//   - This is only implemented by the interpreter. Other engines return false.
+func Stack(ctx context.Context) (StackSnapshot, bool) {
+	s, ok := ctx.Value(wasmruntime.StackKey{}).(*wasmruntime.Stack)
+	if !ok || s.Values == nil {
+		return StackSnapshot{}, false
+	}
+	return StackSnapshot{Caller: s.Caller, Values: s.Values}, true
+}
@@ -0,0 +1,76 @@
+package experimental_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	. "github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+)
+
+func TestStack(t *testing.T) {
+	ctx := context.Background()
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfigInterpreter())
+	defer r.Close(ctx)
+
+	var snapshot StackSnapshot
+	var ok bool
+	_, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, a, b uint32) {
+			snapshot, ok = Stack(ctx)
+		}).
+		Export("inspect").
+		Instantiate(ctx)
+	require.NoError(t, err)
+
+	// Define a function which passes constants to the host function.
+	bin := binary.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{
+			{Params: []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32}},
+			{},
+		},
+		ImportSection: []*wasm.Import{
+			{Module: "env", Name: "inspect", Type: wasm.ExternTypeFunc, DescFunc: 0},
+		},
+		FunctionSection: []wasm.Index{1},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{
+				wasm.OpcodeI32Const, 7,
+				wasm.OpcodeI32Const, 42,
+				wasm.OpcodeCall, 0,
+				wasm.OpcodeEnd,
+			}},
+		},
+		ExportSection: []*wasm.Export{{Name: "run", Type: wasm.ExternTypeFunc, Index: 1}},
+	})
+
+	mod, err := r.InstantiateModuleFromBinary(ctx, bin)
+	require.NoError(t, err)
+	run := mod.ExportedFunction("run")
+
+	t.Run("disabled", func(t *testing.T) {
+		_, err := run.Call(ctx)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		_, err := run.Call(WithStackSnapshots(ctx))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, []string{"run"}, snapshot.Caller.ExportNames())
+		values := snapshot.Values
+		require.True(t, len(values) >= 2, "unexpected stack: %v", values)
+		require.Equal(t, []uint64{7, 42}, values[len(values)-2:])
+	})
+
+	t.Run("outside host function", func(t *testing.T) {
+		_, ok := Stack(WithStackSnapshots(ctx))
+		require.False(t, ok)
+	})
+}
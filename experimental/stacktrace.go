@@ -0,0 +1,26 @@
+package experimental
+
+import "github.com/tetratelabs/wazero/api"
+
+// StackTraceError is returned by api.Function Call when the function trapped
+// and the runtime was configured with wazero.RuntimeConfig WithStackTrace.
+//
+// Use errors.As to access it, as it may be wrapped.
+type StackTraceError struct {
+	// Err is the error that would have been returned without a stack trace.
+	Err error
+
+	// Stack is the wasm call stack, beginning with the function that trapped
+	// and ending with the function that was called.
+	Stack []api.FunctionDefinition
+}
+
+// Error implements the same method as documented on error.
+func (e *StackTraceError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap allows errors.Is and errors.As to inspect Err.
+func (e *StackTraceError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,33 @@
+package experimental
+
+import "io"
+
+// NewTransformWriter returns an io.Writer which applies the transform to each
+// write before it reaches w. For example, a test harness can use this with
+// wazero.ModuleConfig WithStdout to sanitize or annotate guest output.
+//
+// Each call to "fd_write" in "wasi_snapshot_preview1" writes each iovec
+// separately, so the transform sees them separately, too.
+//
+// # Notes
+//
+//   - The count of bytes written is the length of the input, not the
+//     transformed output. This means the guest sees its bytes as accepted.
+//   - The transform must not retain or modify its input, as it aliases guest
+//     memory.
+func NewTransformWriter(w io.Writer, transform func([]byte) []byte) io.Writer {
+	return &transformWriter{w: w, transform: transform}
+}
+
+type transformWriter struct {
+	w         io.Writer
+	transform func([]byte) []byte
+}
+
+// Write implements io.Writer
+func (t *transformWriter) Write(p []byte) (int, error) {
+	if _, err := t.w.Write(t.transform(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
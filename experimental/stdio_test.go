@@ -0,0 +1,90 @@
+package experimental_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	. "github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+)
+
+// fdWriteBin exports a function "_start", which writes "wazero" to stdout.
+var fdWriteBin = binary.EncodeModule(&wasm.Module{
+	TypeSection: []*wasm.FunctionType{
+		{
+			Params:  []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32, wasm.ValueTypeI32, wasm.ValueTypeI32},
+			Results: []wasm.ValueType{wasm.ValueTypeI32},
+		},
+		{},
+	},
+	ImportSection: []*wasm.Import{{
+		Module: wasi_snapshot_preview1.ModuleName, Name: "fd_write",
+		Type: wasm.ExternTypeFunc, DescFunc: 0,
+	}},
+	FunctionSection: []wasm.Index{1},
+	MemorySection:   &wasm.Memory{Min: 1},
+	DataSection: []*wasm.DataSegment{{
+		OffsetExpression: &wasm.ConstantExpression{Opcode: wasm.OpcodeI32Const, Data: []byte{0}},
+		Init: []byte{
+			8, 0, 0, 0, // = iovs[0].offset
+			6, 0, 0, 0, // = iovs[0].length
+			'w', 'a', 'z', 'e', 'r', 'o',
+		},
+	}},
+	CodeSection: []*wasm.Code{{Body: []byte{
+		wasm.OpcodeI32Const, 1, // fd = stdout
+		wasm.OpcodeI32Const, 0, // iovs
+		wasm.OpcodeI32Const, 1, // iovs_len
+		wasm.OpcodeI32Const, 16, // result.nwritten
+		wasm.OpcodeCall, 0,
+		wasm.OpcodeDrop,
+		wasm.OpcodeEnd,
+	}}},
+	ExportSection: []*wasm.Export{
+		{Name: "_start", Type: wasm.ExternTypeFunc, Index: 1},
+		{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+	},
+})
+
+func TestNewTransformWriter(t *testing.T) {
+	ctx := context.Background()
+
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+
+	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+
+	var stdout bytes.Buffer
+	config := wazero.NewModuleConfig().WithStdout(NewTransformWriter(&stdout, bytes.ToUpper))
+
+	compiled, err := r.CompileModule(ctx, fdWriteBin)
+	require.NoError(t, err)
+
+	// Instantiating calls "_start", which writes to stdout.
+	mod, err := r.InstantiateModule(ctx, compiled, config)
+	require.NoError(t, err)
+
+	require.Equal(t, "WAZERO", stdout.String())
+
+	// nwritten is the count of bytes the guest wrote, not the transformed.
+	nwritten, ok := mod.Memory().ReadUint32Le(16)
+	require.True(t, ok)
+	require.Equal(t, uint32(6), nwritten)
+}
+
+func TestNewTransformWriter_nwritten(t *testing.T) {
+	var out bytes.Buffer
+	w := NewTransformWriter(&out, func(p []byte) []byte {
+		return append([]byte("> "), p...)
+	})
+
+	n, err := w.Write([]byte("wazero"))
+	require.NoError(t, err)
+	require.Equal(t, 6, n)
+	require.Equal(t, "> wazero", out.String())
+}
@@ -0,0 +1,32 @@
+package experimental
+
+import (
+	"context"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/wasi_snapshot_preview1"
+)
+
+// WASIInterceptor is called instead of the WASI function it is registered
+// for, with the same parameters. This is for testing guest behavior under
+// failure, for example forcing "fd_write" to return ENOSPC.
+//
+// To return a canned result, write it to api.Memory at the offset given in
+// params, and return handled=true with the errno to return to the guest. To
+// defer to the real implementation, return handled=false.
+type WASIInterceptor func(ctx context.Context, mod api.Module, params []uint64) (errno uint32, handled bool)
+
+// WithWASIInterceptors returns a context which makes "wasi_snapshot_preview1"
+// call the interceptors instead of the WASI functions they are keyed by, e.g.
+// "fd_write". Like FunctionListenerFactoryKey, this must be set on the
+// context used to compile or instantiate the module, not the context of
+// function calls.
+//
+// The map is read on each call, so deleting an entry removes its interceptor
+// for subsequent calls. It must not be modified concurrently with calls.
+//
+// Note: This is only supported by functions implemented in
+// "wasi_snapshot_preview1", not those stubbed to return ENOSYS.
+func WithWASIInterceptors(ctx context.Context, interceptors map[string]WASIInterceptor) context.Context {
+	return context.WithValue(ctx, wasi_snapshot_preview1.InterceptorsKey{}, interceptors)
+}
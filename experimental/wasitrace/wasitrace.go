@@ -0,0 +1,224 @@
+// Package wasitrace records WASI calls made by a guest, and replays their
+// results, so that a subsequent run of the guest is deterministic.
+//
+// This is for debugging guests whose behavior depends on clocks, random
+// numbers or input, which otherwise change on each run.
+package wasitrace
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/internal/wasi_snapshot_preview1"
+)
+
+// Call is a WASI function call, encoded as a line of JSON in a trace.
+type Call struct {
+	// Name is the WASI function name, e.g. "random_get".
+	Name string `json:"name"`
+
+	// Params are the api.ValueType encoded parameters.
+	Params []uint64 `json:"params"`
+
+	// Errno is the result of the function.
+	Errno uint32 `json:"errno"`
+
+	// Writes are the results written to api.Memory by a replayable function,
+	// in order. These are empty for other functions, or when Errno isn't
+	// zero.
+	Writes []Write `json:"writes,omitempty"`
+}
+
+// Write is a region of api.Memory written by a Call.
+type Write struct {
+	Offset uint32 `json:"offset"`
+	Data   []byte `json:"data"`
+}
+
+// replayable are the functions whose results are replayed, as they are the
+// sources of non-determinism.
+var replayable = map[string]func(mem api.Memory, params []uint64) []Write{
+	wasi_snapshot_preview1.ClockResGetName:  clockResGetWrites,
+	wasi_snapshot_preview1.ClockTimeGetName: clockTimeGetWrites,
+	wasi_snapshot_preview1.FdReadName:       fdReadWrites,
+	wasi_snapshot_preview1.RandomGetName:    randomGetWrites,
+}
+
+// Recorder is an experimental.FunctionListenerFactory which writes each WASI
+// call to a trace.
+//
+// To record a trace, add the recorder to the context used to instantiate
+// "wasi_snapshot_preview1", via experimental.FunctionListenerFactoryKey:
+//
+//	f, _ := os.Create("trace.jsonl")
+//	recorder := wasitrace.NewRecorder(f)
+//	ctx = context.WithValue(ctx, experimental.FunctionListenerFactoryKey{}, recorder)
+//	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+type Recorder struct {
+	mux sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewRecorder returns a Recorder which writes one line of JSON per Call to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// Err returns the first error writing the trace, if any.
+func (r *Recorder) Err() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.err
+}
+
+// NewListener implements experimental.FunctionListenerFactory.NewListener
+func (r *Recorder) NewListener(def api.FunctionDefinition) experimental.FunctionListener {
+	if def.ModuleName() != wasi_snapshot_preview1.InternalModuleName || def.GoFunction() == nil {
+		return nil // only record implemented WASI functions.
+	}
+	return r
+}
+
+// paramsKey is a context.Context Value key. Its associated value is a copy of
+// the parameters passed to FunctionListener.Before.
+type paramsKey struct{}
+
+// Before implements experimental.FunctionListener.Before
+func (r *Recorder) Before(ctx context.Context, _ api.Module, def api.FunctionDefinition, params []uint64) context.Context {
+	// Copy the params, as results are written over them. Clear the upper bits
+	// of 32-bit params, as engines needn't.
+	copied := make([]uint64, len(params))
+	for i, t := range def.ParamTypes() {
+		if t == api.ValueTypeI32 {
+			copied[i] = uint64(api.DecodeU32(params[i]))
+		} else {
+			copied[i] = params[i]
+		}
+	}
+	return context.WithValue(ctx, paramsKey{}, copied)
+}
+
+// After implements experimental.FunctionListener.After
+func (r *Recorder) After(ctx context.Context, mod api.Module, def api.FunctionDefinition, err error, results []uint64) {
+	if err != nil {
+		return // the guest is exiting or trapped, so there's no result.
+	}
+	params, _ := ctx.Value(paramsKey{}).([]uint64)
+	call := Call{Name: def.Name(), Params: params, Errno: uint32(results[0])}
+	if writes := replayable[call.Name]; writes != nil && call.Errno == 0 {
+		call.Writes = writes(mod.Memory(), params)
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.err == nil {
+		r.err = r.enc.Encode(&call)
+	}
+}
+
+// WithReplay returns a context which replays the results of clock, random and
+// read functions recorded in the trace, when used to instantiate
+// "wasi_snapshot_preview1". Other functions, such as "fd_write", are called as
+// usual.
+//
+//	replayCtx, _ := wasitrace.WithReplay(ctx, trace)
+//	wasi_snapshot_preview1.MustInstantiate(replayCtx, r)
+//
+// Results are replayed in the order they were recorded for each function.
+// Once a function's recorded calls are exhausted, it is called as usual.
+//
+// Note: Replaying writes results to the offsets in api.Memory they were
+// recorded at, so the guest and its inputs must be the same as when recorded.
+func WithReplay(ctx context.Context, trace io.Reader) (context.Context, error) {
+	calls := map[string][]Call{}
+	dec := json.NewDecoder(trace)
+	for {
+		var call Call
+		if err := dec.Decode(&call); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if replayable[call.Name] != nil {
+			calls[call.Name] = append(calls[call.Name], call)
+		}
+	}
+
+	var mux sync.Mutex
+	interceptors := make(map[string]experimental.WASIInterceptor, len(replayable))
+	for name := range replayable {
+		name := name
+		interceptors[name] = func(_ context.Context, mod api.Module, _ []uint64) (uint32, bool) {
+			mux.Lock()
+			defer mux.Unlock()
+			if len(calls[name]) == 0 {
+				return 0, false // call the real function.
+			}
+			call := calls[name][0]
+			calls[name] = calls[name][1:]
+			for _, w := range call.Writes {
+				if !mod.Memory().Write(w.Offset, w.Data) {
+					return uint32(wasi_snapshot_preview1.ErrnoFault), true
+				}
+			}
+			return call.Errno, true
+		}
+	}
+	return experimental.WithWASIInterceptors(ctx, interceptors), nil
+}
+
+// clockResGetWrites returns the resolution written by "clock_res_get".
+func clockResGetWrites(mem api.Memory, params []uint64) []Write {
+	return readWrites(mem, uint32(params[1]), 8)
+}
+
+// clockTimeGetWrites returns the timestamp written by "clock_time_get".
+func clockTimeGetWrites(mem api.Memory, params []uint64) []Write {
+	return readWrites(mem, uint32(params[2]), 8)
+}
+
+// randomGetWrites returns the random bytes written by "random_get".
+func randomGetWrites(mem api.Memory, params []uint64) []Write {
+	return readWrites(mem, uint32(params[0]), uint32(params[1]))
+}
+
+// fdReadWrites returns the bytes read into each iovec by "fd_read", followed
+// by the count of bytes read.
+func fdReadWrites(mem api.Memory, params []uint64) (writes []Write) {
+	iovs, iovsCount, resultNread := uint32(params[1]), uint32(params[2]), uint32(params[3])
+	nread, ok := mem.ReadUint32Le(resultNread)
+	if !ok {
+		return nil
+	}
+	iovsBuf, ok := mem.Read(iovs, iovsCount<<3)
+	if !ok {
+		return nil
+	}
+	for pos := uint32(0); pos < uint32(len(iovsBuf)) && nread > 0; pos += 8 {
+		offset := binary.LittleEndian.Uint32(iovsBuf[pos:])
+		l := binary.LittleEndian.Uint32(iovsBuf[pos+4:])
+		if l > nread {
+			l = nread
+		}
+		writes = append(writes, readWrites(mem, offset, l)...)
+		nread -= l
+	}
+	return append(writes, readWrites(mem, resultNread, 4)...)
+}
+
+// readWrites returns a copy of the memory region as a Write, or nil if it is
+// out of range.
+func readWrites(mem api.Memory, offset, byteCount uint32) []Write {
+	buf, ok := mem.Read(offset, byteCount)
+	if !ok {
+		return nil
+	}
+	return []Write{{Offset: offset, Data: append([]byte(nil), buf...)}}
+}
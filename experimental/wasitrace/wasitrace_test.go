@@ -0,0 +1,101 @@
+package wasitrace_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/experimental/wasitrace"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/internal/testing/require"
+	. "github.com/tetratelabs/wazero/internal/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+)
+
+var testCtx = context.Background()
+
+// guestBin writes 8 random bytes at offset 0 and the realtime clock at offset
+// 8, when "run" is called.
+var guestBin = binary.EncodeModule(&wasm.Module{
+	TypeSection: []*wasm.FunctionType{
+		{
+			Params:  []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32},
+			Results: []wasm.ValueType{wasm.ValueTypeI32},
+		},
+		{
+			Params:  []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI64, wasm.ValueTypeI32},
+			Results: []wasm.ValueType{wasm.ValueTypeI32},
+		},
+		{},
+	},
+	ImportSection: []*wasm.Import{
+		{Module: wasi_snapshot_preview1.ModuleName, Name: RandomGetName, Type: wasm.ExternTypeFunc, DescFunc: 0},
+		{Module: wasi_snapshot_preview1.ModuleName, Name: ClockTimeGetName, Type: wasm.ExternTypeFunc, DescFunc: 1},
+	},
+	FunctionSection: []wasm.Index{2},
+	CodeSection: []*wasm.Code{{Body: []byte{
+		wasm.OpcodeI32Const, 0, // buf
+		wasm.OpcodeI32Const, 8, // buf_len
+		wasm.OpcodeCall, 0, // random_get
+		wasm.OpcodeDrop, // errno
+		wasm.OpcodeI32Const, byte(ClockIDRealtime),
+		wasm.OpcodeI64Const, 0, // precision
+		wasm.OpcodeI32Const, 8, // result.timestamp
+		wasm.OpcodeCall, 1, // clock_time_get
+		wasm.OpcodeDrop, // errno
+		wasm.OpcodeEnd,
+	}}},
+	MemorySection: &wasm.Memory{Min: 1},
+	ExportSection: []*wasm.Export{{Name: "run", Type: wasm.ExternTypeFunc, Index: 2}},
+})
+
+// run instantiates WASI with wasiCtx and the guest with config, returning
+// the 16 bytes the guest wrote after calling "run" with callCtx.
+func run(t *testing.T, wasiCtx, callCtx context.Context, config wazero.ModuleConfig) []byte {
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	_, err := wasi_snapshot_preview1.Instantiate(wasiCtx, r)
+	require.NoError(t, err)
+
+	compiled, err := r.CompileModule(testCtx, guestBin)
+	require.NoError(t, err)
+	mod, err := r.InstantiateModule(testCtx, compiled, config)
+	require.NoError(t, err)
+
+	_, err = mod.ExportedFunction("run").Call(callCtx)
+	require.NoError(t, err)
+
+	out, ok := mod.Memory().Read(0, 16)
+	require.True(t, ok)
+	return append([]byte(nil), out...)
+}
+
+func TestRecorder_WithReplay(t *testing.T) {
+	// Record a run using real randomness and time.
+	var trace bytes.Buffer
+	recorder := wasitrace.NewRecorder(&trace)
+	recordCtx := context.WithValue(testCtx, experimental.FunctionListenerFactoryKey{}, recorder)
+	recorded := run(t, recordCtx, testCtx, wazero.NewModuleConfig().
+		WithRandSource(rand.Reader).WithSysWalltime())
+	require.NoError(t, recorder.Err())
+
+	// Each call is a line of JSON, including the results written to memory.
+	lines := bytes.Split(bytes.TrimSpace(trace.Bytes()), []byte{'\n'})
+	require.Equal(t, 2, len(lines))
+	require.Contains(t, string(lines[0]), `"name":"random_get","params":[0,8],"errno":0,"writes":[{"offset":0,`)
+	require.Contains(t, string(lines[1]), `"name":"clock_time_get","params":[0,0,8],"errno":0,"writes":[{"offset":8,`)
+
+	// Replay the trace with default, fake, sources of randomness and time.
+	replayCtx, err := wasitrace.WithReplay(testCtx, bytes.NewReader(trace.Bytes()))
+	require.NoError(t, err)
+	replayed := run(t, replayCtx, testCtx, wazero.NewModuleConfig())
+	require.Equal(t, recorded, replayed)
+
+	// Without replay, the output is different.
+	require.NotEqual(t, recorded, run(t, testCtx, testCtx, wazero.NewModuleConfig()))
+}
@@ -8,6 +8,7 @@
 package writefs
 
 import (
+	"io"
 	"io/fs"
 
 	"github.com/tetratelabs/wazero/internal/syscallfs"
@@ -38,3 +39,104 @@ func NewDirFS(dir string) (fs.FS, error) {
 	// syscallfs.DirFS is intentionally internal as it is still evolving
 	return syscallfs.NewDirFS(dir)
 }
+
+// NewRemapFS intercepts guest paths before they resolve against the input
+// filesystem. When `remap` returns true, the path is resolved against the
+// returned fs.FS using the returned path instead.
+//
+// For example, this maps "/config/app.toml" to a file on the host:
+//
+//	remapped := writefs.NewRemapFS(rootFS, func(guestPath string) (string, fs.FS, bool) {
+//		if guestPath == "config/app.toml" {
+//			return "app.toml", os.DirFS("/etc/myapp"), true
+//		}
+//		return "", nil, false
+//	})
+//
+// Note: Guest paths are relative to the filesystem root, e.g.
+// "config/app.toml", not "/config/app.toml". Renames between a remapped and
+// non-remapped path fail with syscall.EXDEV.
+//
+// # This is wazero-only
+//
+// Do not attempt to use the result as a fs.FS, as it will panic. This is a
+// bridge to a future filesystem abstraction made for wazero.
+func NewRemapFS(root fs.FS, remap func(guestPath string) (realPath string, fs fs.FS, ok bool)) fs.FS {
+	return syscallfs.NewRemapFS(syscallfs.Adapt(root), remap)
+}
+
+// NewDevFS synthesizes the character devices "/dev/null" and "/dev/zero" on
+// top of the input filesystem. Other paths resolve against the input.
+//
+//   - "/dev/null" reads EOF and discards writes.
+//   - "/dev/zero" reads an infinite amount of zero bytes and discards writes.
+//
+// Both report fs.ModeCharDevice, which WASI reports as filetype
+// CHARACTER_DEVICE.
+//
+// # This is wazero-only
+//
+// Do not attempt to use the result as a fs.FS, as it will panic. This is a
+// bridge to a future filesystem abstraction made for wazero.
+func NewDevFS(root fs.FS) fs.FS {
+	return syscallfs.NewDevFS(syscallfs.Adapt(root))
+}
+
+// NewBufferedFS buffers writes to host files opened for writing by the input
+// filesystem, using a buffer of `size` bytes. This reduces syscalls made by
+// guests that write a small amount of bytes at a time via fd_write.
+//
+// Buffered writes are flushed by fd_close, fd_sync and when the module is
+// closed. They are also flushed before reads, seeks or stats of the file.
+//
+// # This is wazero-only
+//
+// Do not attempt to use the result as a fs.FS, as it will panic. This is a
+// bridge to a future filesystem abstraction made for wazero.
+func NewBufferedFS(root fs.FS, size int) fs.FS {
+	return syscallfs.NewBufferedFS(syscallfs.Adapt(root), size)
+}
+
+// NewSeedFS writes initial content to files newly created in the input
+// filesystem, e.g. via path_open with O_CREAT, before the guest receives the
+// file descriptor. For example, this allows a lock-file to start with a magic
+// value.
+//
+// The seed function returns the content for a guest path, or false to create
+// the file empty. Files that already exist are not changed.
+//
+// # This is wazero-only
+//
+// Do not attempt to use the result as a fs.FS, as it will panic. This is a
+// bridge to a future filesystem abstraction made for wazero.
+func NewSeedFS(root fs.FS, seed func(guestPath string) (initialContent []byte, ok bool)) fs.FS {
+	return syscallfs.NewSeedFS(syscallfs.Adapt(root), seed)
+}
+
+// NewReaderAtFS returns a read-only filesystem with a single file `name`,
+// whose content is the first `size` bytes of `ra`. This exposes a large blob,
+// such as a memory-mapped database, to the guest without copying it.
+//
+// fd_pread reads directly via io.ReaderAt, while fd_read and fd_seek track an
+// offset per file descriptor.
+//
+// # This is wazero-only
+//
+// Do not attempt to use the result as a fs.FS, as it will panic. This is a
+// bridge to a future filesystem abstraction made for wazero.
+func NewReaderAtFS(name string, ra io.ReaderAt, size int64) fs.FS {
+	return syscallfs.NewReaderAtFS(name, ra, size)
+}
+
+// NewDenyOpenFS forbids opening files in the input filesystem with any of the
+// denied flags, regardless of whether it is writable. For example, denying
+// os.O_CREATE forbids path_open with O_CREAT, and denying os.O_APPEND forbids
+// path_open with FD_APPEND. WASI functions return ENOTCAPABLE when denied.
+//
+// # This is wazero-only
+//
+// Do not attempt to use the result as a fs.FS, as it will panic. This is a
+// bridge to a future filesystem abstraction made for wazero.
+func NewDenyOpenFS(root fs.FS, denied int) fs.FS {
+	return syscallfs.NewDenyOpenFS(syscallfs.Adapt(root), denied)
+}
@@ -37,6 +37,57 @@ func Test_argsGet(t *testing.T) {
 	require.Equal(t, expectedMemory, actual)
 }
 
+func Test_argsGet_programName(t *testing.T) {
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().
+		WithProgramName("prog").WithArgs("--flag"))
+	defer r.Close(testCtx)
+
+	resultArgc := uint32(16)    // arbitrary offset
+	resultArgvLen := uint32(21) // arbitrary offset
+	expectedSizes := []byte{
+		'?',                // resultArgc is after this
+		0x2, 0x0, 0x0, 0x0, // little endian-encoded arg count
+		'?',                // resultArgvLen is after this
+		0xc, 0x0, 0x0, 0x0, // little endian-encoded size of null terminated strings
+		'?', // stopped after encoding
+	}
+
+	maskMemory(t, mod, int(resultArgc)+len(expectedSizes))
+
+	// The program name is counted as an argument.
+	requireErrno(t, ErrnoSuccess, mod, ArgsSizesGetName, uint64(resultArgc), uint64(resultArgvLen))
+	actual, ok := mod.Memory().Read(resultArgc-1, uint32(len(expectedSizes)))
+	require.True(t, ok)
+	require.Equal(t, expectedSizes, actual)
+
+	argvBuf := uint32(16) // arbitrary offset
+	argv := uint32(29)    // arbitrary offset
+	expectedMemory := []byte{
+		'?',                   // argvBuf is after this
+		'p', 'r', 'o', 'g', 0, // null terminated "prog"
+		'-', '-', 'f', 'l', 'a', 'g', 0, // null terminated "--flag"
+		'?',         // argv is after this
+		16, 0, 0, 0, // little endian-encoded offset of "prog"
+		21, 0, 0, 0, // little endian-encoded offset of "--flag"
+		'?', // stopped after encoding
+	}
+
+	maskMemory(t, mod, len(expectedMemory)+int(argvBuf))
+
+	// The program name is argv[0], followed by the arguments.
+	requireErrno(t, ErrnoSuccess, mod, ArgsGetName, uint64(argv), uint64(argvBuf))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.args_sizes_get(result.argc=16,result.argv_len=21)
+<== errno=ESUCCESS
+==> wasi_snapshot_preview1.args_get(argv=29,argv_buf=16)
+<== errno=ESUCCESS
+`, "\n"+log.String())
+
+	actual, ok = mod.Memory().Read(argvBuf-1, uint32(len(expectedMemory)))
+	require.True(t, ok)
+	require.Equal(t, expectedMemory, actual)
+}
+
 func Test_argsGet_Errors(t *testing.T) {
 	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithArgs("a", "bc"))
 	defer r.Close(testCtx)
@@ -21,7 +21,8 @@ import (
 // Result (Errno)
 //
 // The return value is ErrnoSuccess except the following error conditions:
-//   - ErrnoNotsup: the clock ID is not supported.
+//   - ErrnoNotsup: the clock ID is not supported, or is unconfigured while
+//     strict clocks are enabled.
 //   - ErrnoInval: the clock ID is invalid.
 //   - ErrnoFault: there is not enough memory to write results
 //
@@ -46,8 +47,14 @@ func clockResGetFn(_ context.Context, mod api.Module, params []uint64) Errno {
 	var resolution uint64 // ns
 	switch id {
 	case ClockIDRealtime:
+		if !sysCtx.WalltimeSupported() {
+			return ErrnoNotsup
+		}
 		resolution = uint64(sysCtx.WalltimeResolution())
 	case ClockIDMonotonic:
+		if !sysCtx.NanotimeSupported() {
+			return ErrnoNotsup
+		}
 		resolution = uint64(sysCtx.NanotimeResolution())
 	default:
 		return ErrnoInval
@@ -73,7 +80,8 @@ func clockResGetFn(_ context.Context, mod api.Module, params []uint64) Errno {
 // Result (Errno)
 //
 // The return value is ErrnoSuccess except the following error conditions:
-//   - ErrnoNotsup: the clock ID is not supported.
+//   - ErrnoNotsup: the clock ID is not supported, or is unconfigured while
+//     strict clocks are enabled.
 //   - ErrnoInval: the clock ID is invalid.
 //   - ErrnoFault: there is not enough memory to write results
 //
@@ -102,9 +110,15 @@ func clockTimeGetFn(_ context.Context, mod api.Module, params []uint64) Errno {
 	var val int64
 	switch id {
 	case ClockIDRealtime:
+		if !sysCtx.WalltimeSupported() {
+			return ErrnoNotsup
+		}
 		sec, nsec := sysCtx.Walltime()
 		val = (sec * time.Second.Nanoseconds()) + int64(nsec)
 	case ClockIDMonotonic:
+		if !sysCtx.NanotimeSupported() {
+			return ErrnoNotsup
+		}
 		val = sysCtx.Nanotime()
 	default:
 		return ErrnoInval
@@ -1,12 +1,18 @@
 package wasi_snapshot_preview1_test
 
 import (
+	"context"
 	_ "embed"
 	"testing"
+	"time"
 
 	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 	"github.com/tetratelabs/wazero/internal/testing/require"
 	. "github.com/tetratelabs/wazero/internal/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+	"github.com/tetratelabs/wazero/sys"
 )
 
 func Test_clockResGet(t *testing.T) {
@@ -178,6 +184,246 @@ func Test_clockTimeGet(t *testing.T) {
 	}
 }
 
+// Test_clockTimeGet_walltimeJump ensures each call reads the latest value of
+// the configured walltime, so that tests can inject clock skew.
+func Test_clockTimeGet_walltimeJump(t *testing.T) {
+	sec := int64(1640995200) // 2022-01-01T00:00:00Z
+	walltime := func() (int64, int32) { return sec, 0 }
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().
+		WithWalltime(walltime, sys.ClockResolution(time.Microsecond)))
+	defer r.Close(testCtx)
+
+	resultTimestamp := uint32(16) // arbitrary offset
+	clockTimeGet := func() uint64 {
+		requireErrno(t, ErrnoSuccess, mod, ClockTimeGetName, uint64(ClockIDRealtime), 0 /* TODO: precision */, uint64(resultTimestamp))
+		val, ok := mod.Memory().ReadUint64Le(resultTimestamp)
+		require.True(t, ok)
+		return val
+	}
+
+	before := clockTimeGet()
+	require.Equal(t, uint64(sec*time.Second.Nanoseconds()), before)
+
+	// Advance the clock an hour.
+	sec += 3600
+	require.Equal(t, before+uint64(time.Hour), clockTimeGet())
+
+	// Rewind the clock a day.
+	sec -= 86400
+	require.Equal(t, before-uint64(23*time.Hour), clockTimeGet())
+}
+
+// Test_clockTimeGet_monotonicLoop ensures monotonic reads within the same
+// guest call aren't cached, and never go backwards.
+func Test_clockTimeGet_monotonicLoop(t *testing.T) {
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+	wasi_snapshot_preview1.MustInstantiate(testCtx, r)
+
+	// clockTimeGet reads the monotonic clock into memory at the offset.
+	clockTimeGet := func(offset byte) []byte {
+		return []byte{
+			wasm.OpcodeI32Const, byte(ClockIDMonotonic),
+			wasm.OpcodeI64Const, 0, // precision
+			wasm.OpcodeI32Const, offset,
+			wasm.OpcodeCall, 0,
+			wasm.OpcodeDrop, // errno
+		}
+	}
+
+	// Read the clock, busy-wait, then read it again.
+	var body []byte
+	body = append(body, clockTimeGet(0)...)
+	body = append(body,
+		wasm.OpcodeI32Const, 0x80, 0x80, 0x04, // 65536 iterations
+		wasm.OpcodeLocalSet, 0,
+		wasm.OpcodeLoop, 0x40,
+		wasm.OpcodeLocalGet, 0,
+		wasm.OpcodeI32Const, 1,
+		wasm.OpcodeI32Sub,
+		wasm.OpcodeLocalTee, 0,
+		wasm.OpcodeBrIf, 0,
+		wasm.OpcodeEnd,
+	)
+	body = append(body, clockTimeGet(8)...)
+	body = append(body, wasm.OpcodeEnd)
+
+	bin := binary.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{
+			{
+				Params:  []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI64, wasm.ValueTypeI32},
+				Results: []wasm.ValueType{wasm.ValueTypeI32},
+			},
+			{},
+		},
+		ImportSection: []*wasm.Import{
+			{Module: wasi_snapshot_preview1.ModuleName, Name: ClockTimeGetName, Type: wasm.ExternTypeFunc, DescFunc: 0},
+		},
+		FunctionSection: []wasm.Index{1},
+		CodeSection:     []*wasm.Code{{LocalTypes: []wasm.ValueType{wasm.ValueTypeI32}, Body: body}},
+		MemorySection:   &wasm.Memory{Min: 1},
+		ExportSection:   []*wasm.Export{{Name: "time_loop", Type: wasm.ExternTypeFunc, Index: 1}},
+	})
+
+	compiled, err := r.CompileModule(testCtx, bin)
+	require.NoError(t, err)
+	mod, err := r.InstantiateModule(testCtx, compiled, wazero.NewModuleConfig().WithSysNanotime())
+	require.NoError(t, err)
+
+	_, err = mod.ExportedFunction("time_loop").Call(testCtx)
+	require.NoError(t, err)
+
+	first, ok := mod.Memory().ReadUint64Le(0)
+	require.True(t, ok)
+	second, ok := mod.Memory().ReadUint64Le(8)
+	require.True(t, ok)
+	require.NotEqual(t, uint64(0), first)
+	require.True(t, second >= first, "monotonic clock went backwards: %d < %d", second, first)
+}
+
+// Test_clockTimeGet_hostNow ensures a guest reading time from both an
+// imported "env.now" and clock_time_get sees the same injected source.
+func Test_clockTimeGet_hostNow(t *testing.T) {
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+	wasi_snapshot_preview1.MustInstantiate(testCtx, r)
+
+	injected := int64(1640995200_123456789)
+	now := func() int64 { return injected }
+
+	_, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(func(context.Context) int64 { return now() }).Export("now").
+		Instantiate(testCtx)
+	require.NoError(t, err)
+
+	// times stores env.now at offset 0 and the realtime clock at offset 8.
+	bin := binary.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{
+			{
+				Params:  []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI64, wasm.ValueTypeI32},
+				Results: []wasm.ValueType{wasm.ValueTypeI32},
+			},
+			{Results: []wasm.ValueType{wasm.ValueTypeI64}},
+			{},
+		},
+		ImportSection: []*wasm.Import{
+			{Module: wasi_snapshot_preview1.ModuleName, Name: ClockTimeGetName, Type: wasm.ExternTypeFunc, DescFunc: 0},
+			{Module: "env", Name: "now", Type: wasm.ExternTypeFunc, DescFunc: 1},
+		},
+		FunctionSection: []wasm.Index{2},
+		CodeSection: []*wasm.Code{{Body: []byte{
+			wasm.OpcodeI32Const, 0,
+			wasm.OpcodeCall, 1, // env.now
+			wasm.OpcodeI64Store, 0x3, 0x0, // alignment=3 (natural alignment) staticOffset=0
+			wasm.OpcodeI32Const, byte(ClockIDRealtime),
+			wasm.OpcodeI64Const, 0, // precision
+			wasm.OpcodeI32Const, 8,
+			wasm.OpcodeCall, 0, // clock_time_get
+			wasm.OpcodeDrop, // errno
+			wasm.OpcodeEnd,
+		}}},
+		MemorySection: &wasm.Memory{Min: 1},
+		ExportSection: []*wasm.Export{{Name: "times", Type: wasm.ExternTypeFunc, Index: 2}},
+	})
+
+	compiled, err := r.CompileModule(testCtx, bin)
+	require.NoError(t, err)
+	mod, err := r.InstantiateModule(testCtx, compiled, wazero.NewModuleConfig().
+		WithWalltime(sys.NewWalltime(now), sys.ClockResolution(1)))
+	require.NoError(t, err)
+
+	_, err = mod.ExportedFunction("times").Call(testCtx)
+	require.NoError(t, err)
+
+	direct, ok := mod.Memory().ReadUint64Le(0)
+	require.True(t, ok)
+	require.Equal(t, uint64(injected), direct)
+	realtime, ok := mod.Memory().ReadUint64Le(8)
+	require.True(t, ok)
+	require.Equal(t, uint64(injected), realtime)
+}
+
+func Test_clockTimeGet_strictClocks(t *testing.T) {
+	// monotonic writes the monotonic clock at offset 0, returning the errno.
+	bin := binary.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{
+			{
+				Params:  []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI64, wasm.ValueTypeI32},
+				Results: []wasm.ValueType{wasm.ValueTypeI32},
+			},
+			{Results: []wasm.ValueType{wasm.ValueTypeI32}},
+		},
+		ImportSection: []*wasm.Import{
+			{Module: wasi_snapshot_preview1.ModuleName, Name: ClockTimeGetName, Type: wasm.ExternTypeFunc, DescFunc: 0},
+		},
+		FunctionSection: []wasm.Index{1},
+		CodeSection: []*wasm.Code{{Body: []byte{
+			wasm.OpcodeI32Const, byte(ClockIDMonotonic),
+			wasm.OpcodeI64Const, 0, // precision
+			wasm.OpcodeI32Const, 0,
+			wasm.OpcodeCall, 0, // clock_time_get
+			wasm.OpcodeEnd,
+		}}},
+		MemorySection: &wasm.Memory{Min: 1},
+		ExportSection: []*wasm.Export{{Name: "monotonic", Type: wasm.ExternTypeFunc, Index: 1}},
+	})
+
+	var nt sys.Nanotime = func() int64 { return 42 }
+
+	tests := []struct {
+		name          string
+		strictClocks  bool
+		config        wazero.ModuleConfig
+		expectedErrno Errno
+		expectedTime  uint64
+	}{
+		{
+			name:          "default",
+			config:        wazero.NewModuleConfig(),
+			expectedErrno: ErrnoSuccess,
+			expectedTime:  0, // the first reading of the fake clock
+		},
+		{
+			name:          "strict unconfigured",
+			strictClocks:  true,
+			config:        wazero.NewModuleConfig(),
+			expectedErrno: ErrnoNotsup,
+		},
+		{
+			name:          "strict configured",
+			strictClocks:  true,
+			config:        wazero.NewModuleConfig().WithNanotime(nt, sys.ClockResolution(1)),
+			expectedErrno: ErrnoSuccess,
+			expectedTime:  42,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			r := wazero.NewRuntimeWithConfig(testCtx, wazero.NewRuntimeConfig().WithStrictClocks(tc.strictClocks))
+			defer r.Close(testCtx)
+			wasi_snapshot_preview1.MustInstantiate(testCtx, r)
+
+			compiled, err := r.CompileModule(testCtx, bin)
+			require.NoError(t, err)
+			mod, err := r.InstantiateModule(testCtx, compiled, tc.config)
+			require.NoError(t, err)
+
+			results, err := mod.ExportedFunction("monotonic").Call(testCtx)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedErrno, Errno(results[0]))
+
+			if tc.expectedErrno == ErrnoSuccess {
+				actual, ok := mod.Memory().ReadUint64Le(0)
+				require.True(t, ok)
+				require.Equal(t, tc.expectedTime, actual)
+			}
+		})
+	}
+}
+
 func Test_clockTimeGet_Unsupported(t *testing.T) {
 	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig())
 	defer r.Close(testCtx)
@@ -1,6 +1,7 @@
 package wasi_snapshot_preview1_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/tetratelabs/wazero"
@@ -39,6 +40,29 @@ func Test_environGet(t *testing.T) {
 	require.Equal(t, expectedMemory, actual)
 }
 
+// Test_environGet_large ensures the last of many environment variables is
+// readable by the guest.
+func Test_environGet_large(t *testing.T) {
+	config := wazero.NewModuleConfig()
+	for i := 0; i < 500; i++ {
+		config = config.WithEnv(fmt.Sprintf("KEY%d", i), fmt.Sprintf("value%d", i))
+	}
+	mod, r, _ := requireProxyModule(t, config)
+	defer r.Close(testCtx)
+
+	resultEnviron := uint32(0)       // 500 * 4 bytes of offsets
+	resultEnvironBuf := uint32(4096) // arbitrary offset after the offsets
+	requireErrno(t, ErrnoSuccess, mod, EnvironGetName, uint64(resultEnviron), uint64(resultEnvironBuf))
+
+	offset, ok := mod.Memory().ReadUint32Le(resultEnviron + 499*4)
+	require.True(t, ok)
+
+	expected := "KEY499=value499"
+	actual, ok := mod.Memory().Read(offset, uint32(len(expected)+1))
+	require.True(t, ok)
+	require.Equal(t, append([]byte(expected), 0), actual)
+}
+
 func Test_environGet_Errors(t *testing.T) {
 	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().
 		WithEnv("a", "bc").WithEnv("b", "cd"))
@@ -8,6 +8,9 @@ import (
 	"math"
 	"os"
 	pathutil "path"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/internal/platform"
@@ -59,7 +62,7 @@ func fdCloseFn(_ context.Context, mod api.Module, params []uint64) Errno {
 	fd := uint32(params[0])
 
 	if err := fsc.CloseFile(fd); err != nil {
-		return ToErrno(err)
+		return ToErrno(fsc.MapError(err))
 	}
 	return ErrnoSuccess
 }
@@ -67,8 +70,48 @@ func fdCloseFn(_ context.Context, mod api.Module, params []uint64) Errno {
 // fdDatasync is the WASI function named FdDatasyncName which synchronizes
 // the data of a file to disk.
 //
+// # Parameters
+//
+//   - fd: file descriptor to synchronize
+//
+// Result (Errno)
+//
+// The return value is ErrnoSuccess except the following error conditions:
+//   - ErrnoBadf: the fd was not open.
+//   - ErrnoIo: the data could not be synchronized.
+//
+// # Notes
+//
+//   - This is similar to `fdatasync` in POSIX, which unlike `fsync` doesn't
+//     need to flush metadata. Platforms without `fdatasync` use os.File Sync.
+//   - Files which are not an os.File are synchronized with their Sync method,
+//     if present. Otherwise, there is nothing to synchronize.
+//
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-fd_datasyncfd-fd---errno
-var fdDatasync = stubFunction(FdDatasyncName, []api.ValueType{i32}, "fd")
+// and https://linux.die.net/man/2/fdatasync
+var fdDatasync = newHostFunc(FdDatasyncName, fdDatasyncFn, []api.ValueType{i32}, "fd")
+
+func fdDatasyncFn(_ context.Context, mod api.Module, params []uint64) Errno {
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+	fd := uint32(params[0])
+
+	f, ok := fsc.LookupFile(fd)
+	if !ok {
+		return ErrnoBadf
+	}
+
+	var err error
+	switch file := f.File.(type) {
+	case *os.File:
+		err = platform.Fdatasync(file)
+	case interface{ Sync() error }:
+		err = file.Sync()
+	}
+	if err != nil {
+		return ToErrno(fsc.MapError(err))
+	}
+	return ErrnoSuccess
+}
 
 // fdFdstatGet is the WASI function named FdFdstatGetName which returns the
 // attributes of a file descriptor.
@@ -122,24 +165,73 @@ func fdFdstatGetFn(_ context.Context, mod api.Module, params []uint64) Errno {
 		return ErrnoFault
 	}
 
-	var fdflags uint16
 	var stat fs.FileInfo
 	var err error
-	if f, ok := fsc.LookupFile(fd); !ok {
+	f, ok := fsc.LookupFile(fd)
+	if !ok {
 		return ErrnoBadf
 	} else if stat, err = f.File.Stat(); err != nil {
-		return ToErrno(err)
-	} else if _, ok := f.File.(io.Writer); ok {
-		// TODO: maybe cache flags to open instead
-		fdflags = FD_APPEND
+		return ToErrno(fsc.MapError(err))
 	}
 
 	filetype := getWasiFiletype(stat.Mode())
-	writeFdstat(buf, filetype, fdflags)
-
+	writeFdstat(buf, filetype, f.FdFlags)
+
+	// Advertise what a directory allows, so guests can probe capabilities
+	// before attempting operations. Subdirectories have the same rights as
+	// the pre-open they were opened through.
+	if f.IsPreopen || filetype == FILETYPE_DIRECTORY {
+		rightsBase, rightsInheriting := preopenRights(syscallfs.IsReadOnly(fsc.FS()))
+		le.PutUint64(buf[8:], uint64(rightsBase))
+		le.PutUint64(buf[16:], uint64(rightsInheriting))
+	} else if filetype == FILETYPE_CHARACTER_DEVICE || (filetype == FILETYPE_UNKNOWN && f.IsStdio()) {
+		// A terminal or pipe can be read or written, but not seeked.
+		le.PutUint64(buf[8:], uint64(characterDeviceRights(f.File)))
+	}
 	return ErrnoSuccess
 }
 
+const (
+	// preopenRightsRead are the rights of a read-only pre-open directory.
+	preopenRightsRead = RIGHT_PATH_OPEN | RIGHT_FD_READDIR |
+		RIGHT_PATH_FILESTAT_GET | RIGHT_FD_FILESTAT_GET
+
+	// preopenRightsWrite are the rights a pre-open directory additionally
+	// has when its file system is writable.
+	preopenRightsWrite = RIGHT_PATH_CREATE_DIRECTORY | RIGHT_PATH_CREATE_FILE |
+		RIGHT_PATH_RENAME_SOURCE | RIGHT_PATH_RENAME_TARGET |
+		RIGHT_PATH_FILESTAT_SET_SIZE | RIGHT_PATH_FILESTAT_SET_TIMES |
+		RIGHT_PATH_REMOVE_DIRECTORY | RIGHT_PATH_UNLINK_FILE
+
+	// fileRightsRead are the rights inherited by files opened read-only.
+	fileRightsRead = RIGHT_FD_READ | RIGHT_FD_SEEK | RIGHT_FD_TELL |
+		RIGHT_FD_FILESTAT_GET
+)
+
+// characterDeviceRights returns the base rights of a character device, such
+// as a terminal, which are only to read or write depending on the file.
+func characterDeviceRights(f fs.File) (base uint32) {
+	if _, ok := f.(io.Reader); ok {
+		base |= RIGHT_FD_READ
+	}
+	if _, ok := f.(io.Writer); ok {
+		base |= RIGHT_FD_WRITE
+	}
+	return
+}
+
+// preopenRights returns the base and inheriting rights of a pre-open
+// directory or a subdirectory of it, excluding write rights when readOnly.
+func preopenRights(readOnly bool) (base, inheriting uint32) {
+	base = preopenRightsRead
+	inheriting = preopenRightsRead | fileRightsRead
+	if !readOnly {
+		base |= preopenRightsWrite
+		inheriting |= preopenRightsWrite | RIGHT_FD_WRITE
+	}
+	return
+}
+
 var blockFdstat = []byte{
 	FILETYPE_BLOCK_DEVICE, 0, // filetype
 	0, 0, 0, 0, 0, 0, // fdflags
@@ -152,13 +244,58 @@ func writeFdstat(buf []byte, filetype uint8, fdflags uint16) {
 	copy(buf, blockFdstat)
 	buf[0] = filetype
 	buf[2] = byte(fdflags)
+	if filetype == FILETYPE_SOCKET_STREAM {
+		// Sockets can be read and written, but not seeked.
+		le.PutUint64(buf[8:], uint64(RIGHT_FD_READ|RIGHT_FD_WRITE))
+	}
 }
 
 // fdFdstatSetFlags is the WASI function named FdFdstatSetFlagsName which
 // adjusts the flags associated with a file descriptor.
 //
-// See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-fd_fdstat_set_flagsfd-fd-flags-fdflags---errnoand is stubbed for GrainLang per #271
-var fdFdstatSetFlags = stubFunction(FdFdstatSetFlagsName, []wasm.ValueType{i32, i32}, "fd", "flags")
+// # Parameters
+//
+//   - fd: file descriptor to set the flags of
+//   - flags: fdflags, e.g. FD_APPEND, to replace those of the file descriptor
+//
+// Result (Errno)
+//
+// The return value is ErrnoSuccess except the following error conditions:
+//   - ErrnoBadf: `fd` is invalid
+//   - ErrnoInval: `flags` has bits outside the defined fdflags
+//
+// # Notes
+//
+//   - This is similar to `fcntl(fd, F_SETFL, flags)` in POSIX, so flags not
+//     set are cleared.
+//   - Flags are reported by fdFdstatGet. Only FD_APPEND changes how the file
+//     is written, as documented on fdWrite. Clearing it doesn't take a file
+//     opened with FD_APPEND out of append mode.
+//
+// See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-fd_fdstat_set_flagsfd-fd-flags-fdflags---errno
+var fdFdstatSetFlags = newHostFunc(
+	FdFdstatSetFlagsName, fdFdstatSetFlagsFn,
+	[]wasm.ValueType{i32, i32},
+	"fd", "flags",
+)
+
+// fdflagsMask are all the defined fdflags.
+const fdflagsMask = FD_APPEND | FD_DSYNC | FD_NONBLOCK | FD_RSYNC | FD_SYNC
+
+func fdFdstatSetFlagsFn(_ context.Context, mod api.Module, params []uint64) Errno {
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+
+	fd, flags := uint32(params[0]), uint32(params[1])
+
+	f, ok := fsc.LookupFile(fd)
+	if !ok {
+		return ErrnoBadf
+	} else if flags&^uint32(fdflagsMask) != 0 {
+		return ErrnoInval // reserved bits, so don't store anything.
+	}
+	f.FdFlags = uint16(flags)
+	return ErrnoSuccess
+}
 
 // fdFdstatSetRights will not be implemented as rights were removed from WASI.
 //
@@ -208,7 +345,7 @@ var fdFdstatSetRights = stubFunction(
 //
 // The following properties of filestat are not implemented:
 //   - dev: not supported by Golang FS
-//   - ino: not supported by Golang FS
+//   - ino: not supported by Golang FS, we synthesize one from the path
 //   - nlink: not supported by Golang FS, we use 1
 //   - atime: not supported by Golang FS, we use mtim for this
 //   - ctim: not supported by Golang FS, we use mtim for this
@@ -240,11 +377,17 @@ func fdFilestatGetFunc(mod api.Module, fd, resultBuf uint32) Errno {
 
 	stat, err := f.Stat()
 	if err != nil {
-		return ToErrno(err)
+		return ToErrno(fsc.MapError(err))
 	}
 
-	writeFilestat(buf, stat)
+	writeFilestat(buf, stat, f.Inode())
 
+	// Streams such as pipes have no modification time of their own, so report
+	// the current walltime instead of zero.
+	if f.IsStdio() && !stat.Mode().IsRegular() {
+		sec, nsec := mod.(*wasm.CallContext).Sys.Walltime()
+		le.PutUint64(buf[48:], uint64((sec*time.Second.Nanoseconds())+int64(nsec))) // mtim
+	}
 	return ErrnoSuccess
 }
 
@@ -260,7 +403,10 @@ func getWasiFiletype(fileMode fs.FileMode) uint8 {
 		wasiFileType = FILETYPE_REGULAR_FILE
 	} else if fileMode&fs.ModeSymlink != 0 {
 		wasiFileType = FILETYPE_SYMBOLIC_LINK
+	} else if fileMode&fs.ModeSocket != 0 {
+		wasiFileType = FILETYPE_SOCKET_STREAM
 	}
+	// Note: WASI has no filetype for a FIFO, so it is FILETYPE_UNKNOWN.
 	return wasiFileType
 }
 
@@ -275,13 +421,17 @@ var blockFilestat = []byte{
 	0, 0, 0, 0, 0, 0, 0, 0, // ctim
 }
 
-func writeFilestat(buf []byte, stat fs.FileInfo) {
+// writeFilestat is shared by fd_filestat_get and path_filestat_get, so that
+// both report the same filestat for the same file. The ino is synthesized from
+// the path, as Golang FS doesn't expose one.
+func writeFilestat(buf []byte, stat fs.FileInfo, ino uint64) {
 	filetype := getWasiFiletype(stat.Mode())
 	filesize := uint64(stat.Size())
 	atimeNsec, mtimeNsec, ctimeNsec := platform.StatTimes(stat)
 
 	// memory is re-used, so ensure the result is defaulted.
 	copy(buf, blockFilestat[:32])
+	le.PutUint64(buf[8:], ino) // ino
 	buf[16] = filetype
 	le.PutUint64(buf[32:], filesize)          // filesize
 	le.PutUint64(buf[40:], uint64(atimeNsec)) // atim
@@ -295,16 +445,130 @@ func writeFilestat(buf []byte, stat fs.FileInfo) {
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-fd_filestat_set_sizefd-fd-size-filesize---errno
 var fdFilestatSetSize = stubFunction(FdFilestatSetSizeName, []wasm.ValueType{i32, i64}, "fd", "size")
 
-// fdFilestatSetTimes is the WASI function named functionFdFilestatSetTimes
+// fdFilestatSetTimes is the WASI function named FdFilestatSetTimesName
 // which adjusts the times of an open file.
 //
+// # Parameters
+//
+//   - fd: file descriptor of the file to adjust
+//   - atim: access time in nanoseconds since epoch, used with FSTFLAGS_ATIM
+//   - mtim: modification time in nanoseconds since epoch, used with
+//     FSTFLAGS_MTIM
+//   - fstFlags: bit set of FSTFLAGS_* selecting which times to adjust
+//
+// Result (Errno)
+//
+// The return value is ErrnoSuccess except the following error conditions:
+//   - ErrnoBadf: the fd was not open.
+//   - ErrnoInval: both FSTFLAGS_ATIM and FSTFLAGS_ATIM_NOW, or both
+//     FSTFLAGS_MTIM and FSTFLAGS_MTIM_NOW were set.
+//   - ErrnoInval: `fstFlags` has bits outside the defined FSTFLAGS_*.
+//   - ErrnoNotsup: the fd has no path in the file system, such as stdio.
+//   - ErrnoNotsup: a *_NOW flag was set, but the walltime clock is
+//     unsupported, per wazero.RuntimeConfig WithStrictClocks.
+//
+// # Notes
+//
+//   - This is similar to `futimens` in POSIX.
+//   - A time not selected by fstFlags is preserved.
+//   - Buffered writes to the file are flushed first, so that flushing them
+//     later doesn't overwrite the times.
+//   - The times are set by the name the file was opened with, which can
+//     drift on rename.
+//
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-fd_filestat_set_timesfd-fd-atim-timestamp-mtim-timestamp-fst_flags-fstflags---errno
-var fdFilestatSetTimes = stubFunction(
-	FdFilestatSetTimesName,
+// and https://linux.die.net/man/3/futimens
+var fdFilestatSetTimes = newHostFunc(
+	FdFilestatSetTimesName, fdFilestatSetTimesFn,
 	[]wasm.ValueType{i32, i64, i64, i32},
 	"fd", "atim", "mtim", "fst_flags",
 )
 
+func fdFilestatSetTimesFn(_ context.Context, mod api.Module, params []uint64) Errno {
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+	fd := uint32(params[0])
+
+	f, ok := fsc.LookupFile(fd)
+	if !ok {
+		return ErrnoBadf
+	}
+
+	pathName := f.Name
+	if f.IsPreopen {
+		pathName = "."
+	} else if pathName == "" {
+		return ErrnoNotsup // e.g. stdio, which has no path in the file system.
+	}
+
+	// Flush any buffered writes, e.g. from syscallfs.NewBufferedFS, as
+	// otherwise a later flush would overwrite the modification time.
+	var err error
+	switch file := f.File.(type) {
+	case *os.File: // writes aren't buffered
+	case interface{ Sync() error }:
+		err = file.Sync()
+	}
+	if err != nil {
+		return ToErrno(fsc.MapError(err))
+	}
+	return setTimes(mod, pathName, f.Stat, int64(params[1]), int64(params[2]), uint32(params[3]))
+}
+
+// fstflagsMask are all the defined fstflags.
+const fstflagsMask = FSTFLAGS_ATIM | FSTFLAGS_ATIM_NOW | FSTFLAGS_MTIM | FSTFLAGS_MTIM_NOW
+
+// setTimes is shared by fd_filestat_set_times and path_filestat_set_times.
+// As syscallfs.FS Utimes sets both times, stat is only called when one of
+// them must be preserved.
+func setTimes(mod api.Module, pathName string, stat func() (fs.FileInfo, error), atim, mtim int64, rawFstFlags uint32) Errno {
+	if rawFstFlags&^uint32(fstflagsMask) != 0 {
+		return ErrnoInval // reserved bits
+	}
+	fstFlags := uint16(rawFstFlags)
+	if fstFlags&(FSTFLAGS_ATIM|FSTFLAGS_ATIM_NOW) == FSTFLAGS_ATIM|FSTFLAGS_ATIM_NOW ||
+		fstFlags&(FSTFLAGS_MTIM|FSTFLAGS_MTIM_NOW) == FSTFLAGS_MTIM|FSTFLAGS_MTIM_NOW {
+		return ErrnoInval
+	}
+
+	sysCtx := mod.(*wasm.CallContext).Sys
+	fsc := sysCtx.FS()
+
+	var atimeNsec, mtimeNsec int64
+	if fstFlags&(FSTFLAGS_ATIM|FSTFLAGS_ATIM_NOW) == 0 ||
+		fstFlags&(FSTFLAGS_MTIM|FSTFLAGS_MTIM_NOW) == 0 {
+		st, err := stat()
+		if err != nil {
+			return ToErrno(fsc.MapError(err))
+		}
+		atimeNsec, mtimeNsec, _ = platform.StatTimes(st)
+	}
+
+	var nowNsec int64
+	if fstFlags&(FSTFLAGS_ATIM_NOW|FSTFLAGS_MTIM_NOW) != 0 {
+		if !sysCtx.WalltimeSupported() {
+			return ErrnoNotsup
+		}
+		sec, nsec := sysCtx.Walltime()
+		nowNsec = sec*time.Second.Nanoseconds() + int64(nsec)
+	}
+
+	if fstFlags&FSTFLAGS_ATIM != 0 {
+		atimeNsec = atim
+	} else if fstFlags&FSTFLAGS_ATIM_NOW != 0 {
+		atimeNsec = nowNsec
+	}
+	if fstFlags&FSTFLAGS_MTIM != 0 {
+		mtimeNsec = mtim
+	} else if fstFlags&FSTFLAGS_MTIM_NOW != 0 {
+		mtimeNsec = nowNsec
+	}
+
+	if err := fsc.FS().Utimes(pathName, atimeNsec, mtimeNsec); err != nil {
+		return ToErrno(fsc.MapError(err))
+	}
+	return ErrnoSuccess
+}
+
 // fdPread is the WASI function named FdPreadName which reads from a file
 // descriptor, without using and updating the file descriptor's offset.
 //
@@ -317,8 +581,8 @@ var fdPread = newHostFunc(
 	"fd", "iovs", "iovs_len", "offset", "result.nread",
 )
 
-func fdPreadFn(_ context.Context, mod api.Module, params []uint64) Errno {
-	return fdReadOrPread(mod, params, true)
+func fdPreadFn(ctx context.Context, mod api.Module, params []uint64) Errno {
+	return fdReadOrPread(ctx, mod, params, true)
 }
 
 // fdPrestatGet is the WASI function named FdPrestatGetName which returns
@@ -386,7 +650,7 @@ func fdPrestatGetFn(_ context.Context, mod api.Module, params []uint64) Errno {
 // Result (Errno)
 //
 // The return value is ErrnoSuccess except the following error conditions:
-//   - ErrnoBadf: `fd` is invalid
+//   - ErrnoBadf: `fd` is invalid or the `fd` is not a pre-opened directory
 //   - ErrnoFault: `path` points to an offset out of memory
 //   - ErrnoNametoolong: `pathLen` is longer than the actual length of the result
 //
@@ -456,6 +720,7 @@ var fdPwrite = stubFunction(
 // The return value is ErrnoSuccess except the following error conditions:
 //   - ErrnoBadf: `fd` is invalid
 //   - ErrnoFault: `iovs` or `resultNread` point to an offset out of memory
+//   - ErrnoInval: `iovsCount` is larger than iovMax
 //   - ErrnoIo: a file system error
 //
 // For example, this function needs to first read `iovs` to determine where
@@ -483,7 +748,12 @@ var fdPwrite = stubFunction(
 //	                         iovs[1].offset --+           |
 //	                                        resultNread --+
 //
-// Note: This is similar to `readv` in POSIX. https://linux.die.net/man/3/readv
+// # Notes
+//
+//   - This is similar to `readv` in POSIX. https://linux.die.net/man/3/readv
+//   - When ModuleConfig WithFSReadChunkSize is set, each iovec is read with
+//     calls no larger than the chunk size, stopping early on a short read or
+//     when the context is done.
 //
 // See fdWrite
 // and https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#fd_read
@@ -493,11 +763,11 @@ var fdRead = newHostFunc(
 	"fd", "iovs", "iovs_len", "result.nread",
 )
 
-func fdReadFn(_ context.Context, mod api.Module, params []uint64) Errno {
-	return fdReadOrPread(mod, params, false)
+func fdReadFn(ctx context.Context, mod api.Module, params []uint64) Errno {
+	return fdReadOrPread(ctx, mod, params, false)
 }
 
-func fdReadOrPread(mod api.Module, params []uint64, isPread bool) Errno {
+func fdReadOrPread(ctx context.Context, mod api.Module, params []uint64, isPread bool) Errno {
 	mem := mod.Memory()
 	fsc := mod.(*wasm.CallContext).Sys.FS()
 
@@ -505,6 +775,10 @@ func fdReadOrPread(mod api.Module, params []uint64, isPread bool) Errno {
 	iovs := uint32(params[1])
 	iovsCount := uint32(params[2])
 
+	if iovsCount > iovMax {
+		return ErrnoInval
+	}
+
 	var offset int64
 	var resultNread uint32
 	if isPread {
@@ -547,6 +821,10 @@ func fdReadOrPread(mod api.Module, params []uint64, isPread bool) Errno {
 		}
 	}
 
+	if chunkSize := fsc.ReadChunkSize; chunkSize > 0 {
+		read = chunkedRead(ctx, read, chunkSize)
+	}
+
 	var nread uint32
 	iovsStop := iovsCount << 3 // iovsCount * 8
 	iovsBuf, ok := mem.Read(iovs, iovsStop)
@@ -566,7 +844,7 @@ func fdReadOrPread(mod api.Module, params []uint64, isPread bool) Errno {
 		n, err := read(b)
 		nread += uint32(n)
 
-		shouldContinue, errno := fdRead_shouldContinueRead(uint32(n), l, err)
+		shouldContinue, errno := fdRead_shouldContinueRead(uint32(n), l, fsc.MapError(err))
 		if errno != ErrnoSuccess {
 			return errno
 		} else if !shouldContinue {
@@ -580,16 +858,49 @@ func fdReadOrPread(mod api.Module, params []uint64, isPread bool) Errno {
 	}
 }
 
+// chunkedRead returns a read function which fills the buffer with reads no
+// larger than chunkSize. It returns early on a short read, an error, or when
+// the context is done.
+func chunkedRead(ctx context.Context, read func([]byte) (int, error), chunkSize uint32) func([]byte) (int, error) {
+	return func(p []byte) (n int, err error) {
+		for n < len(p) {
+			if n > 0 {
+				if err = ctx.Err(); err != nil {
+					return
+				}
+			}
+			chunk := p[n:]
+			if uint32(len(chunk)) > chunkSize {
+				chunk = chunk[:chunkSize]
+			}
+			var m int
+			m, err = read(chunk)
+			n += m
+			if err != nil || m < len(chunk) {
+				return
+			}
+		}
+		return
+	}
+}
+
+// iovMax is the maximum count of iovecs accepted by fd_read, fd_pread and
+// fd_write, like IOV_MAX in POSIX. This bounds the work a guest can request,
+// and prevents `iovsCount << 3` from overflowing.
+const iovMax = 1024
+
 // fdRead_shouldContinueRead decides whether to continue reading the next iovec
 // based on the amount read (n/l) and a possible error returned from io.Reader.
 //
 // Note: When there are both bytes read (n) and an error, this continues.
 // See /RATIONALE.md "Why ignore the error returned by io.Reader when n > 1?"
+//
+// The caller is expected to have applied sys.FSContext MapError to err.
 func fdRead_shouldContinueRead(n, l uint32, err error) (bool, Errno) {
 	if errors.Is(err, io.EOF) {
 		return false, ErrnoSuccess // EOF isn't an error, and we shouldn't continue.
 	} else if err != nil && n == 0 {
-		return false, ErrnoIo
+		return false, ToErrno(err)
 	} else if err != nil {
 		return false, ErrnoSuccess // Allow the caller to process n bytes.
 	}
@@ -600,6 +911,16 @@ func fdRead_shouldContinueRead(n, l uint32, err error) (bool, Errno) {
 // fdReaddir is the WASI function named FdReaddirName which reads directory
 // entries from a directory.
 //
+// The directory is snapshot when read with cookie zero. Entries added or
+// removed afterwards aren't visible until the guest rewinds by reading with
+// cookie zero again.
+//
+// # Notes
+//
+//   - This returns ErrnoBadf, not ErrnoNotdir, when `fd` isn't a directory,
+//     such as a regular file opened without O_DIRECTORY. WASI doesn't define
+//     which to return, and Rust crashes on ErrnoNotdir. See openedDir.
+//
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-fd_readdirfd-fd-buf-pointeru8-buf_len-size-cookie-dircookie---errno-size
 var fdReaddir = newHostFunc(
 	FdReaddirName, fdReaddirFn,
@@ -632,9 +953,22 @@ func fdReaddirFn(_ context.Context, mod api.Module, params []uint64) Errno {
 		return errno
 	}
 
-	// expect a cookie only if we are continuing a read.
-	if cookie == 0 && dir.CountRead > 0 {
-		return ErrnoInval // cookie is minimally one.
+	// A zero cookie starts a new listing, so snapshot the directory.
+	if cookie == 0 {
+		if dir.CountRead > 0 { // rewind
+			if err := fsc.Rewind(fd); err != nil {
+				return ToErrno(fsc.MapError(err))
+			}
+			if rd, dir, errno = openedDir(fsc, fd); errno != ErrnoSuccess {
+				return errno
+			}
+		}
+		l, err := rd.ReadDir(-1)
+		if err != nil {
+			return ToErrno(fsc.MapError(err))
+		}
+		dir.CountRead = uint64(len(l))
+		dir.Entries = append(dir.Entries[:0], l...)
 	}
 
 	// First, determine the maximum directory entries that can be encoded as
@@ -661,10 +995,12 @@ func fdReaddirFn(_ context.Context, mod api.Module, params []uint64) Errno {
 	if entryCount := len(entries); entryCount < maxDirEntries {
 		if l, err := rd.ReadDir(maxDirEntries - entryCount); err != io.EOF {
 			if err != nil {
-				return ErrnoIo
+				return ToErrno(fsc.MapError(err))
 			}
 			dir.CountRead += uint64(len(l))
-			entries = append(entries, l...)
+			// Shift unread entries to the front of the cache, so that its
+			// backing array is reused instead of allocating a new one.
+			entries = append(dir.Entries[:copy(dir.Entries, entries)], l...)
 			// Replace the cache with up to maxDirEntries, starting at cookie.
 			dir.Entries = entries
 		}
@@ -721,7 +1057,6 @@ func lastDirEntries(dir *sys.ReadDir, cookie int64) (entries []fs.DirEntry, errn
 	switch {
 	case cookiePos < 0: // cookie is asking for results outside our window.
 		errno = ErrnoNosys // we can't implement directory seeking backwards.
-	case cookiePos == 0: // cookie is asking for the next page.
 	case cookiePos > entryCount:
 		errno = ErrnoInval // invalid as we read that far, yet.
 	case cookiePos > 0: // truncate so to avoid large lists.
@@ -824,13 +1159,13 @@ func writeDirents(
 		return
 	}
 
-	// Write a dirent without its name
-	dirent := make([]byte, DirentSize)
+	// Write a dirent without its name, on the stack to avoid an allocation.
+	var dirent [DirentSize]byte
 	e := entries[i]
-	writeDirent(dirent, d_next, uint32(len(e.Name())), e.IsDir())
+	writeDirent(dirent[:], d_next, uint32(len(e.Name())), e.IsDir())
 
 	// Potentially truncate it
-	copy(dirents[pos:], dirent)
+	copy(dirents[pos:], dirent[:])
 }
 
 // writeDirent writes DirentSize bytes
@@ -850,7 +1185,10 @@ func writeDirent(buf []byte, dNext uint64, dNamlen uint32, dType bool) {
 func openedDir(fsc *sys.FSContext, fd uint32) (fs.ReadDirFile, *sys.ReadDir, Errno) {
 	if f, ok := fsc.LookupFile(fd); !ok {
 		return nil, nil, ErrnoBadf
-	} else if d, ok := f.File.(fs.ReadDirFile); !ok {
+	} else if d, ok := f.File.(fs.ReadDirFile); !ok || !f.IsDir() {
+		// Note: A regular file can be a fs.ReadDirFile, such as an os.File,
+		// so check it is a directory, too.
+		//
 		// fd_readdir docs don't indicate whether to return ErrnoNotdir or
 		// ErrnoBadf. It has been noticed that rust will crash on ErrnoNotdir,
 		// and POSIX C ref seems to not return this, so we don't either.
@@ -869,8 +1207,35 @@ func openedDir(fsc *sys.FSContext, fd uint32) (fs.ReadDirFile, *sys.ReadDir, Err
 // fdRenumber is the WASI function named FdRenumberName which atomically
 // replaces a file descriptor by renumbering another file descriptor.
 //
+// # Parameters
+//
+//   - fd: file descriptor to renumber
+//   - to: file descriptor to replace, which is closed
+//
+// Result (Errno)
+//
+// The return value is ErrnoSuccess except the following error conditions:
+//   - ErrnoBadf: `fd` or `to` is invalid
+//   - ErrnoNotsup: `fd` or `to` is a pre-opened directory
+//
+// Note: The offset and fdflags of `fd` move with it to `to`.
+//
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-fd_renumberfd-fd-to-fd---errno
-var fdRenumber = stubFunction(FdRenumberName, []wasm.ValueType{i32, i32}, "fd", "to")
+var fdRenumber = newHostFunc(
+	FdRenumberName, fdRenumberFn,
+	[]wasm.ValueType{i32, i32},
+	"fd", "to",
+)
+
+func fdRenumberFn(_ context.Context, mod api.Module, params []uint64) Errno {
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+
+	from, to := uint32(params[0]), uint32(params[1])
+	if err := fsc.Renumber(from, to); err != nil {
+		return ToErrno(fsc.MapError(err))
+	}
+	return ErrnoSuccess
+}
 
 // fdSeek is the WASI function named FdSeekName which moves the offset of a
 // file descriptor.
@@ -892,7 +1257,9 @@ var fdRenumber = stubFunction(FdRenumberName, []wasm.ValueType{i32, i32}, "fd",
 // The return value is ErrnoSuccess except the following error conditions:
 //   - ErrnoBadf: `fd` is invalid
 //   - ErrnoFault: `resultNewoffset` points to an offset out of memory
-//   - ErrnoInval: `whence` is an invalid value
+//   - ErrnoInval: `whence` is an invalid value, `offset` is negative with
+//     SeekStart, or the resulting offset of SeekCurrent would overflow or be
+//     negative
 //   - ErrnoIo: a file system error
 //
 // For example, if fd 3 is a file with offset 0, and parameters fd=3, offset=4,
@@ -935,9 +1302,21 @@ func fdSeekFn(_ context.Context, mod api.Module, params []uint64) Errno {
 		return ErrnoInval
 	}
 
+	// Seeking before the start is invalid, regardless of the seeker.
+	if whence == io.SeekStart && int64(offset) < 0 {
+		return ErrnoInval
+	}
+
+	// Detect overflow instead of letting the seeker wrap the position.
+	if whence == io.SeekCurrent && offset != 0 {
+		if errno := checkSeekCurrent(fsc, seeker, int64(offset)); errno != ErrnoSuccess {
+			return errno
+		}
+	}
+
 	newOffset, err := seeker.Seek(int64(offset), int(whence))
 	if err != nil {
-		return ErrnoIo
+		return ToErrno(fsc.MapError(err))
 	}
 
 	if !mod.Memory().WriteUint64Le(resultNewoffset, uint64(newOffset)) {
@@ -946,17 +1325,90 @@ func fdSeekFn(_ context.Context, mod api.Module, params []uint64) Errno {
 	return ErrnoSuccess
 }
 
+// checkSeekCurrent returns ErrnoInval if adding the offset to the current
+// position would overflow int64 or result in a negative position.
+func checkSeekCurrent(fsc *sys.FSContext, seeker io.Seeker, offset int64) Errno {
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return ToErrno(fsc.MapError(err))
+	}
+	if offset > 0 && current > math.MaxInt64-offset {
+		return ErrnoInval // overflow
+	} else if offset < 0 && current+offset < 0 {
+		return ErrnoInval // underflow
+	}
+	return ErrnoSuccess
+}
+
 // fdSync is the WASI function named FdSyncName which synchronizes the data
 // and metadata of a file to disk.
 //
+// # Parameters
+//
+//   - fd: file descriptor to synchronize
+//
+// Result (Errno)
+//
+// The return value is ErrnoSuccess except the following error conditions:
+//   - ErrnoBadf: the fd was not open.
+//   - ErrnoIo: the file could not be synchronized.
+//
+// # Notes
+//
+//   - This is similar to `fsync` in POSIX.
+//   - Files are synchronized with their Sync method, such as os.File Sync, if
+//     present. Otherwise, there is nothing to synchronize.
+//
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-fd_syncfd-fd---errno
-var fdSync = stubFunction(FdSyncName, []api.ValueType{i32}, "fd")
+// and https://linux.die.net/man/2/fsync
+var fdSync = newHostFunc(FdSyncName, fdSyncFn, []api.ValueType{i32}, "fd")
+
+func fdSyncFn(_ context.Context, mod api.Module, params []uint64) Errno {
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+	fd := uint32(params[0])
+
+	f, ok := fsc.LookupFile(fd)
+	if !ok {
+		return ErrnoBadf
+	}
+
+	if file, ok := f.File.(interface{ Sync() error }); ok {
+		if err := file.Sync(); err != nil {
+			return ToErrno(fsc.MapError(err))
+		}
+	}
+	return ErrnoSuccess
+}
 
 // fdTell is the WASI function named FdTellName which returns the current
 // offset of a file descriptor.
 //
+// # Parameters
+//
+//   - fd: file descriptor to get the offset of
+//   - resultOffset: offset in api.Memory to write the current offset to,
+//     relative to start of the file
+//
+// Result (Errno)
+//
+// The return value is ErrnoSuccess except the following error conditions:
+//   - ErrnoBadf: `fd` is invalid
+//   - ErrnoFault: `resultOffset` points to an offset out of memory
+//   - ErrnoIo: a file system error
+//
+// Note: This is similar to `lseek(fd, 0, SEEK_CUR)` in POSIX.
+//
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-fd_tellfd-fd---errno-filesize
-var fdTell = stubFunction(FdTellName, []wasm.ValueType{i32, i32}, "fd", "result.offset")
+var fdTell = newHostFunc(
+	FdTellName, fdTellFn,
+	[]wasm.ValueType{i32, i32},
+	"fd", "result.offset",
+)
+
+func fdTellFn(ctx context.Context, mod api.Module, params []uint64) Errno {
+	fd, resultOffset := params[0], params[1]
+	return fdSeekFn(ctx, mod, []uint64{fd, 0, io.SeekCurrent, resultOffset})
+}
 
 // fdWrite is the WASI function named FdWriteName which writes to a file
 // descriptor.
@@ -977,6 +1429,8 @@ var fdTell = stubFunction(FdTellName, []wasm.ValueType{i32, i32}, "fd", "result.
 // The return value is ErrnoSuccess except the following error conditions:
 //   - ErrnoBadf: `fd` is invalid
 //   - ErrnoFault: `iovs` or `resultNwritten` point to an offset out of memory
+//   - ErrnoInval: `iovsCount` is larger than iovMax
+//   - ErrnoPipe: `fd` is a pipe whose read end was closed
 //   - ErrnoIo: a file system error
 //
 // For example, this function needs to first read `iovs` to determine what to
@@ -1011,7 +1465,13 @@ var fdTell = stubFunction(FdTellName, []wasm.ValueType{i32, i32}, "fd", "result.
 //	[]byte{ 0..24, ?, 6, 0, 0, 0', ? }
 //	 resultNwritten --^
 //
-// Note: This is similar to `writev` in POSIX. https://linux.die.net/man/3/writev
+// # Notes
+//
+//   - This is similar to `writev` in POSIX. https://linux.die.net/man/3/writev
+//   - Files opened with FD_APPEND are in append mode, so each write is
+//     atomically positioned at the end of the file, even if it was appended
+//     to via another file descriptor. When FD_APPEND was added later via
+//     fd_fdstat_set_flags, the file is seeked to its end before each write.
 //
 // See fdRead
 // https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#ciovec
@@ -1031,11 +1491,22 @@ func fdWriteFn(_ context.Context, mod api.Module, params []uint64) Errno {
 	iovsCount := uint32(params[2])
 	resultNwritten := uint32(params[3])
 
+	if iovsCount > iovMax {
+		return ErrnoInval
+	}
+
 	writer := sys.WriterForFile(fsc, fd)
 	if writer == nil {
 		return ErrnoBadf
 	}
 
+	// FD_APPEND added after opening didn't put the file in append mode, so
+	// seek to the end before each write instead.
+	var appendSeeker io.Seeker
+	if f, ok := fsc.LookupFile(fd); ok && f.FdFlags&FD_APPEND != 0 {
+		appendSeeker, _ = f.File.(io.Seeker)
+	}
+
 	var err error
 	var nwritten uint32
 	iovsStop := iovsCount << 3 // iovsCount * 8
@@ -1056,9 +1527,14 @@ func fdWriteFn(_ context.Context, mod api.Module, params []uint64) Errno {
 			if !ok {
 				return ErrnoFault
 			}
+			if appendSeeker != nil {
+				if _, err = appendSeeker.Seek(0, io.SeekEnd); err != nil {
+					return ToErrno(fsc.MapError(err))
+				}
+			}
 			n, err = writer.Write(b)
 			if err != nil {
-				return ErrnoIo
+				return writeErrno(fsc.MapError(err))
 			}
 		}
 		nwritten += uint32(n)
@@ -1070,6 +1546,20 @@ func fdWriteFn(_ context.Context, mod api.Module, params []uint64) Errno {
 	return ErrnoSuccess
 }
 
+// writeErrno maps an error from io.Writer.Write to an Errno. As SIGPIPE isn't
+// possible, writes to a pipe whose read end was closed return ErrnoPipe.
+func writeErrno(err error) Errno {
+	switch {
+	case errors.Is(err, io.ErrClosedPipe), errors.Is(err, os.ErrClosed), errors.Is(err, syscall.EPIPE):
+		return ErrnoPipe
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return ToErrno(errno)
+	}
+	return ErrnoIo
+}
+
 // pathCreateDirectory is the WASI function named PathCreateDirectoryName which
 // creates a directory.
 //
@@ -1085,6 +1575,8 @@ func fdWriteFn(_ context.Context, mod api.Module, params []uint64) Errno {
 //   - ErrnoBadf: `fd` is invalid
 //   - ErrnoNoent: `path` does not exist.
 //   - ErrnoNotdir: `path` is a file
+//   - ErrnoNospc: there is no space left to create the directory
+//   - ErrnoNametoolong: a component of `path` is longer than nameMax
 //
 // # Notes
 //   - This is similar to mkdirat in POSIX.
@@ -1110,7 +1602,7 @@ func pathCreateDirectoryFn(_ context.Context, mod api.Module, params []uint64) E
 	}
 
 	if err := fsc.FS().Mkdir(pathName, 0o700); err != nil {
-		return ToErrno(err)
+		return ToErrno(fsc.MapError(err))
 	}
 
 	return ErrnoSuccess
@@ -1139,7 +1631,8 @@ func pathCreateDirectoryFn(_ context.Context, mod api.Module, params []uint64) E
 //   - ErrnoNoent: could not find the path
 //
 // The rest of this implementation matches that of fdFilestatGet, so is not
-// repeated here.
+// repeated here. A `path` of "." or empty stats the directory `fd` refers to,
+// such as the pre-open root.
 //
 // Note: This is similar to `fstatat` in POSIX.
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-path_filestat_getfd-fd-flags-lookupflags-path-string---errno-filestat
@@ -1167,12 +1660,17 @@ func pathFilestatGetFn(_ context.Context, mod api.Module, params []uint64) Errno
 		return errno
 	}
 
+	// An empty path stats the directory itself, which is "." at the root.
+	if pathName == "" {
+		pathName = "."
+	}
+
 	resultBuf := uint32(params[4])
 
 	// Stat the file without allocating a file descriptor
 	stat, err := syscallfs.StatPath(fsc.FS(), pathName)
 	if err != nil {
-		return ToErrno(err)
+		return ToErrno(fsc.MapError(err))
 	}
 
 	// Write the stat result to memory
@@ -1180,7 +1678,7 @@ func pathFilestatGetFn(_ context.Context, mod api.Module, params []uint64) Errno
 	if !ok {
 		return ErrnoFault
 	}
-	writeFilestat(buf, stat)
+	writeFilestat(buf, stat, sys.SynthesizeInode(pathName))
 
 	return ErrnoSuccess
 }
@@ -1188,13 +1686,70 @@ func pathFilestatGetFn(_ context.Context, mod api.Module, params []uint64) Errno
 // pathFilestatSetTimes is the WASI function named PathFilestatSetTimesName
 // which adjusts the timestamps of a file or directory.
 //
+// # Parameters
+//
+//   - fd: file descriptor of a directory that `path` is relative to
+//   - flags: flags to indicate how to resolve `path`
+//   - path: offset in api.Memory to read the path string from
+//   - pathLen: length of `path`
+//   - atim: access time in nanoseconds since epoch, used with FSTFLAGS_ATIM
+//   - mtim: modification time in nanoseconds since epoch, used with
+//     FSTFLAGS_MTIM
+//   - fstFlags: bit set of FSTFLAGS_* selecting which times to adjust
+//
+// Result (Errno)
+//
+// The return value is ErrnoSuccess except the following error conditions:
+//   - ErrnoBadf: `fd` is invalid
+//   - ErrnoNotdir: `fd` is not a directory
+//   - ErrnoNoent: `path` does not exist.
+//   - ErrnoInval: both FSTFLAGS_ATIM and FSTFLAGS_ATIM_NOW, or both
+//     FSTFLAGS_MTIM and FSTFLAGS_MTIM_NOW were set.
+//   - ErrnoInval: `fstFlags` has bits outside the defined FSTFLAGS_*.
+//   - ErrnoNotsup: a *_NOW flag was set, but the walltime clock is
+//     unsupported, per wazero.RuntimeConfig WithStrictClocks.
+//   - ErrnoFault: `path` is out of memory bounds
+//
+// # Notes
+//
+//   - This is similar to `utimensat` in POSIX.
+//   - A time not selected by fstFlags is preserved.
+//   - Symbolic links are always followed, as syscallfs.FS Utimes does.
+//
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-path_filestat_set_timesfd-fd-flags-lookupflags-path-string-atim-timestamp-mtim-timestamp-fst_flags-fstflags---errno
-var pathFilestatSetTimes = stubFunction(
-	PathFilestatSetTimesName,
+// and https://linux.die.net/man/3/utimensat
+var pathFilestatSetTimes = newHostFunc(
+	PathFilestatSetTimesName, pathFilestatSetTimesFn,
 	[]wasm.ValueType{i32, i32, i32, i32, i64, i64, i32},
 	"fd", "flags", "path", "path_len", "atim", "mtim", "fst_flags",
 )
 
+func pathFilestatSetTimesFn(_ context.Context, mod api.Module, params []uint64) Errno {
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+
+	dirFD := uint32(params[0])
+
+	// TODO: flags is a lookupflags and it only has one bit: symlink_follow
+	// https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#lookupflags
+	_ /* flags */ = uint32(params[1])
+
+	path := uint32(params[2])
+	pathLen := uint32(params[3])
+
+	pathName, errno := atPath(fsc, mod.Memory(), dirFD, path, pathLen)
+	if errno != ErrnoSuccess {
+		return errno
+	}
+
+	// An empty path adjusts the directory itself, which is "." at the root.
+	if pathName == "" {
+		pathName = "."
+	}
+
+	stat := func() (fs.FileInfo, error) { return syscallfs.StatPath(fsc.FS(), pathName) }
+	return setTimes(mod, pathName, stat, int64(params[4]), int64(params[5]), uint32(params[6]))
+}
+
 // pathLink is the WASI function named PathLinkName which adjusts the
 // timestamps of a file or directory.
 //
@@ -1231,6 +1786,9 @@ var pathLink = stubFunction(
 //   - ErrnoNoent: `path` does not exist.
 //   - ErrnoExist: `path` exists, while `oFlags` requires that it must not.
 //   - ErrnoNotdir: `path` is not a directory, while `oFlags` requires it.
+//   - ErrnoNametoolong: a component of `path` is longer than nameMax
+//   - ErrnoNotcapable: `oFlags` include O_CREAT or O_TRUNC, but the pre-open
+//     lacks the rights to create or resize files.
 //   - ErrnoIo: a file system error
 //
 // For example, this function needs to first read `path` to determine the file
@@ -1291,13 +1849,29 @@ func pathOpenFn(_ context.Context, mod api.Module, params []uint64) Errno {
 
 	fileOpenFlags, isDir := openFlags(oflags, fdflags)
 
+	// Like POSIX, a trailing slash implies O_DIRECTORY, e.g. "dir/". This is
+	// checked on the guest path, as atPath cleans it away.
+	if pathLen > 0 {
+		if b, _ := mod.Memory().ReadByte(path + pathLen - 1); b == '/' {
+			isDir = true
+		}
+	}
+
 	if isDir && oflags&O_CREAT != 0 {
 		return ErrnoInval // use pathCreateDirectory!
 	}
 
+	// Creating or truncating a file requires rights a read-only pre-open
+	// doesn't grant, so fail early with the capability error.
+	if required := oflagsRights(oflags); required != 0 {
+		if base, _ := preopenRights(syscallfs.IsReadOnly(fsc.FS())); base&required != required {
+			return ErrnoNotcapable
+		}
+	}
+
 	newFD, err := fsc.OpenFile(pathName, fileOpenFlags, 0o600)
 	if err != nil {
-		return ToErrno(err)
+		return ToErrno(fsc.MapError(err))
 	}
 
 	// Check any flags that require the file to evaluate.
@@ -1310,6 +1884,11 @@ func pathOpenFn(_ context.Context, mod api.Module, params []uint64) Errno {
 		}
 	}
 
+	// Record the fdflags, so that fd_fdstat_get returns them.
+	if f, ok := fsc.LookupFile(newFD); ok {
+		f.FdFlags = fdflags & fdflagsMask
+	}
+
 	if !mod.Memory().WriteUint32Le(resultOpenedFd, newFD) {
 		_ = fsc.CloseFile(newFD)
 		return ErrnoFault
@@ -1340,6 +1919,10 @@ func atPath(fsc *sys.FSContext, mem api.Memory, dirFD, path, pathLen uint32) (st
 	}
 	pathName := string(b)
 
+	if !isNameMaxValid(pathName) {
+		return "", ErrnoNametoolong
+	}
+
 	if f, ok := fsc.LookupFile(dirFD); !ok {
 		return "", ErrnoBadf // closed
 	} else if f.IsDir() {
@@ -1349,27 +1932,59 @@ func atPath(fsc *sys.FSContext, mem api.Memory, dirFD, path, pathLen uint32) (st
 	}
 }
 
+// nameMax is the maximum length in bytes of a single path component, which
+// is NAME_MAX on Linux and macOS. This is checked before calling the file
+// system, so that the result is the same regardless of the host.
+const nameMax = 255
+
+// isNameMaxValid returns false if any component of the path is longer than
+// nameMax.
+func isNameMaxValid(path string) bool {
+	for len(path) > nameMax {
+		i := strings.IndexByte(path, '/')
+		if i == -1 {
+			return false
+		} else if i > nameMax {
+			return false
+		}
+		path = path[i+1:]
+	}
+	return true
+}
+
+// preopenPath returns ErrnoBadf for any file descriptor that isn't a
+// pre-open, even if open, as libc scans upward from the first pre-open until
+// this error to discover them all.
 func preopenPath(fsc *sys.FSContext, dirFD uint32) (string, Errno) {
-	if f, ok := fsc.LookupFile(dirFD); !ok {
-		return "", ErrnoBadf // closed
-	} else if !f.IsPreopen {
-		return "", ErrnoInval
+	if path, ok := fsc.PreopenPath(dirFD); !ok {
+		return "", ErrnoBadf
 	} else {
-		// TODO: multiple pre-opens
-		return fsc.FS().Path(), ErrnoSuccess
+		return path, ErrnoSuccess
 	}
 }
 
+// oflagsRights returns the rights a directory needs to open a path with the
+// given oflags.
+func oflagsRights(oflags uint16) (rights uint32) {
+	if oflags&O_CREAT != 0 {
+		rights |= RIGHT_PATH_CREATE_FILE
+	}
+	if oflags&O_TRUNC != 0 {
+		rights |= RIGHT_PATH_FILESTAT_SET_SIZE
+	}
+	return
+}
+
 func openFlags(oflags, fdflags uint16) (openFlags int, isDir bool) {
 	isDir = oflags&O_DIRECTORY != 0
 	if oflags&O_TRUNC != 0 {
-		openFlags = os.O_RDWR | os.O_TRUNC
+		openFlags |= os.O_RDWR | os.O_TRUNC
 	}
 	if oflags&O_CREAT != 0 {
-		openFlags = os.O_RDWR | os.O_CREATE
+		openFlags |= os.O_RDWR | os.O_CREATE
 	}
 	if fdflags&FD_APPEND != 0 {
-		openFlags = os.O_RDWR | os.O_APPEND
+		openFlags |= os.O_RDWR | os.O_APPEND
 	}
 	if openFlags == 0 {
 		openFlags = os.O_RDONLY
@@ -1434,7 +2049,7 @@ func pathRemoveDirectoryFn(_ context.Context, mod api.Module, params []uint64) E
 	}
 
 	if err := fsc.FS().Rmdir(pathName); err != nil {
-		return ToErrno(err)
+		return ToErrno(fsc.MapError(err))
 	}
 
 	return ErrnoSuccess
@@ -1459,6 +2074,9 @@ func pathRemoveDirectoryFn(_ context.Context, mod api.Module, params []uint64) E
 //   - ErrnoNoent: `old_path` does not exist.
 //   - ErrnoNotdir: `old` is a directory and `new` exists, but is a file.
 //   - ErrnoIsdir: `old` is a file and `new` exists, but is a directory.
+//   - ErrnoNotempty: `old` is a directory and `new` exists, but is a
+//     non-empty directory.
+//   - ErrnoInval: `new` is a descendant of `old`, e.g. "a" to "a/b".
 //
 // # Notes
 //   - This is similar to unlinkat in POSIX.
@@ -1492,13 +2110,27 @@ func pathRenameFn(_ context.Context, mod api.Module, params []uint64) Errno {
 		return errno
 	}
 
+	// A directory can't be moved into itself. This is checked before the
+	// file system, which might otherwise fail for a different reason.
+	if isDescendant(oldPathName, newPathName) {
+		return ErrnoInval
+	}
+
 	if err := fsc.FS().Rename(oldPathName, newPathName); err != nil {
-		return ToErrno(err)
+		return ToErrno(fsc.MapError(err))
 	}
 
 	return ErrnoSuccess
 }
 
+// isDescendant returns true if the cleaned path newPath is under oldPath.
+func isDescendant(oldPath, newPath string) bool {
+	if oldPath == "." {
+		return newPath != "."
+	}
+	return strings.HasPrefix(newPath, oldPath+"/")
+}
+
 // pathSymlink is the WASI function named PathSymlinkName which creates a
 // symbolic link.
 //
@@ -1528,6 +2160,8 @@ var pathSymlink = stubFunction(
 // # Notes
 //   - This is similar to unlinkat without AT_REMOVEDIR in POSIX.
 //     See https://linux.die.net/man/2/unlinkat
+//   - POSIX allows EPERM when `path` is a directory, and some platforms
+//     return it. This consistently returns ErrnoIsdir instead, as WASI does.
 //
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-path_unlink_filefd-fd-path-string---errno
 var pathUnlinkFile = newHostFunc(
@@ -1536,6 +2170,12 @@ var pathUnlinkFile = newHostFunc(
 	"fd", "path", "path_len",
 )
 
+// isDir returns true if pathName is a directory in the file system.
+func isDir(fsys syscallfs.FS, pathName string) bool {
+	stat, err := syscallfs.StatPath(fsys, pathName)
+	return err == nil && stat.IsDir()
+}
+
 func pathUnlinkFileFn(_ context.Context, mod api.Module, params []uint64) Errno {
 	fsc := mod.(*wasm.CallContext).Sys.FS()
 
@@ -1549,7 +2189,10 @@ func pathUnlinkFileFn(_ context.Context, mod api.Module, params []uint64) Errno
 	}
 
 	if err := fsc.FS().Unlink(pathName); err != nil {
-		return ToErrno(err)
+		if errors.Is(err, syscall.EPERM) && isDir(fsc.FS(), pathName) {
+			return ErrnoIsdir
+		}
+		return ToErrno(fsc.MapError(err))
 	}
 
 	return ErrnoSuccess
@@ -3,6 +3,8 @@ package wasi_snapshot_preview1_test
 import (
 	"bytes"
 	_ "embed"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -10,12 +12,18 @@ import (
 	"os"
 	"path"
 	"runtime"
+	"sort"
+	"strings"
+	"syscall"
 	"testing"
+	gofstest "testing/fstest"
+	"time"
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/internal/fstest"
 	"github.com/tetratelabs/wazero/internal/leb128"
+	"github.com/tetratelabs/wazero/internal/platform"
 	"github.com/tetratelabs/wazero/internal/sys"
 	"github.com/tetratelabs/wazero/internal/syscallfs"
 	"github.com/tetratelabs/wazero/internal/testing/require"
@@ -81,13 +89,89 @@ func Test_fdClose(t *testing.T) {
 	})
 }
 
-// Test_fdDatasync only tests it is stubbed for GrainLang per #271
+// Test_fdClose_twice ensures closing the same FD twice returns EBADF the
+// second time, without closing the underlying *os.File again.
+func Test_fdClose_twice(t *testing.T) {
+	mod, fd, log, r := requireOpenFile(t, t.TempDir(), "file", []byte("wazero"), false)
+	defer r.Close(testCtx)
+
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+	f, ok := fsc.LookupFile(fd)
+	require.True(t, ok)
+	osFile, ok := f.File.(*os.File)
+	require.True(t, ok)
+
+	requireErrno(t, ErrnoSuccess, mod, FdCloseName, uint64(fd))
+	requireErrno(t, ErrnoBadf, mod, FdCloseName, uint64(fd))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_close(fd=4)
+<== errno=ESUCCESS
+==> wasi_snapshot_preview1.fd_close(fd=4)
+<== errno=EBADF
+`, "\n"+log.String())
+
+	// The file was closed exactly once, by the first call.
+	require.ErrorIs(t, osFile.Close(), os.ErrClosed)
+}
+
 func Test_fdDatasync(t *testing.T) {
-	log := requireErrnoNosys(t, FdDatasyncName, 0)
+	tmpDir := t.TempDir() // open before loop to ensure no locking problems.
+
+	tests := []struct {
+		name          string
+		fd            uint32
+		expectedErrno Errno
+		expectedLog   string
+	}{
+		{
+			name:          "file",
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_datasync(fd=4)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "invalid fd",
+			fd:            42, // arbitrary invalid fd
+			expectedErrno: ErrnoBadf,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_datasync(fd=42)
+<== errno=EBADF
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			mod, fd, log, r := requireOpenFile(t, tmpDir, tc.name, []byte("wazero"), false)
+			defer r.Close(testCtx)
+
+			if tc.fd != 0 {
+				fd = tc.fd
+			}
+
+			requireErrno(t, tc.expectedErrno, mod, FdDatasyncName, uint64(fd))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+		})
+	}
+}
+
+// Test_fdDatasync_noSync ensures files without a Sync method, such as those
+// in an fs.FS, succeed as there is nothing to synchronize.
+func Test_fdDatasync_noSync(t *testing.T) {
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fstest.FS))
+	defer r.Close(testCtx)
+
+	fd := requireOpenFD(t, mod, "animals.txt")
+
+	requireErrno(t, ErrnoSuccess, mod, FdDatasyncName, uint64(fd))
 	require.Equal(t, `
---> wasi_snapshot_preview1.fd_datasync(fd=0)
-<-- errno=ENOSYS
-`, log)
+==> wasi_snapshot_preview1.fd_datasync(fd=4)
+<== errno=ESUCCESS
+`, "\n"+log.String())
 }
 
 func Test_fdFdstatGet(t *testing.T) {
@@ -131,13 +215,13 @@ func Test_fdFdstatGet(t *testing.T) {
 			fd:   sys.FdStdout,
 			expectedMemory: []byte{
 				1, 0, // fs_filetype
-				1, 0, 0, 0, 0, 0, // fs_flags
+				0, 0, 0, 0, 0, 0, // fs_flags
 				0, 0, 0, 0, 0, 0, 0, 0, // fs_rights_base
 				0, 0, 0, 0, 0, 0, 0, 0, // fs_rights_inheriting
 			},
 			expectedLog: `
 ==> wasi_snapshot_preview1.fd_fdstat_get(fd=1)
-<== (stat={filetype=BLOCK_DEVICE,fdflags=APPEND,fs_rights_base=,fs_rights_inheriting=},errno=ESUCCESS)
+<== (stat={filetype=BLOCK_DEVICE,fdflags=,fs_rights_base=,fs_rights_inheriting=},errno=ESUCCESS)
 `,
 		},
 		{
@@ -145,13 +229,13 @@ func Test_fdFdstatGet(t *testing.T) {
 			fd:   sys.FdStderr,
 			expectedMemory: []byte{
 				1, 0, // fs_filetype
-				1, 0, 0, 0, 0, 0, // fs_flags
+				0, 0, 0, 0, 0, 0, // fs_flags
 				0, 0, 0, 0, 0, 0, 0, 0, // fs_rights_base
 				0, 0, 0, 0, 0, 0, 0, 0, // fs_rights_inheriting
 			},
 			expectedLog: `
 ==> wasi_snapshot_preview1.fd_fdstat_get(fd=2)
-<== (stat={filetype=BLOCK_DEVICE,fdflags=APPEND,fs_rights_base=,fs_rights_inheriting=},errno=ESUCCESS)
+<== (stat={filetype=BLOCK_DEVICE,fdflags=,fs_rights_base=,fs_rights_inheriting=},errno=ESUCCESS)
 `,
 		},
 		{
@@ -160,12 +244,12 @@ func Test_fdFdstatGet(t *testing.T) {
 			expectedMemory: []byte{
 				3, 0, // fs_filetype
 				0, 0, 0, 0, 0, 0, // fs_flags
-				0, 0, 0, 0, 0, 0, 0, 0, // fs_rights_base
-				0, 0, 0, 0, 0, 0, 0, 0, // fs_rights_inheriting
+				0x00, 0x60, 0x24, 0x00, 0x00, 0x00, 0x00, 0x00, // fs_rights_base
+				0x26, 0x60, 0x24, 0x00, 0x00, 0x00, 0x00, 0x00, // fs_rights_inheriting
 			},
 			expectedLog: `
 ==> wasi_snapshot_preview1.fd_fdstat_get(fd=3)
-<== (stat={filetype=DIRECTORY,fdflags=,fs_rights_base=,fs_rights_inheriting=},errno=ESUCCESS)
+<== (stat={filetype=DIRECTORY,fdflags=,fs_rights_base=PATH_OPEN|FD_READDIR|PATH_FILESTAT_GET|FD_FILESTAT_GET,fs_rights_inheriting=FD_READ|FD_SEEK|FD_TELL|PATH_OPEN|FD_READDIR|PATH_FILESTAT_GET|FD_FILESTAT_GET},errno=ESUCCESS)
 `,
 		},
 		{
@@ -188,12 +272,12 @@ func Test_fdFdstatGet(t *testing.T) {
 			expectedMemory: []byte{
 				3, 0, // fs_filetype
 				0, 0, 0, 0, 0, 0, // fs_flags
-				0, 0, 0, 0, 0, 0, 0, 0, // fs_rights_base
-				0, 0, 0, 0, 0, 0, 0, 0, // fs_rights_inheriting
+				0x00, 0x60, 0x24, 0x00, 0x00, 0x00, 0x00, 0x00, // fs_rights_base
+				0x26, 0x60, 0x24, 0x00, 0x00, 0x00, 0x00, 0x00, // fs_rights_inheriting
 			},
 			expectedLog: `
 ==> wasi_snapshot_preview1.fd_fdstat_get(fd=5)
-<== (stat={filetype=DIRECTORY,fdflags=,fs_rights_base=,fs_rights_inheriting=},errno=ESUCCESS)
+<== (stat={filetype=DIRECTORY,fdflags=,fs_rights_base=PATH_OPEN|FD_READDIR|PATH_FILESTAT_GET|FD_FILESTAT_GET,fs_rights_inheriting=FD_READ|FD_SEEK|FD_TELL|PATH_OPEN|FD_READDIR|PATH_FILESTAT_GET|FD_FILESTAT_GET},errno=ESUCCESS)
 `,
 		},
 		{
@@ -235,13 +319,280 @@ func Test_fdFdstatGet(t *testing.T) {
 	}
 }
 
-// Test_fdFdstatSetFlags only tests it is stubbed for GrainLang per #271
-func Test_fdFdstatSetFlags(t *testing.T) {
-	log := requireErrnoNosys(t, FdFdstatSetFlagsName, 0, 0)
+// Test_fdFdstatGet_preopen ensures the rights of a pre-open directory depend
+// on whether its file system is writable.
+func Test_fdFdstatGet_preopen(t *testing.T) {
+	dirFS, err := syscallfs.NewDirFS(t.TempDir())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		fs             fs.FS
+		expectedMemory []byte
+		expectedLog    string
+	}{
+		{
+			name: "syscallfs.DirFS",
+			fs:   dirFS,
+			expectedMemory: []byte{
+				3, 0, // fs_filetype
+				0, 0, 0, 0, 0, 0, // fs_flags
+				0x00, 0x66, 0x3f, 0x06, 0x00, 0x00, 0x00, 0x00, // fs_rights_base
+				0x66, 0x66, 0x3f, 0x06, 0x00, 0x00, 0x00, 0x00, // fs_rights_inheriting
+			},
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_fdstat_get(fd=3)
+<== (stat={filetype=DIRECTORY,fdflags=,fs_rights_base=PATH_CREATE_DIRECTORY|PATH_CREATE_FILE|PATH_OPEN|FD_READDIR|PATH_RENAME_SOURCE|PATH_RENAME_TARGET|PATH_FILESTAT_GET|PATH_FILESTAT_SET_SIZE|PATH_FILESTAT_SET_TIMES|FD_FILESTAT_GET|PATH_REMOVE_DIRECTORY|PATH_UNLINK_FILE,fs_rights_inheriting=FD_READ|FD_SEEK|FD_TELL|FD_WRITE|PATH_CREATE_DIRECTORY|PATH_CREATE_FILE|PATH_OPEN|FD_READDIR|PATH_RENAME_SOURCE|PATH_RENAME_TARGET|PATH_FILESTAT_GET|PATH_FILESTAT_SET_SIZE|PATH_FILESTAT_SET_TIMES|FD_FILESTAT_GET|PATH_REMOVE_DIRECTORY|PATH_UNLINK_FILE},errno=ESUCCESS)
+`,
+		},
+		{
+			name: "syscallfs.ReadFS",
+			fs:   syscallfs.NewReadFS(dirFS),
+			expectedMemory: []byte{
+				3, 0, // fs_filetype
+				0, 0, 0, 0, 0, 0, // fs_flags
+				0x00, 0x60, 0x24, 0x00, 0x00, 0x00, 0x00, 0x00, // fs_rights_base
+				0x26, 0x60, 0x24, 0x00, 0x00, 0x00, 0x00, 0x00, // fs_rights_inheriting
+			},
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_fdstat_get(fd=3)
+<== (stat={filetype=DIRECTORY,fdflags=,fs_rights_base=PATH_OPEN|FD_READDIR|PATH_FILESTAT_GET|FD_FILESTAT_GET,fs_rights_inheriting=FD_READ|FD_SEEK|FD_TELL|PATH_OPEN|FD_READDIR|PATH_FILESTAT_GET|FD_FILESTAT_GET},errno=ESUCCESS)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(tc.fs))
+			defer r.Close(testCtx)
+
+			maskMemory(t, mod, len(tc.expectedMemory))
+
+			requireErrno(t, ErrnoSuccess, mod, FdFdstatGetName, uint64(sys.FdPreopen), uint64(0))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+
+			actual, ok := mod.Memory().Read(0, uint32(len(tc.expectedMemory)))
+			require.True(t, ok)
+			require.Equal(t, tc.expectedMemory, actual)
+		})
+	}
+}
+
+// Test_fdFdstatGet_subdir ensures a nested subdirectory opened with
+// path_open reports DIRECTORY and the rights of the pre-open, unlike a file.
+func Test_fdFdstatGet_subdir(t *testing.T) {
+	tmpDir := t.TempDir()
+	mkdir(t, tmpDir, "a")
+	mkdir(t, tmpDir, "a/b")
+	writeFile(t, tmpDir, "a/b/file", []byte("wazero"))
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(dirFS))
+	defer r.Close(testCtx)
+
+	pathOpen := func(dirFD uint32, pathName string, oflags uint16) uint32 {
+		name, resultOpenedFd := uint32(16), uint32(8)
+		require.True(t, mod.Memory().WriteString(name, pathName))
+		requireErrno(t, ErrnoSuccess, mod, PathOpenName, uint64(dirFD), 0, uint64(name),
+			uint64(len(pathName)), uint64(oflags), 0, 0, 0, uint64(resultOpenedFd))
+		fd, ok := mod.Memory().ReadUint32Le(resultOpenedFd)
+		require.True(t, ok)
+		return fd
+	}
+
+	// Open "a" from the pre-open, then "b" from "a".
+	aFD := pathOpen(sys.FdPreopen, "a", O_DIRECTORY)
+	bFD := pathOpen(aFD, "b", O_DIRECTORY)
+	fileFD := pathOpen(bFD, "file", 0)
+	log.Reset()
+
+	requireErrno(t, ErrnoSuccess, mod, FdFdstatGetName, uint64(bFD), 0)
+	requireErrno(t, ErrnoSuccess, mod, FdFdstatGetName, uint64(fileFD), 0)
 	require.Equal(t, `
---> wasi_snapshot_preview1.fd_fdstat_set_flags(fd=0,flags=0)
-<-- errno=ENOSYS
-`, log)
+==> wasi_snapshot_preview1.fd_fdstat_get(fd=5)
+<== (stat={filetype=DIRECTORY,fdflags=,fs_rights_base=PATH_CREATE_DIRECTORY|PATH_CREATE_FILE|PATH_OPEN|FD_READDIR|PATH_RENAME_SOURCE|PATH_RENAME_TARGET|PATH_FILESTAT_GET|PATH_FILESTAT_SET_SIZE|PATH_FILESTAT_SET_TIMES|FD_FILESTAT_GET|PATH_REMOVE_DIRECTORY|PATH_UNLINK_FILE,fs_rights_inheriting=FD_READ|FD_SEEK|FD_TELL|FD_WRITE|PATH_CREATE_DIRECTORY|PATH_CREATE_FILE|PATH_OPEN|FD_READDIR|PATH_RENAME_SOURCE|PATH_RENAME_TARGET|PATH_FILESTAT_GET|PATH_FILESTAT_SET_SIZE|PATH_FILESTAT_SET_TIMES|FD_FILESTAT_GET|PATH_REMOVE_DIRECTORY|PATH_UNLINK_FILE},errno=ESUCCESS)
+==> wasi_snapshot_preview1.fd_fdstat_get(fd=6)
+<== (stat={filetype=REGULAR_FILE,fdflags=,fs_rights_base=,fs_rights_inheriting=},errno=ESUCCESS)
+`, "\n"+log.String())
+}
+
+// ttyReader is a stdin which reports itself as a terminal, such as the
+// pseudo-terminal of a host shell.
+type ttyReader struct{ io.Reader }
+
+func (ttyReader) Stat() (fs.FileInfo, error) {
+	return gofstest.MapFS{"tty": &gofstest.MapFile{Mode: fs.ModeCharDevice}}.Stat("tty")
+}
+
+// Test_fdFdstatGet_stdinTTY ensures stdin reports CHARACTER_DEVICE when it is
+// a terminal, with the right to read, but not seek.
+func Test_fdFdstatGet_stdinTTY(t *testing.T) {
+	stdin := ttyReader{strings.NewReader("wazero")}
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithStdin(stdin))
+	defer r.Close(testCtx)
+
+	expectedMemory := []byte{
+		2, 0, // fs_filetype
+		0, 0, 0, 0, 0, 0, // fs_flags
+		2, 0, 0, 0, 0, 0, 0, 0, // fs_rights_base
+		0, 0, 0, 0, 0, 0, 0, 0, // fs_rights_inheriting
+	}
+	maskMemory(t, mod, len(expectedMemory))
+
+	requireErrno(t, ErrnoSuccess, mod, FdFdstatGetName, uint64(sys.FdStdin), uint64(0))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_fdstat_get(fd=0)
+<== (stat={filetype=CHARACTER_DEVICE,fdflags=,fs_rights_base=FD_READ,fs_rights_inheriting=},errno=ESUCCESS)
+`, "\n"+log.String())
+
+	actual, ok := mod.Memory().Read(0, uint32(len(expectedMemory)))
+	require.True(t, ok)
+	require.Equal(t, expectedMemory, actual)
+}
+
+// Test_fdFdstatGet_socket ensures a socket-backed file reports SOCKET_STREAM
+// and rights to read and write, but not seek.
+func Test_fdFdstatGet_socket(t *testing.T) {
+	socketFS := gofstest.MapFS{"sock": &gofstest.MapFile{Mode: fs.ModeSocket}}
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(socketFS))
+	defer r.Close(testCtx)
+
+	fd := requireOpenFD(t, mod, "sock")
+
+	expectedMemory := []byte{
+		6, 0, // fs_filetype
+		0, 0, 0, 0, 0, 0, // fs_flags
+		66, 0, 0, 0, 0, 0, 0, 0, // fs_rights_base
+		0, 0, 0, 0, 0, 0, 0, 0, // fs_rights_inheriting
+	}
+	maskMemory(t, mod, len(expectedMemory))
+
+	requireErrno(t, ErrnoSuccess, mod, FdFdstatGetName, uint64(fd), uint64(0))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_fdstat_get(fd=4)
+<== (stat={filetype=SOCKET_STREAM,fdflags=,fs_rights_base=FD_READ|FD_WRITE,fs_rights_inheriting=},errno=ESUCCESS)
+`, "\n"+log.String())
+
+	actual, ok := mod.Memory().Read(0, uint32(len(expectedMemory)))
+	require.True(t, ok)
+	require.Equal(t, expectedMemory, actual)
+}
+
+func Test_fdFdstatSetFlags(t *testing.T) {
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fstest.FS))
+	defer r.Close(testCtx)
+
+	fd := requireOpenFD(t, mod, "animals.txt")
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+	f, ok := fsc.LookupFile(fd)
+	require.True(t, ok)
+
+	tests := []struct {
+		name          string
+		fd            uint32
+		flags         uint32
+		expectedErrno Errno
+		expectedFlags uint16
+		expectedLog   string
+	}{
+		{
+			name:          "APPEND",
+			fd:            fd,
+			flags:         uint32(FD_APPEND),
+			expectedErrno: ErrnoSuccess,
+			expectedFlags: FD_APPEND,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_fdstat_set_flags(fd=4,flags=1)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "replaces flags",
+			fd:            fd,
+			flags:         uint32(FD_NONBLOCK),
+			expectedErrno: ErrnoSuccess,
+			expectedFlags: FD_NONBLOCK, // FD_APPEND was cleared
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_fdstat_set_flags(fd=4,flags=4)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "clears flags",
+			fd:            fd,
+			flags:         0,
+			expectedErrno: ErrnoSuccess,
+			expectedFlags: 0,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_fdstat_set_flags(fd=4,flags=0)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "APPEND again",
+			fd:            fd,
+			flags:         uint32(FD_APPEND),
+			expectedErrno: ErrnoSuccess,
+			expectedFlags: FD_APPEND,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_fdstat_set_flags(fd=4,flags=1)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "undefined bit",
+			fd:            fd,
+			flags:         uint32(FD_SYNC) << 1,
+			expectedErrno: ErrnoInval,
+			expectedFlags: FD_APPEND, // unchanged
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_fdstat_set_flags(fd=4,flags=32)
+<== errno=EINVAL
+`,
+		},
+		{
+			name:          "bit past uint16",
+			fd:            fd,
+			flags:         1 << 16,
+			expectedErrno: ErrnoInval,
+			expectedFlags: FD_APPEND, // unchanged
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_fdstat_set_flags(fd=4,flags=65536)
+<== errno=EINVAL
+`,
+		},
+		{
+			name:          "invalid fd",
+			fd:            42, // arbitrary invalid fd
+			flags:         uint32(FD_APPEND),
+			expectedErrno: ErrnoBadf,
+			expectedFlags: FD_APPEND, // unchanged
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_fdstat_set_flags(fd=42,flags=1)
+<== errno=EBADF
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			defer log.Reset()
+
+			requireErrno(t, tc.expectedErrno, mod, FdFdstatSetFlagsName, uint64(tc.fd), uint64(tc.flags))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+			require.Equal(t, tc.expectedFlags, f.FdFlags)
+
+			// Ensure the guest sees the flags via fd_fdstat_get.
+			requireErrno(t, ErrnoSuccess, mod, FdFdstatGetName, uint64(fd), 0)
+			fdflags, ok := mod.Memory().ReadUint16Le(2)
+			require.True(t, ok)
+			require.Equal(t, tc.expectedFlags, fdflags)
+		})
+	}
 }
 
 // Test_fdFdstatSetRights only tests it is stubbed for GrainLang per #271
@@ -255,7 +606,10 @@ func Test_fdFdstatSetRights(t *testing.T) {
 
 func Test_fdFilestatGet(t *testing.T) {
 	file, dir := "animals.txt", "sub"
-	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fstest.FS))
+	// Fix the walltime, as it is the mtim of stdio.
+	walltime := func() (sec int64, nsec int32) { return 1640995200, 0 } // 2022-01-01T00:00:00Z
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fstest.FS).
+		WithWalltime(walltime, 1))
 	defer r.Close(testCtx)
 	memorySize := mod.Memory().Size()
 
@@ -286,12 +640,12 @@ func Test_fdFilestatGet(t *testing.T) {
 				1, 0, 0, 0, 0, 0, 0, 0, // nlink
 				0, 0, 0, 0, 0, 0, 0, 0, // size
 				0, 0, 0, 0, 0, 0, 0, 0, // atim
-				0, 0, 0, 0, 0, 0, 0, 0, // mtim
+				0x00, 0x00, 0x1f, 0xa6, 0x70, 0xfc, 0xc5, 0x16, // mtim
 				0, 0, 0, 0, 0, 0, 0, 0, // ctim
 			},
 			expectedLog: `
 ==> wasi_snapshot_preview1.fd_filestat_get(fd=0)
-<== (filestat={filetype=BLOCK_DEVICE,size=0,mtim=0},errno=ESUCCESS)
+<== (filestat={filetype=BLOCK_DEVICE,size=0,mtim=1640995200000000000},errno=ESUCCESS)
 `,
 		},
 		{
@@ -305,12 +659,12 @@ func Test_fdFilestatGet(t *testing.T) {
 				1, 0, 0, 0, 0, 0, 0, 0, // nlink
 				0, 0, 0, 0, 0, 0, 0, 0, // size
 				0, 0, 0, 0, 0, 0, 0, 0, // atim
-				0, 0, 0, 0, 0, 0, 0, 0, // mtim
+				0x00, 0x00, 0x1f, 0xa6, 0x70, 0xfc, 0xc5, 0x16, // mtim
 				0, 0, 0, 0, 0, 0, 0, 0, // ctim
 			},
 			expectedLog: `
 ==> wasi_snapshot_preview1.fd_filestat_get(fd=1)
-<== (filestat={filetype=BLOCK_DEVICE,size=0,mtim=0},errno=ESUCCESS)
+<== (filestat={filetype=BLOCK_DEVICE,size=0,mtim=1640995200000000000},errno=ESUCCESS)
 `,
 		},
 		{
@@ -324,12 +678,12 @@ func Test_fdFilestatGet(t *testing.T) {
 				1, 0, 0, 0, 0, 0, 0, 0, // nlink
 				0, 0, 0, 0, 0, 0, 0, 0, // size
 				0, 0, 0, 0, 0, 0, 0, 0, // atim
-				0, 0, 0, 0, 0, 0, 0, 0, // mtim
+				0x00, 0x00, 0x1f, 0xa6, 0x70, 0xfc, 0xc5, 0x16, // mtim
 				0, 0, 0, 0, 0, 0, 0, 0, // ctim
 			},
 			expectedLog: `
 ==> wasi_snapshot_preview1.fd_filestat_get(fd=2)
-<== (filestat={filetype=BLOCK_DEVICE,size=0,mtim=0},errno=ESUCCESS)
+<== (filestat={filetype=BLOCK_DEVICE,size=0,mtim=1640995200000000000},errno=ESUCCESS)
 `,
 		},
 		{
@@ -337,7 +691,7 @@ func Test_fdFilestatGet(t *testing.T) {
 			fd:   sys.FdPreopen,
 			expectedMemory: []byte{
 				0, 0, 0, 0, 0, 0, 0, 0, // dev
-				0, 0, 0, 0, 0, 0, 0, 0, // ino
+				0x25, 0x23, 0x22, 0x84, 0xe4, 0x9c, 0xf2, 0xcb, // ino
 				3, 0, 0, 0, 0, 0, 0, 0, // filetype + padding
 				1, 0, 0, 0, 0, 0, 0, 0, // nlink
 				0, 0, 0, 0, 0, 0, 0, 0, // size
@@ -355,7 +709,7 @@ func Test_fdFilestatGet(t *testing.T) {
 			fd:   fileFD,
 			expectedMemory: []byte{
 				0, 0, 0, 0, 0, 0, 0, 0, // dev
-				0, 0, 0, 0, 0, 0, 0, 0, // ino
+				0xcc, 0xdd, 0x00, 0xf5, 0xa1, 0x2c, 0x99, 0x97, // ino
 				4, 0, 0, 0, 0, 0, 0, 0, // filetype + padding
 				1, 0, 0, 0, 0, 0, 0, 0, // nlink
 				30, 0, 0, 0, 0, 0, 0, 0, // size
@@ -373,7 +727,7 @@ func Test_fdFilestatGet(t *testing.T) {
 			fd:   dirFD,
 			expectedMemory: []byte{
 				0, 0, 0, 0, 0, 0, 0, 0, // dev
-				0, 0, 0, 0, 0, 0, 0, 0, // ino
+				0xf5, 0xc2, 0x0f, 0x5d, 0x19, 0x9d, 0x71, 0x82, // ino
 				3, 0, 0, 0, 0, 0, 0, 0, // filetype + padding
 				1, 0, 0, 0, 0, 0, 0, 0, // nlink
 				0, 0, 0, 0, 0, 0, 0, 0, // size
@@ -425,24 +779,324 @@ func Test_fdFilestatGet(t *testing.T) {
 	}
 }
 
-// Test_fdFilestatSetSize only tests it is stubbed for GrainLang per #271
-func Test_fdFilestatSetSize(t *testing.T) {
-	log := requireErrnoNosys(t, FdFilestatSetSizeName, 0, 0)
+// Test_fdFilestatGet_stdinPipe ensures a pipe-backed stdin reports an unknown
+// filetype, and the configured walltime as its mtim.
+func Test_fdFilestatGet_stdinPipe(t *testing.T) {
+	stdin, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer stdin.Close()
+	defer w.Close()
+
+	walltime := func() (sec int64, nsec int32) { return 1640995200, 0 } // 2022-01-01T00:00:00Z
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().
+		WithStdin(stdin).WithWalltime(walltime, 1))
+	defer r.Close(testCtx)
+
+	resultFilestat := uint32(0)
+	requireErrno(t, ErrnoSuccess, mod, FdFilestatGetName, uint64(sys.FdStdin), uint64(resultFilestat))
 	require.Equal(t, `
---> wasi_snapshot_preview1.fd_filestat_set_size(fd=0,size=0)
-<-- errno=ENOSYS
-`, log)
+==> wasi_snapshot_preview1.fd_filestat_get(fd=0)
+<== (filestat={filetype=UNKNOWN,size=0,mtim=1640995200000000000},errno=ESUCCESS)
+`, "\n"+log.String())
+
+	buf, ok := mod.Memory().Read(resultFilestat, 64)
+	require.True(t, ok)
+	// WASI has no FIFO filetype.
+	require.Equal(t, uint8(FILETYPE_UNKNOWN), buf[16])
+	require.Equal(t, uint64(1640995200000000000), binary.LittleEndian.Uint64(buf[48:]))
 }
 
-// Test_fdFilestatSetTimes only tests it is stubbed for GrainLang per #271
-func Test_fdFilestatSetTimes(t *testing.T) {
-	log := requireErrnoNosys(t, FdFilestatSetTimesName, 0, 0, 0, 0)
-	require.Equal(t, `
---> wasi_snapshot_preview1.fd_filestat_set_times(fd=0,atim=0,mtim=0,fst_flags=0)
+// Test_fdFilestatGet_notStdio ensures the mtim of a regular file isn't
+// replaced by the walltime, even on a stdio file descriptor.
+func Test_fdFilestatGet_notStdio(t *testing.T) {
+	tmpDir := t.TempDir()
+	mtim := time.Unix(567, 8*1e6)
+
+	t.Run("renumbered to stdout", func(t *testing.T) {
+		mod, fd, log, r := requireOpenFile(t, tmpDir, "renumbered", []byte("wazero"), false)
+		defer r.Close(testCtx)
+		require.NoError(t, os.Chtimes(path.Join(tmpDir, "renumbered"), mtim, mtim))
+
+		requireErrno(t, ErrnoSuccess, mod, FdRenumberName, uint64(fd), uint64(sys.FdStdout))
+		log.Reset()
+
+		resultFilestat := uint32(0)
+		requireErrno(t, ErrnoSuccess, mod, FdFilestatGetName, uint64(sys.FdStdout), uint64(resultFilestat))
+		require.Equal(t, `
+==> wasi_snapshot_preview1.fd_filestat_get(fd=1)
+<== (filestat={filetype=REGULAR_FILE,size=6,mtim=567008000000},errno=ESUCCESS)
+`, "\n"+log.String())
+	})
+
+	t.Run("stdout redirected to a file", func(t *testing.T) {
+		stdout, err := os.Create(path.Join(tmpDir, "stdout"))
+		require.NoError(t, err)
+		defer stdout.Close()
+		require.NoError(t, os.Chtimes(stdout.Name(), mtim, mtim))
+
+		mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithStdout(stdout))
+		defer r.Close(testCtx)
+
+		resultFilestat := uint32(0)
+		requireErrno(t, ErrnoSuccess, mod, FdFilestatGetName, uint64(sys.FdStdout), uint64(resultFilestat))
+		require.Equal(t, `
+==> wasi_snapshot_preview1.fd_filestat_get(fd=1)
+<== (filestat={filetype=REGULAR_FILE,size=0,mtim=567008000000},errno=ESUCCESS)
+`, "\n"+log.String())
+	})
+}
+
+// Test_fdFilestatSetSize only tests it is stubbed for GrainLang per #271
+func Test_fdFilestatSetSize(t *testing.T) {
+	log := requireErrnoNosys(t, FdFilestatSetSizeName, 0, 0)
+	require.Equal(t, `
+--> wasi_snapshot_preview1.fd_filestat_set_size(fd=0,size=0)
 <-- errno=ENOSYS
 `, log)
 }
 
+// The below times are the same as the gojs writefs testdata, which expects
+// "times: 123 4000000 567 8000000" after JavaScript truncates to milliseconds.
+// WASI timestamps are nanoseconds, so these round-trip exactly.
+var (
+	testAtim    = time.Unix(123, 4*1e6).UnixNano()
+	testMtim    = time.Unix(567, 8*1e6).UnixNano()
+	initialAtim = time.Unix(1, 0).UnixNano()
+	initialMtim = time.Unix(2, 0).UnixNano()
+)
+
+func Test_fdFilestatSetTimes(t *testing.T) {
+	tmpDir := t.TempDir() // open before loop to ensure no locking problems.
+
+	tests := []struct {
+		name                       string
+		fd                         uint32
+		atim, mtim                 int64
+		fstFlags                   uint16
+		expectedAtim, expectedMtim int64
+		expectedErrno              Errno
+		expectedLog                string
+	}{
+		{
+			name:          "atim and mtim",
+			atim:          testAtim,
+			mtim:          testMtim,
+			fstFlags:      FSTFLAGS_ATIM | FSTFLAGS_MTIM,
+			expectedAtim:  testAtim,
+			expectedMtim:  testMtim,
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=4,atim=123004000000,mtim=567008000000,fst_flags=5)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "atim preserves mtim",
+			atim:          testAtim,
+			mtim:          testMtim,
+			fstFlags:      FSTFLAGS_ATIM,
+			expectedAtim:  testAtim,
+			expectedMtim:  initialMtim,
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=4,atim=123004000000,mtim=567008000000,fst_flags=1)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "mtim preserves atim",
+			atim:          testAtim,
+			mtim:          testMtim,
+			fstFlags:      FSTFLAGS_MTIM,
+			expectedAtim:  initialAtim,
+			expectedMtim:  testMtim,
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=4,atim=123004000000,mtim=567008000000,fst_flags=4)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "atim_now and mtim_now",
+			fstFlags:      FSTFLAGS_ATIM_NOW | FSTFLAGS_MTIM_NOW,
+			expectedAtim:  platform.FakeEpochNanos,
+			expectedMtim:  platform.FakeEpochNanos,
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=4,atim=0,mtim=0,fst_flags=10)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "atim and atim_now",
+			atim:          testAtim,
+			fstFlags:      FSTFLAGS_ATIM | FSTFLAGS_ATIM_NOW,
+			expectedAtim:  initialAtim,
+			expectedMtim:  initialMtim,
+			expectedErrno: ErrnoInval,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=4,atim=123004000000,mtim=0,fst_flags=3)
+<== errno=EINVAL
+`,
+		},
+		{
+			name:          "mtim and mtim_now",
+			mtim:          testMtim,
+			fstFlags:      FSTFLAGS_MTIM | FSTFLAGS_MTIM_NOW,
+			expectedAtim:  initialAtim,
+			expectedMtim:  initialMtim,
+			expectedErrno: ErrnoInval,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=4,atim=0,mtim=567008000000,fst_flags=12)
+<== errno=EINVAL
+`,
+		},
+		{
+			name:          "invalid fd",
+			fd:            42, // arbitrary invalid fd
+			fstFlags:      FSTFLAGS_ATIM_NOW,
+			expectedAtim:  initialAtim,
+			expectedMtim:  initialMtim,
+			expectedErrno: ErrnoBadf,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=42,atim=0,mtim=0,fst_flags=2)
+<== errno=EBADF
+`,
+		},
+		{
+			name:          "undefined bit",
+			fstFlags:      FSTFLAGS_MTIM_NOW << 1,
+			expectedAtim:  initialAtim,
+			expectedMtim:  initialMtim,
+			expectedErrno: ErrnoInval,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=4,atim=0,mtim=0,fst_flags=16)
+<== errno=EINVAL
+`,
+		},
+		{
+			name:          "stdio",
+			fd:            sys.FdStdout,
+			fstFlags:      FSTFLAGS_ATIM_NOW,
+			expectedAtim:  initialAtim,
+			expectedMtim:  initialMtim,
+			expectedErrno: ErrnoNotsup,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=1,atim=0,mtim=0,fst_flags=2)
+<== errno=ENOTSUP
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			mod, fd, log, r := requireOpenFile(t, tmpDir, tc.name, []byte("wazero"), false)
+			defer r.Close(testCtx)
+
+			realPath := path.Join(tmpDir, tc.name)
+			requireChtimes(t, realPath, initialAtim, initialMtim)
+
+			if tc.fd != 0 {
+				fd = tc.fd
+			}
+
+			requireErrno(t, tc.expectedErrno, mod, FdFilestatSetTimesName,
+				uint64(fd), uint64(tc.atim), uint64(tc.mtim), uint64(tc.fstFlags))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+
+			atim, mtim := requireStatTimes(t, realPath)
+			require.Equal(t, tc.expectedAtim, atim)
+			require.Equal(t, tc.expectedMtim, mtim)
+		})
+	}
+}
+
+// Test_fdFilestatSetTimes_renumbered ensures a file renumbered onto a stdio
+// file descriptor can still have its times adjusted.
+func Test_fdFilestatSetTimes_renumbered(t *testing.T) {
+	tmpDir := t.TempDir()
+	mod, fd, log, r := requireOpenFile(t, tmpDir, "file", []byte("wazero"), false)
+	defer r.Close(testCtx)
+
+	requireErrno(t, ErrnoSuccess, mod, FdRenumberName, uint64(fd), uint64(sys.FdStdout))
+	log.Reset()
+
+	requireErrno(t, ErrnoSuccess, mod, FdFilestatSetTimesName,
+		uint64(sys.FdStdout), uint64(testAtim), uint64(testMtim), uint64(FSTFLAGS_ATIM|FSTFLAGS_MTIM))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=1,atim=123004000000,mtim=567008000000,fst_flags=5)
+<== errno=ESUCCESS
+`, "\n"+log.String())
+
+	atim, mtim := requireStatTimes(t, path.Join(tmpDir, "file"))
+	require.Equal(t, testAtim, atim)
+	require.Equal(t, testMtim, mtim)
+}
+
+// Test_fdFilestatSetTimes_buffered ensures buffered writes are flushed before
+// setting times, so that closing the file doesn't overwrite them.
+func Test_fdFilestatSetTimes_buffered(t *testing.T) {
+	tmpDir := t.TempDir()
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(syscallfs.NewBufferedFS(dirFS, 4096)))
+	defer r.Close(testCtx)
+
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+	fd, err := fsc.OpenFile("buffered", os.O_RDWR|os.O_CREATE, 0o600)
+	require.NoError(t, err)
+
+	_, err = sys.WriterForFile(fsc, fd).Write([]byte("wazero"))
+	require.NoError(t, err)
+	require.Zero(t, len(readFile(t, tmpDir, "buffered"))) // still buffered
+
+	requireErrno(t, ErrnoSuccess, mod, FdFilestatSetTimesName,
+		uint64(fd), uint64(testAtim), uint64(testMtim), uint64(FSTFLAGS_ATIM|FSTFLAGS_MTIM))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=4,atim=123004000000,mtim=567008000000,fst_flags=5)
+<== errno=ESUCCESS
+`, "\n"+log.String())
+
+	require.NoError(t, fsc.CloseFile(fd))
+	require.Equal(t, []byte("wazero"), readFile(t, tmpDir, "buffered"))
+	_, mtim := requireStatTimes(t, path.Join(tmpDir, "buffered"))
+	require.Equal(t, testMtim, mtim)
+}
+
+// Test_fdFilestatSetTimes_strictClocks ensures the *_NOW flags are
+// unsupported when the walltime clock is, per RuntimeConfig.WithStrictClocks.
+func Test_fdFilestatSetTimes_strictClocks(t *testing.T) {
+	mod, fd, log, r := requireOpenFile(t, t.TempDir(), "file", []byte("wazero"), false)
+	defer r.Close(testCtx)
+	mod.(*wasm.CallContext).Sys.StrictClocks = true
+
+	requireErrno(t, ErrnoNotsup, mod, FdFilestatSetTimesName,
+		uint64(fd), 0, 0, uint64(FSTFLAGS_ATIM_NOW|FSTFLAGS_MTIM_NOW))
+	// Explicit times don't read the clock.
+	requireErrno(t, ErrnoSuccess, mod, FdFilestatSetTimesName,
+		uint64(fd), uint64(testAtim), uint64(testMtim), uint64(FSTFLAGS_ATIM|FSTFLAGS_MTIM))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=4,atim=0,mtim=0,fst_flags=10)
+<== errno=ENOTSUP
+==> wasi_snapshot_preview1.fd_filestat_set_times(fd=4,atim=123004000000,mtim=567008000000,fst_flags=5)
+<== errno=ESUCCESS
+`, "\n"+log.String())
+}
+
+func requireChtimes(t *testing.T, realPath string, atim, mtim int64) {
+	require.NoError(t, os.Chtimes(realPath, time.Unix(0, atim), time.Unix(0, mtim)))
+}
+
+func requireStatTimes(t *testing.T, realPath string) (atim, mtim int64) {
+	st, err := os.Stat(realPath)
+	require.NoError(t, err)
+	atim, mtim, _ = platform.StatTimes(st)
+	return
+}
+
 func Test_fdPread(t *testing.T) {
 	tmpDir := t.TempDir()
 	mod, fd, log, r := requireOpenFile(t, tmpDir, "test_path", []byte("wazero"), true)
@@ -600,21 +1254,23 @@ func Test_fdPread_Errors(t *testing.T) {
 	}{
 		{
 			name:          "invalid fd",
-			fd:            42,                         // arbitrary invalid fd
+			fd:            42, // arbitrary invalid fd
+			iovsCount:     1,
 			memory:        []byte{'?', '?', '?', '?'}, // pass result.nread validation
 			expectedErrno: ErrnoBadf,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_pread(fd=42,iovs=65532,iovs_len=65532,offset=0)
+==> wasi_snapshot_preview1.fd_pread(fd=42,iovs=65532,iovs_len=1,offset=0)
 <== (nread=,errno=EBADF)
 `,
 		},
 		{
 			name:          "seek past file",
 			fd:            fd,
+			iovsCount:     1,
 			offset:        int64(len(contents) + 1),
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65536,iovs_len=65536,offset=7)
+==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65536,iovs_len=1,offset=7)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -622,10 +1278,11 @@ func Test_fdPread_Errors(t *testing.T) {
 			name:          "out-of-memory reading iovs[0].offset",
 			fd:            fd,
 			iovs:          1,
+			iovsCount:     1,
 			memory:        []byte{'?'},
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65536,iovs_len=65535,offset=0)
+==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65536,iovs_len=1,offset=0)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -639,7 +1296,7 @@ func Test_fdPread_Errors(t *testing.T) {
 			},
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65532,iovs_len=65532,offset=0)
+==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65532,iovs_len=1,offset=0)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -654,7 +1311,7 @@ func Test_fdPread_Errors(t *testing.T) {
 			},
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65528,iovs_len=65528,offset=0)
+==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65528,iovs_len=1,offset=0)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -670,7 +1327,7 @@ func Test_fdPread_Errors(t *testing.T) {
 			},
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65527,iovs_len=65527,offset=0)
+==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65527,iovs_len=1,offset=0)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -687,7 +1344,7 @@ func Test_fdPread_Errors(t *testing.T) {
 			},
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65527,iovs_len=65527,offset=0)
+==> wasi_snapshot_preview1.fd_pread(fd=4,iovs=65527,iovs_len=1,offset=0)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -703,7 +1360,7 @@ func Test_fdPread_Errors(t *testing.T) {
 			memoryWriteOK := mod.Memory().Write(offset, tc.memory)
 			require.True(t, memoryWriteOK)
 
-			requireErrno(t, tc.expectedErrno, mod, FdPreadName, uint64(tc.fd), uint64(tc.iovs+offset), uint64(tc.iovsCount+offset), uint64(tc.offset), uint64(tc.resultNread+offset))
+			requireErrno(t, tc.expectedErrno, mod, FdPreadName, uint64(tc.fd), uint64(tc.iovs+offset), uint64(tc.iovsCount), uint64(tc.offset), uint64(tc.resultNread+offset))
 			require.Equal(t, tc.expectedLog, "\n"+log.String())
 		})
 	}
@@ -766,10 +1423,10 @@ func Test_fdPrestatGet_Errors(t *testing.T) {
 			name:          "not pre-opened FD",
 			fd:            dirFD,
 			resultPrestat: 0, // valid offset
-			expectedErrno: ErrnoInval,
+			expectedErrno: ErrnoBadf,
 			expectedLog: `
 ==> wasi_snapshot_preview1.fd_prestat_get(fd=4)
-<== (prestat=,errno=EINVAL)
+<== (prestat=,errno=EBADF)
 `,
 		},
 		{
@@ -796,6 +1453,42 @@ func Test_fdPrestatGet_Errors(t *testing.T) {
 	}
 }
 
+// Test_fdPrestatGet_scan ensures the pre-open discovery loop of libc, which
+// scans upward from sys.FdPreopen until EBADF, stops after the pre-opens even
+// when regular files are open above them.
+func Test_fdPrestatGet_scan(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFile(t, tmpDir, "file", []byte("wazero"))
+	mod, fd, log, r := requireOpenFile(t, tmpDir, "dir", nil, false)
+	defer r.Close(testCtx)
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+
+	fileFD, err := fsc.OpenFile("file", os.O_RDONLY, 0)
+	require.NoError(t, err)
+	require.Equal(t, fd+1, fileFD)
+
+	var preopens []uint32
+	resultPrestat := uint32(0)
+	for scanFD := sys.FdPreopen; ; scanFD++ {
+		results, err := mod.ExportedFunction(FdPrestatGetName).Call(testCtx, uint64(scanFD), uint64(resultPrestat))
+		require.NoError(t, err)
+		if errno := Errno(results[0]); errno == ErrnoBadf {
+			require.Equal(t, sys.FdPreopen+uint32(len(preopens)), scanFD)
+			break
+		} else {
+			require.Equal(t, ErrnoSuccess, errno)
+		}
+		preopens = append(preopens, scanFD)
+	}
+	require.Equal(t, []uint32{sys.FdPreopen}, preopens)
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_prestat_get(fd=3)
+<== (prestat={pr_name_len=1},errno=ESUCCESS)
+==> wasi_snapshot_preview1.fd_prestat_get(fd=4)
+<== (prestat=,errno=EBADF)
+`, "\n"+log.String())
+}
+
 func Test_fdPrestatDirName(t *testing.T) {
 	testfs, err := syscallfs.NewDirFS(t.TempDir())
 	require.NoError(t, err)
@@ -890,10 +1583,10 @@ func Test_fdPrestatDirName_Errors(t *testing.T) {
 			fd:            dirFD,
 			path:          validAddress,
 			pathLen:       pathLen,
-			expectedErrno: ErrnoInval,
+			expectedErrno: ErrnoBadf,
 			expectedLog: `
 ==> wasi_snapshot_preview1.fd_prestat_dir_name(fd=4)
-<== (path=,errno=EINVAL)
+<== (path=,errno=EBADF)
 `,
 		},
 	}
@@ -960,6 +1653,121 @@ func Test_fdRead(t *testing.T) {
 	require.Equal(t, expectedMemory, actual)
 }
 
+// Test_fdRead_EOF ensures reads at EOF succeed with nread=0, which guests use
+// to stop reading.
+func Test_fdRead_EOF(t *testing.T) {
+	mod, fd, log, r := requireOpenFile(t, t.TempDir(), "test_path", []byte("wazero"), true)
+	defer r.Close(testCtx)
+
+	iovs, resultNread := uint32(0), uint32(8)
+	ok := mod.Memory().Write(iovs, []byte{
+		16, 0, 0, 0, // = iovs[0].offset
+		8, 0, 0, 0, // = iovs[0].length, larger than the file
+	})
+	require.True(t, ok)
+
+	// The first read consumes the entire file.
+	requireErrno(t, ErrnoSuccess, mod, FdReadName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNread))
+	nread, ok := mod.Memory().ReadUint32Le(resultNread)
+	require.True(t, ok)
+	require.Equal(t, uint32(6), nread)
+
+	// Reads at EOF succeed with nread=0, repeatably.
+	for i := 0; i < 2; i++ {
+		requireErrno(t, ErrnoSuccess, mod, FdReadName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNread))
+		nread, ok = mod.Memory().ReadUint32Le(resultNread)
+		require.True(t, ok)
+		require.Zero(t, nread)
+	}
+
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=1)
+<== (nread=6,errno=ESUCCESS)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=1)
+<== (nread=0,errno=ESUCCESS)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=1)
+<== (nread=0,errno=ESUCCESS)
+`, "\n"+log.String())
+}
+
+// Test_fdRead_offset ensures fd_read advances the offset reported by fd_seek,
+// and that the next read continues from it.
+func Test_fdRead_offset(t *testing.T) {
+	mod, fd, log, r := requireOpenFile(t, t.TempDir(), "test_path", []byte("wazero"), true)
+	defer r.Close(testCtx)
+
+	iovs, resultNread, resultOffset := uint32(0), uint32(8), uint32(12)
+	ok := mod.Memory().Write(iovs, []byte{
+		32, 0, 0, 0, // = iovs[0].offset
+		4, 0, 0, 0, // = iovs[0].length
+	})
+	require.True(t, ok)
+
+	requireErrno(t, ErrnoSuccess, mod, FdReadName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNread))
+	buf, ok := mod.Memory().Read(32, 4)
+	require.True(t, ok)
+	require.Equal(t, "waze", string(buf))
+
+	requireErrno(t, ErrnoSuccess, mod, FdSeekName, uint64(fd), uint64(0), uint64(io.SeekCurrent), uint64(resultOffset))
+	offset, ok := mod.Memory().ReadUint64Le(resultOffset)
+	require.True(t, ok)
+	require.Equal(t, uint64(4), offset)
+
+	// The next read continues from the offset.
+	requireErrno(t, ErrnoSuccess, mod, FdReadName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNread))
+	buf, ok = mod.Memory().Read(32, 2)
+	require.True(t, ok)
+	require.Equal(t, "ro", string(buf))
+
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=1)
+<== (nread=4,errno=ESUCCESS)
+==> wasi_snapshot_preview1.fd_seek(fd=4,offset=0,whence=1,result.newoffset=12)
+<== errno=ESUCCESS
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=1)
+<== (nread=2,errno=ESUCCESS)
+`, "\n"+log.String())
+}
+
+// Test_fdRead_rewind ensures a guest re-reads a file from the start after the
+// host rewinds it.
+func Test_fdRead_rewind(t *testing.T) {
+	mod, fd, log, r := requireOpenFile(t, t.TempDir(), "test_path", []byte("wazero"), true)
+	defer r.Close(testCtx)
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+
+	iovs, resultNread := uint32(0), uint32(8)
+	ok := mod.Memory().Write(iovs, []byte{
+		16, 0, 0, 0, // = iovs[0].offset
+		8, 0, 0, 0, // = iovs[0].length, larger than the file
+	})
+	require.True(t, ok)
+
+	read := func() string {
+		requireErrno(t, ErrnoSuccess, mod, FdReadName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNread))
+		nread, ok := mod.Memory().ReadUint32Le(resultNread)
+		require.True(t, ok)
+		buf, ok := mod.Memory().Read(16, nread)
+		require.True(t, ok)
+		return string(buf)
+	}
+
+	require.Equal(t, "wazero", read())
+	require.Equal(t, "", read()) // EOF
+
+	require.NoError(t, fsc.Rewind(fd))
+	require.Equal(t, "wazero", read())
+
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=1)
+<== (nread=6,errno=ESUCCESS)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=1)
+<== (nread=0,errno=ESUCCESS)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=1)
+<== (nread=6,errno=ESUCCESS)
+`, "\n"+log.String())
+}
+
 func Test_fdRead_Errors(t *testing.T) {
 	mod, fd, log, r := requireOpenFile(t, t.TempDir(), "test_path", []byte("wazero"), true)
 	defer r.Close(testCtx)
@@ -973,11 +1781,12 @@ func Test_fdRead_Errors(t *testing.T) {
 	}{
 		{
 			name:          "invalid fd",
-			fd:            42,                         // arbitrary invalid fd
+			fd:            42, // arbitrary invalid fd
+			iovsCount:     1,
 			memory:        []byte{'?', '?', '?', '?'}, // pass result.nread validation
 			expectedErrno: ErrnoBadf,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_read(fd=42,iovs=65532,iovs_len=65532)
+==> wasi_snapshot_preview1.fd_read(fd=42,iovs=65532,iovs_len=1)
 <== (nread=,errno=EBADF)
 `,
 		},
@@ -985,10 +1794,11 @@ func Test_fdRead_Errors(t *testing.T) {
 			name:          "out-of-memory reading iovs[0].offset",
 			fd:            fd,
 			iovs:          1,
+			iovsCount:     1,
 			memory:        []byte{'?'},
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_read(fd=4,iovs=65536,iovs_len=65535)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=65536,iovs_len=1)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -1002,7 +1812,7 @@ func Test_fdRead_Errors(t *testing.T) {
 			},
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_read(fd=4,iovs=65532,iovs_len=65532)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=65532,iovs_len=1)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -1017,7 +1827,7 @@ func Test_fdRead_Errors(t *testing.T) {
 			},
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_read(fd=4,iovs=65528,iovs_len=65528)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=65528,iovs_len=1)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -1033,7 +1843,7 @@ func Test_fdRead_Errors(t *testing.T) {
 			},
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_read(fd=4,iovs=65527,iovs_len=65527)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=65527,iovs_len=1)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -1050,7 +1860,7 @@ func Test_fdRead_Errors(t *testing.T) {
 			},
 			expectedErrno: ErrnoFault,
 			expectedLog: `
-==> wasi_snapshot_preview1.fd_read(fd=4,iovs=65527,iovs_len=65527)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=65527,iovs_len=1)
 <== (nread=,errno=EFAULT)
 `,
 		},
@@ -1066,7 +1876,7 @@ func Test_fdRead_Errors(t *testing.T) {
 			memoryWriteOK := mod.Memory().Write(offset, tc.memory)
 			require.True(t, memoryWriteOK)
 
-			requireErrno(t, tc.expectedErrno, mod, FdReadName, uint64(tc.fd), uint64(tc.iovs+offset), uint64(tc.iovsCount+offset), uint64(tc.resultNread+offset))
+			requireErrno(t, tc.expectedErrno, mod, FdReadName, uint64(tc.fd), uint64(tc.iovs+offset), uint64(tc.iovsCount), uint64(tc.resultNread+offset))
 			require.Equal(t, tc.expectedLog, "\n"+log.String())
 		})
 	}
@@ -1382,40 +2192,174 @@ func Test_fdReaddir(t *testing.T) {
 	}
 }
 
-func Test_fdReaddir_Errors(t *testing.T) {
-	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fstest.FS))
+// Test_fdReaddir_snapshot ensures the directory is snapshot on the first read,
+// so that files added mid-iteration are only visible after a rewind.
+func Test_fdReaddir_snapshot(t *testing.T) {
+	tmpDir := t.TempDir()
+	mkdir(t, tmpDir, "dir")
+	writeFile(t, tmpDir, "dir/a", nil)
+	writeFile(t, tmpDir, "dir/b", nil)
+
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(dirFS))
 	defer r.Close(testCtx)
-	memLen := mod.Memory().Size()
 
 	fsc := mod.(*wasm.CallContext).Sys.FS()
-
-	fileFD, err := fsc.OpenFile("animals.txt", os.O_RDONLY, 0)
+	fd, err := fsc.OpenFile("dir", os.O_RDONLY, 0)
 	require.NoError(t, err)
 
-	dirFD, err := fsc.OpenFile("dir", os.O_RDONLY, 0)
+	resultBufused, buf := uint32(0), uint32(8)
+	readdir := func(bufLen uint32, cookie uint64) (names []string) {
+		requireErrno(t, ErrnoSuccess, mod, FdReaddirName,
+			uint64(fd), uint64(buf), uint64(bufLen), cookie, uint64(resultBufused))
+
+		bufused, ok := mod.Memory().ReadUint32Le(resultBufused)
+		require.True(t, ok)
+		dirents, ok := mod.Memory().Read(buf, bufused)
+		require.True(t, ok)
+		for pos := uint32(0); pos+DirentSize <= bufused; {
+			nameLen := binary.LittleEndian.Uint32(dirents[pos+16:])
+			names = append(names, string(dirents[pos+DirentSize:pos+DirentSize+nameLen]))
+			pos += DirentSize + nameLen
+		}
+		return
+	}
+
+	// Read the first entry only, which snapshots the directory.
+	names := readdir(DirentSize+1, 0)
+	require.Equal(t, 1, len(names))
+
+	// Add a file mid-iteration, which shouldn't be visible yet.
+	writeFile(t, tmpDir, "dir/c", nil)
+
+	names = append(names, readdir(4096, 1)...)
+	sort.Strings(names)
+	require.Equal(t, []string{"a", "b"}, names)
+
+	// Rewinding with cookie zero takes a new snapshot.
+	names = readdir(4096, 0)
+	sort.Strings(names)
+	require.Equal(t, []string{"a", "b", "c"}, names)
+}
+
+// Test_readerAtFS ensures a single io.ReaderAt mounted as a file can be listed
+// and read at an offset.
+func Test_readerAtFS(t *testing.T) {
+	blob := make([]byte, 1024*1024)
+	for i := range blob {
+		blob[i] = byte(i * 7)
+	}
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().
+		WithFS(syscallfs.NewReaderAtFS("blob", bytes.NewReader(blob), int64(len(blob)))))
+	defer r.Close(testCtx)
+
+	// List the single entry.
+	dirFD := requireOpenFD(t, mod, ".")
+	buf, resultBufused := uint32(64), uint32(0)
+	requireErrno(t, ErrnoSuccess, mod, FdReaddirName, uint64(dirFD), uint64(buf), 128, 0, uint64(resultBufused))
+	bufused, ok := mod.Memory().ReadUint32Le(resultBufused)
+	require.True(t, ok)
+	require.Equal(t, DirentSize+uint32(len("blob")), bufused)
+	dirent, ok := mod.Memory().Read(buf, bufused)
+	require.True(t, ok)
+	require.Equal(t, "blob", string(dirent[DirentSize:]))
+	require.Equal(t, uint8(FILETYPE_REGULAR_FILE), dirent[20])
+
+	// Read a slice from the middle.
+	fd := requireOpenFD(t, mod, "blob")
+	iovs, resultNread := uint32(8), uint32(16)
+	data, length, offset := uint32(256), uint32(32), int64(len(blob)/2)
+	require.True(t, mod.Memory().Write(iovs, []byte{
+		byte(data), byte(data >> 8), 0, 0, // = iovs[0].offset
+		byte(length), 0, 0, 0, // = iovs[0].length
+	}))
+	requireErrno(t, ErrnoSuccess, mod, FdPreadName, uint64(fd), uint64(iovs), 1, uint64(offset), uint64(resultNread))
+	nread, ok := mod.Memory().ReadUint32Le(resultNread)
+	require.True(t, ok)
+	require.Equal(t, length, nread)
+	actual, ok := mod.Memory().Read(data, length)
+	require.True(t, ok)
+	require.Equal(t, blob[offset:offset+int64(length)], actual)
+}
+
+// Test_fdReaddir_file ensures reading directory entries from a regular file
+// opened by the guest without O_DIRECTORY returns EBADF, not ENOTDIR.
+func Test_fdReaddir_file(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFile(t, tmpDir, "animals.txt", []byte("bear"))
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
 	require.NoError(t, err)
 
+	// Notably, an os.File from DirFS is a fs.ReadDirFile even when a file.
 	tests := []struct {
-		name                           string
-		dir                            func() *sys.FileEntry
-		fd, buf, bufLen, resultBufused uint32
-		cookie                         int64
-		readDir                        *sys.ReadDir
-		expectedErrno                  Errno
-		expectedLog                    string
+		name string
+		fs   fs.FS
+	}{
+		{name: "fstest.FS", fs: fstest.FS},
+		{name: "DirFS", fs: dirFS},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(tc.fs))
+			defer r.Close(testCtx)
+
+			pathName := "animals.txt"
+			pathOffset, resultOpenedFd := uint32(0), uint32(16)
+			require.True(t, mod.Memory().Write(pathOffset, []byte(pathName)))
+			requireErrno(t, ErrnoSuccess, mod, PathOpenName, uint64(sys.FdPreopen), 0,
+				uint64(pathOffset), uint64(len(pathName)), 0, 0, 0, 0, uint64(resultOpenedFd))
+			fd, ok := mod.Memory().ReadUint32Le(resultOpenedFd)
+			require.True(t, ok)
+			log.Reset()
+
+			buf, resultBufused := uint32(32), uint32(16)
+			requireErrno(t, ErrnoBadf, mod, FdReaddirName, uint64(fd), uint64(buf), uint64(DirentSize), 0, uint64(resultBufused))
+			require.Equal(t, `
+==> wasi_snapshot_preview1.fd_readdir(fd=4,buf=32,buf_len=24,cookie=0,result.bufused=16)
+<== errno=EBADF
+`, "\n"+log.String())
+		})
+	}
+}
+
+func Test_fdReaddir_Errors(t *testing.T) {
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fstest.FS))
+	defer r.Close(testCtx)
+	memLen := mod.Memory().Size()
+
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+
+	fileFD, err := fsc.OpenFile("animals.txt", os.O_RDONLY, 0)
+	require.NoError(t, err)
+
+	dirFD, err := fsc.OpenFile("dir", os.O_RDONLY, 0)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                           string
+		dir                            func() *sys.FileEntry
+		fd, buf, bufLen, resultBufused uint32
+		cookie                         int64
+		readDir                        *sys.ReadDir
+		expectedErrno                  Errno
+		expectedLog                    string
 	}{
 		{
-			name:          "out-of-memory reading buf",
-			fd:            dirFD,
-			buf:           memLen,
-			bufLen:        1000,
-			expectedErrno: ErrnoFault,
-			expectedLog: `
-==> wasi_snapshot_preview1.fd_readdir(fd=5,buf=65536,buf_len=1000,cookie=0,result.bufused=0)
-<== errno=EFAULT
-`,
-		},
-		{
+			name:          "out-of-memory reading buf",
+			fd:            dirFD,
+			buf:           memLen,
+			bufLen:        1000,
+			expectedErrno: ErrnoFault,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_readdir(fd=5,buf=65536,buf_len=1000,cookie=0,result.bufused=0)
+<== errno=EFAULT
+`,
+		},
+		{
 			name: "invalid fd",
 			fd:   42,                    // arbitrary invalid fd
 			buf:  0, bufLen: DirentSize, // enough to read the dirent
@@ -1508,13 +2452,97 @@ func Test_fdReaddir_Errors(t *testing.T) {
 	}
 }
 
-// Test_fdRenumber only tests it is stubbed for GrainLang per #271
+// Test_fdRenumber ensures the offset and fdflags of the source file
+// descriptor move with it.
 func Test_fdRenumber(t *testing.T) {
-	log := requireErrnoNosys(t, FdRenumberName, 0, 0)
+	tmpDir := t.TempDir()
+	writeFile(t, tmpDir, "dst", []byte("wazero"))
+	mod, from, log, r := requireOpenFile(t, tmpDir, "src", []byte("wazero"), false)
+	defer r.Close(testCtx)
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+
+	to, err := fsc.OpenFile("dst", os.O_RDONLY, 0)
+	require.NoError(t, err)
+
+	requireErrno(t, ErrnoSuccess, mod, FdFdstatSetFlagsName, uint64(from), uint64(FD_APPEND))
+	resultOffset := uint32(1)
+	requireErrno(t, ErrnoSuccess, mod, FdSeekName, uint64(from), uint64(3), uint64(io.SeekStart), uint64(resultOffset))
+	log.Reset()
+
+	requireErrno(t, ErrnoSuccess, mod, FdRenumberName, uint64(from), uint64(to))
+	requireErrno(t, ErrnoSuccess, mod, FdSeekName, uint64(to), uint64(0), uint64(io.SeekCurrent), uint64(resultOffset))
 	require.Equal(t, `
---> wasi_snapshot_preview1.fd_renumber(fd=0,to=0)
-<-- errno=ENOSYS
-`, log)
+==> wasi_snapshot_preview1.fd_renumber(fd=4,to=5)
+<== errno=ESUCCESS
+==> wasi_snapshot_preview1.fd_seek(fd=5,offset=0,whence=1,result.newoffset=1)
+<== errno=ESUCCESS
+`, "\n"+log.String())
+
+	offset, ok := mod.Memory().ReadUint64Le(resultOffset)
+	require.True(t, ok)
+	require.Equal(t, uint64(3), offset)
+
+	f, ok := fsc.LookupFile(to)
+	require.True(t, ok)
+	require.Equal(t, uint16(FD_APPEND), f.FdFlags)
+	require.Equal(t, "src", f.Name)
+
+	// The source file descriptor is no longer open.
+	_, ok = fsc.LookupFile(from)
+	require.False(t, ok)
+}
+
+func Test_fdRenumber_Errors(t *testing.T) {
+	mod, fd, log, r := requireOpenFile(t, t.TempDir(), "test_path", []byte("wazero"), false)
+	defer r.Close(testCtx)
+
+	tests := []struct {
+		name          string
+		fd, to        uint32
+		expectedErrno Errno
+		expectedLog   string
+	}{
+		{
+			name:          "invalid fd",
+			fd:            42, // arbitrary invalid fd
+			to:            fd,
+			expectedErrno: ErrnoBadf,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_renumber(fd=42,to=4)
+<== errno=EBADF
+`,
+		},
+		{
+			name:          "invalid to",
+			fd:            fd,
+			to:            42, // arbitrary invalid fd
+			expectedErrno: ErrnoBadf,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_renumber(fd=4,to=42)
+<== errno=EBADF
+`,
+		},
+		{
+			name:          "preopen",
+			fd:            fd,
+			to:            sys.FdPreopen,
+			expectedErrno: ErrnoNotsup,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_renumber(fd=4,to=3)
+<== errno=ENOTSUP
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			defer log.Reset()
+
+			requireErrno(t, tc.expectedErrno, mod, FdRenumberName, uint64(tc.fd), uint64(tc.to))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+		})
+	}
 }
 
 func Test_fdSeek(t *testing.T) {
@@ -1641,6 +2669,17 @@ func Test_fdSeek_Errors(t *testing.T) {
 			expectedLog: `
 ==> wasi_snapshot_preview1.fd_seek(fd=4,offset=0,whence=3,result.newoffset=0)
 <== errno=EINVAL
+`,
+		},
+		{
+			name:          "negative offset with SeekStart",
+			fd:            fd,
+			offset:        math.MaxUint64, // -1 as an int64
+			whence:        io.SeekStart,
+			expectedErrno: ErrnoInval,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_seek(fd=4,offset=-1,whence=0,result.newoffset=0)
+<== errno=EINVAL
 `,
 		},
 		{
@@ -1666,22 +2705,296 @@ func Test_fdSeek_Errors(t *testing.T) {
 	}
 }
 
-// Test_fdSync only tests it is stubbed for GrainLang per #271
+// Test_fdSeek_hostOffset ensures the host can read and reposition the offset
+// of a guest file descriptor.
+func Test_fdSeek_hostOffset(t *testing.T) {
+	mod, fd, _, r := requireOpenFile(t, t.TempDir(), "test_path", []byte("wazero"), true)
+	defer r.Close(testCtx)
+
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+
+	// The host sees where the guest seeked to.
+	resultNewoffset := uint32(1) // arbitrary offset
+	requireErrno(t, ErrnoSuccess, mod, FdSeekName, uint64(fd), uint64(2), uint64(io.SeekStart), uint64(resultNewoffset))
+	offset, ok := fsc.FileOffset(fd)
+	require.True(t, ok)
+	require.Equal(t, int64(2), offset)
+
+	// The guest reads from where the host set the offset.
+	require.NoError(t, fsc.SetFileOffset(fd, 4))
+
+	iovs, resultNread := uint32(16), uint32(32)
+	require.True(t, mod.Memory().Write(iovs, []byte{
+		24, 0, 0, 0, // = iovs[0].offset
+		6, 0, 0, 0, // = iovs[0].length
+	}))
+	requireErrno(t, ErrnoSuccess, mod, FdReadName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNread))
+
+	nread, ok := mod.Memory().ReadUint32Le(resultNread)
+	require.True(t, ok)
+	read, ok := mod.Memory().Read(24, nread)
+	require.True(t, ok)
+	require.Equal(t, "ro", string(read))
+}
+
+// seekFile is a fs.File which can seek to any non-negative offset, such as
+// near math.MaxInt64, unlike files on a real filesystem.
+type seekFile struct{ offset int64 }
+
+func (f *seekFile) Stat() (fs.FileInfo, error) { return nil, nil }
+func (f *seekFile) Read([]byte) (int, error)   { return 0, io.EOF }
+func (f *seekFile) Close() error               { return nil }
+
+func (f *seekFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	default:
+		return 0, syscall.EINVAL
+	}
+	if offset < 0 {
+		return 0, syscall.EINVAL
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// seekFS returns the same seekFile for any path.
+type seekFS struct{ f *seekFile }
+
+func (s seekFS) Open(string) (fs.File, error) { return s.f, nil }
+
+func Test_fdSeek_overflow(t *testing.T) {
+	file := &seekFile{}
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(seekFS{file}))
+	defer r.Close(testCtx)
+
+	fd := requireOpenFD(t, mod, "file")
+
+	tests := []struct {
+		name          string
+		start, offset int64
+		expectedLog   string
+	}{
+		{
+			name:   "overflow",
+			start:  math.MaxInt64 - 10,
+			offset: 100,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_seek(fd=4,offset=100,whence=1,result.newoffset=0)
+<== errno=EINVAL
+`,
+		},
+		{
+			name:   "underflow",
+			start:  10,
+			offset: -100,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_seek(fd=4,offset=-100,whence=1,result.newoffset=0)
+<== errno=EINVAL
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			defer log.Reset()
+
+			file.offset = tc.start
+
+			requireErrno(t, ErrnoInval, mod, FdSeekName, uint64(fd), uint64(tc.offset), uint64(io.SeekCurrent), 0)
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+
+			// The position is unchanged.
+			require.Equal(t, tc.start, file.offset)
+		})
+	}
+}
+
 func Test_fdSync(t *testing.T) {
-	log := requireErrnoNosys(t, FdSyncName, 0)
+	tmpDir := t.TempDir() // open before loop to ensure no locking problems.
+
+	tests := []struct {
+		name          string
+		fd            uint32
+		closed        bool
+		expectedErrno Errno
+		expectedLog   string
+	}{
+		{
+			name:          "file",
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_sync(fd=4)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			// Closing the *os.File behind the fd makes its Sync fail, which
+			// shows fd_sync called it.
+			name:          "file closed on the host",
+			closed:        true,
+			expectedErrno: ErrnoBadf,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_sync(fd=4)
+<== errno=EBADF
+`,
+		},
+		{
+			name:          "invalid fd",
+			fd:            42, // arbitrary invalid fd
+			expectedErrno: ErrnoBadf,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_sync(fd=42)
+<== errno=EBADF
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			mod, fd, log, r := requireOpenFile(t, tmpDir, tc.name, []byte("wazero"), false)
+			defer r.Close(testCtx)
+
+			if tc.closed {
+				f, ok := mod.(*wasm.CallContext).Sys.FS().LookupFile(fd)
+				require.True(t, ok)
+				require.NoError(t, f.File.(*os.File).Close())
+			}
+
+			if tc.fd != 0 {
+				fd = tc.fd
+			}
+
+			requireErrno(t, tc.expectedErrno, mod, FdSyncName, uint64(fd))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+		})
+	}
+}
+
+// syncFile is a fs.File which counts calls to Sync, returning err.
+type syncFile struct {
+	seekFile
+	syncs int
+	err   error
+}
+
+func (f *syncFile) Sync() error {
+	f.syncs++
+	return f.err
+}
+
+// syncFS returns its file for any name.
+type syncFS struct{ file *syncFile }
+
+func (s syncFS) Open(string) (fs.File, error) { return s.file, nil }
+
+// Test_fdSync_syncFile ensures fd_sync calls Sync on files that implement
+// it, returning any error as an errno.
+func Test_fdSync_syncFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectedErrno Errno
+		expectedLog   string
+	}{
+		{
+			name:          "success",
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_sync(fd=4)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "error",
+			err:           syscall.EIO,
+			expectedErrno: ErrnoIo,
+			expectedLog: `
+==> wasi_snapshot_preview1.fd_sync(fd=4)
+<== errno=EIO
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			file := &syncFile{err: tc.err}
+			mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(syncFS{file}))
+			defer r.Close(testCtx)
+
+			fd := requireOpenFD(t, mod, "file")
+
+			requireErrno(t, tc.expectedErrno, mod, FdSyncName, uint64(fd))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+			require.Equal(t, 1, file.syncs)
+		})
+	}
+}
+
+// Test_fdSync_noSync ensures files without a Sync method, such as those in an
+// fs.FS, succeed as there is nothing to synchronize.
+func Test_fdSync_noSync(t *testing.T) {
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fstest.FS))
+	defer r.Close(testCtx)
+
+	fd := requireOpenFD(t, mod, "animals.txt")
+
+	requireErrno(t, ErrnoSuccess, mod, FdSyncName, uint64(fd))
 	require.Equal(t, `
---> wasi_snapshot_preview1.fd_sync(fd=0)
-<-- errno=ENOSYS
-`, log)
+==> wasi_snapshot_preview1.fd_sync(fd=4)
+<== errno=ESUCCESS
+`, "\n"+log.String())
 }
 
-// Test_fdTell only tests it is stubbed for GrainLang per #271
 func Test_fdTell(t *testing.T) {
-	log := requireErrnoNosys(t, FdTellName, 0, 0)
+	mod, fd, log, r := requireOpenFile(t, t.TempDir(), "test_path", []byte("wazero"), false)
+	defer r.Close(testCtx)
+
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+	require.NoError(t, fsc.SetFileOffset(fd, 4))
+
+	resultOffset := uint32(1) // arbitrary offset
+	expectedMemory := []byte{
+		'?',                    // resultOffset is after this
+		4, 0, 0, 0, 0, 0, 0, 0, // = expected offset
+		'?',
+	}
+	maskMemory(t, mod, len(expectedMemory))
+
+	requireErrno(t, ErrnoSuccess, mod, FdTellName, uint64(fd), uint64(resultOffset))
 	require.Equal(t, `
---> wasi_snapshot_preview1.fd_tell(fd=0,result.offset=0)
-<-- errno=ENOSYS
-`, log)
+==> wasi_snapshot_preview1.fd_tell(fd=4,result.offset=1)
+<== errno=ESUCCESS
+`, "\n"+log.String())
+
+	actual, ok := mod.Memory().Read(0, uint32(len(expectedMemory)))
+	require.True(t, ok)
+	require.Equal(t, expectedMemory, actual)
+
+	t.Run("invalid fd", func(t *testing.T) {
+		log.Reset()
+		requireErrno(t, ErrnoBadf, mod, FdTellName, uint64(42), uint64(resultOffset))
+		require.Equal(t, `
+==> wasi_snapshot_preview1.fd_tell(fd=42,result.offset=1)
+<== errno=EBADF
+`, "\n"+log.String())
+	})
+
+	t.Run("out-of-memory", func(t *testing.T) {
+		log.Reset()
+		requireErrno(t, ErrnoFault, mod, FdTellName, uint64(fd), uint64(mod.Memory().Size()))
+		require.Equal(t, `
+==> wasi_snapshot_preview1.fd_tell(fd=4,result.offset=65536)
+<== errno=EFAULT
+`, "\n"+log.String())
+	})
 }
 
 func Test_fdWrite(t *testing.T) {
@@ -1761,18 +3074,560 @@ func Test_fdWrite_discard(t *testing.T) {
 		'?',
 	)
 
-	maskMemory(t, mod, len(expectedMemory))
-	ok := mod.Memory().Write(0, initialMemory)
-	require.True(t, ok)
+	maskMemory(t, mod, len(expectedMemory))
+	ok := mod.Memory().Write(0, initialMemory)
+	require.True(t, ok)
+
+	fd := sys.FdStdout
+	requireErrno(t, ErrnoSuccess, mod, FdWriteName, uint64(fd), uint64(iovs), uint64(iovsCount), uint64(resultNwritten))
+	// Should not amplify logging
+	require.Zero(t, len(log.Bytes()))
+
+	actual, ok := mod.Memory().Read(0, uint32(len(expectedMemory)))
+	require.True(t, ok)
+	require.Equal(t, expectedMemory, actual)
+}
+
+// Test_fdWrite_stream ensures writes to stdout reach a streaming writer, such
+// as a pipe, as they happen as opposed to when the guest finishes.
+func Test_fdWrite_stream(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithStdout(pw))
+	defer r.Close(testCtx)
+
+	ok := mod.Memory().Write(0, []byte{
+		16, 0, 0, 0, // = iovs[0].offset
+		3, 0, 0, 0, // = iovs[0].length
+		19, 0, 0, 0, // = iovs[1].offset
+		3, 0, 0, 0, // = iovs[1].length
+		'w', 'a', 'z', 'e', 'r', 'o',
+	})
+	require.True(t, ok)
+
+	fdWrite := mod.ExportedFunction(FdWriteName)
+	proceed, errs := make(chan struct{}), make(chan error)
+	go func() {
+		defer close(errs)
+		for _, iovs := range []uint64{0, 8} {
+			if iovs != 0 {
+				<-proceed // block the guest until the host read the first write.
+			}
+			if _, err := fdWrite.Call(testCtx, uint64(sys.FdStdout), iovs, 1, 32); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	buf := make([]byte, 3)
+	_, err := io.ReadFull(pr, buf)
+	require.NoError(t, err)
+	require.Equal(t, "waz", string(buf))
+
+	close(proceed)
+	_, err = io.ReadFull(pr, buf)
+	require.NoError(t, err)
+	require.Equal(t, "ero", string(buf))
+
+	require.NoError(t, <-errs)
+}
+
+// Test_fdWrite_closedPipe ensures writes to stdout return EPIPE once the read
+// end of a pipe is closed, as opposed to a generic EIO.
+func Test_fdWrite_closedPipe(t *testing.T) {
+	pr, pw := io.Pipe()
+	require.NoError(t, pr.Close())
+
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithStdout(pw))
+	defer r.Close(testCtx)
+
+	iovs, resultNwritten := uint32(0), uint32(16)
+	ok := mod.Memory().Write(0, []byte{
+		8, 0, 0, 0, // = iovs[0].offset
+		6, 0, 0, 0, // = iovs[0].length
+		'w', 'a', 'z', 'e', 'r', 'o',
+	})
+	require.True(t, ok)
+
+	requireErrno(t, ErrnoPipe, mod, FdWriteName, uint64(sys.FdStdout), uint64(iovs), uint64(1), uint64(resultNwritten))
+}
+
+// Test_fdWrite_noCopy ensures a single large write passes the guest memory to
+// the writer directly, rather than an intermediate copy.
+func Test_fdWrite_noCopy(t *testing.T) {
+	const size = 1 << 20 // 1MB
+
+	var written []byte
+	var aliased bool
+	var mod api.Module
+	stdout := writerFunc(func(p []byte) (int, error) {
+		// Read the slice synchronously, as it is only valid during the call.
+		mem, ok := mod.Memory().Read(16, size)
+		require.True(t, ok)
+		aliased = len(p) == size && &p[0] == &mem[0]
+		written = append(written[:0], p...)
+		return len(p), nil
+	})
+
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithStdout(stdout))
+	defer r.Close(testCtx)
+
+	_, ok := mod.Memory().Grow(size / 65536)
+	require.True(t, ok)
+
+	expected := make([]byte, size)
+	for i := range expected {
+		expected[i] = byte(i)
+	}
+	iovs, resultNwritten := uint32(0), uint32(8)
+	require.True(t, mod.Memory().WriteUint32Le(iovs, 16))     // = iovs[0].offset
+	require.True(t, mod.Memory().WriteUint32Le(iovs+4, size)) // = iovs[0].length
+	require.True(t, mod.Memory().Write(16, expected))
+
+	requireErrno(t, ErrnoSuccess, mod, FdWriteName, uint64(sys.FdStdout), uint64(iovs), uint64(1), uint64(resultNwritten))
+
+	require.True(t, aliased)
+	require.Equal(t, expected, written)
+	nwritten, ok := mod.Memory().ReadUint32Le(resultNwritten)
+	require.True(t, ok)
+	require.Equal(t, uint32(size), nwritten)
+}
+
+// errBucketFull is a custom error, such as an object store might return.
+var errBucketFull = errors.New("bucket full")
+
+// fullFile is a fs.File whose writes fail with errBucketFull.
+type fullFile struct{ seekFile }
+
+func (f *fullFile) Write([]byte) (int, error) { return 0, errBucketFull }
+
+// fullFS returns a new fullFile for any path.
+type fullFS struct{}
+
+func (fullFS) Open(string) (fs.File, error) { return &fullFile{}, nil }
+
+// Test_fdWrite_errnoMapper ensures custom file system errors can be mapped to
+// an errno via ModuleConfig.WithFSErrnoMapper.
+func Test_fdWrite_errnoMapper(t *testing.T) {
+	mapper := func(err error) (syscall.Errno, bool) {
+		if errors.Is(err, errBucketFull) {
+			return syscall.ENOSPC, true
+		}
+		return 0, false
+	}
+
+	tests := []struct {
+		name          string
+		config        wazero.ModuleConfig
+		expectedErrno Errno
+	}{
+		{
+			name:          "default",
+			config:        wazero.NewModuleConfig().WithFS(fullFS{}),
+			expectedErrno: ErrnoIo,
+		},
+		{
+			name:          "mapped",
+			config:        wazero.NewModuleConfig().WithFS(fullFS{}).WithFSErrnoMapper(mapper),
+			expectedErrno: ErrnoNospc,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			mod, r, _ := requireProxyModule(t, tc.config)
+			defer r.Close(testCtx)
+
+			fd := requireOpenFD(t, mod, "file")
+
+			iovs, resultNwritten := uint32(0), uint32(16)
+			ok := mod.Memory().Write(0, []byte{
+				8, 0, 0, 0, // = iovs[0].offset
+				6, 0, 0, 0, // = iovs[0].length
+				'w', 'a', 'z', 'e', 'r', 'o',
+			})
+			require.True(t, ok)
+
+			requireErrno(t, tc.expectedErrno, mod, FdWriteName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNwritten))
+		})
+	}
+}
+
+// errBucketOffline is a custom error, such as an object store might return.
+var errBucketOffline = errors.New("bucket offline")
+
+// offlineFile is a fs.File whose reads and seeks fail with errBucketOffline.
+type offlineFile struct{ seekFile }
+
+func (f *offlineFile) Read([]byte) (int, error)       { return 0, errBucketOffline }
+func (f *offlineFile) Seek(int64, int) (int64, error) { return 0, errBucketOffline }
+
+// offlineDir is a fs.ReadDirFile whose listing fails with errBucketOffline.
+type offlineDir struct{ seekFile }
+
+func (d *offlineDir) Stat() (fs.FileInfo, error) {
+	return fs.Stat(gofstest.MapFS{"dir": {Mode: fs.ModeDir}}, "dir")
+}
+
+func (d *offlineDir) ReadDir(int) ([]fs.DirEntry, error) { return nil, errBucketOffline }
+
+// offlineFS returns a new offlineDir for "dir" and offlineFile otherwise.
+type offlineFS struct{}
+
+func (offlineFS) Open(name string) (fs.File, error) {
+	if name == "dir" {
+		return &offlineDir{}, nil
+	}
+	return &offlineFile{}, nil
+}
+
+// Test_errnoMapper ensures custom file system errors returned by reads, seeks
+// and directory listings are mapped via ModuleConfig.WithFSErrnoMapper.
+func Test_errnoMapper(t *testing.T) {
+	mapper := func(err error) (syscall.Errno, bool) {
+		if errors.Is(err, errBucketOffline) {
+			return syscall.EAGAIN, true
+		}
+		return 0, false
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		funcName string
+		params   func(fd uint32) []uint64
+	}{
+		{
+			name:     "fd_read",
+			path:     "file",
+			funcName: FdReadName,
+			params: func(fd uint32) []uint64 {
+				return []uint64{uint64(fd), 0, 1, 16} // iovs, iovsCount, resultNread
+			},
+		},
+		{
+			name:     "fd_seek",
+			path:     "file",
+			funcName: FdSeekName,
+			params: func(fd uint32) []uint64 {
+				return []uint64{uint64(fd), 0, io.SeekStart, 16} // offset, whence, resultNewoffset
+			},
+		},
+		{
+			name:     "fd_seek SeekCurrent",
+			path:     "file",
+			funcName: FdSeekName,
+			params: func(fd uint32) []uint64 {
+				return []uint64{uint64(fd), 1, io.SeekCurrent, 16} // offset, whence, resultNewoffset
+			},
+		},
+		{
+			name:     "fd_readdir",
+			path:     "dir",
+			funcName: FdReaddirName,
+			params: func(fd uint32) []uint64 {
+				return []uint64{uint64(fd), 32, uint64(DirentSize), 0, 16} // buf, bufLen, cookie, resultBufused
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("default", func(t *testing.T) {
+				mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(offlineFS{}))
+				defer r.Close(testCtx)
+
+				fd := requireOpenFD(t, mod, tc.path)
+				require.True(t, mod.Memory().Write(0, []byte{8, 0, 0, 0, 4, 0, 0, 0})) // = iovs[0]
+				requireErrno(t, ErrnoIo, mod, tc.funcName, tc.params(fd)...)
+			})
+
+			t.Run("mapped", func(t *testing.T) {
+				config := wazero.NewModuleConfig().WithFS(offlineFS{}).WithFSErrnoMapper(mapper)
+				mod, r, _ := requireProxyModule(t, config)
+				defer r.Close(testCtx)
+
+				fd := requireOpenFD(t, mod, tc.path)
+				require.True(t, mod.Memory().Write(0, []byte{8, 0, 0, 0, 4, 0, 0, 0})) // = iovs[0]
+				requireErrno(t, ErrnoAgain, mod, tc.funcName, tc.params(fd)...)
+			})
+		})
+	}
+}
+
+// errnoFile is a fs.File whose writes fail with errno.
+type errnoFile struct {
+	seekFile
+	errno syscall.Errno
+}
+
+func (f *errnoFile) Write([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "write", Path: "file", Err: f.errno}
+}
+
+// errnoFS returns a new errnoFile for any path.
+type errnoFS struct{ errno syscall.Errno }
+
+func (e errnoFS) Open(string) (fs.File, error) { return &errnoFile{errno: e.errno}, nil }
+
+// Test_fdWrite_quota ensures a file system enforcing a quota is reported
+// distinctly from one that is full.
+func Test_fdWrite_quota(t *testing.T) {
+	tests := []struct {
+		name          string
+		errno         syscall.Errno
+		expectedErrno Errno
+	}{
+		{
+			name:          "EDQUOT",
+			errno:         syscall.EDQUOT,
+			expectedErrno: ErrnoDquot,
+		},
+		{
+			name:          "ENOSPC",
+			errno:         syscall.ENOSPC,
+			expectedErrno: ErrnoNospc,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(errnoFS{tc.errno}))
+			defer r.Close(testCtx)
+
+			fd := requireOpenFD(t, mod, "file")
+
+			iovs, resultNwritten := uint32(0), uint32(16)
+			ok := mod.Memory().Write(0, []byte{
+				8, 0, 0, 0, // = iovs[0].offset
+				6, 0, 0, 0, // = iovs[0].length
+				'w', 'a', 'z', 'e', 'r', 'o',
+			})
+			require.True(t, ok)
+
+			requireErrno(t, tc.expectedErrno, mod, FdWriteName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNwritten))
+		})
+	}
+}
+
+// sizeRecordingReader records the size of each read, filling it with 'a'.
+type sizeRecordingReader struct{ sizes []int }
+
+func (r *sizeRecordingReader) Read(p []byte) (int, error) {
+	r.sizes = append(r.sizes, len(p))
+	for i := range p {
+		p[i] = 'a'
+	}
+	return len(p), nil
+}
+
+// Test_fdRead_chunkSize ensures ModuleConfig.WithFSReadChunkSize bounds the
+// size of each read.
+func Test_fdRead_chunkSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		chunkSize     uint32
+		expectedSizes []int
+	}{
+		{name: "default", expectedSizes: []int{10}},
+		{name: "chunked", chunkSize: 4, expectedSizes: []int{4, 4, 2}},
+		{name: "chunk larger than iovec", chunkSize: 16, expectedSizes: []int{10}},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			reader := &sizeRecordingReader{}
+			mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().
+				WithStdin(reader).WithFSReadChunkSize(tc.chunkSize))
+			defer r.Close(testCtx)
+
+			iovs, resultNread := uint32(0), uint32(8)
+			ok := mod.Memory().Write(iovs, []byte{
+				16, 0, 0, 0, // = iovs[0].offset
+				10, 0, 0, 0, // = iovs[0].length
+			})
+			require.True(t, ok)
+
+			requireErrno(t, ErrnoSuccess, mod, FdReadName, uint64(sys.FdStdin), uint64(iovs), uint64(1), uint64(resultNread))
+
+			nread, ok := mod.Memory().ReadUint32Le(resultNread)
+			require.True(t, ok)
+			require.Equal(t, uint32(10), nread)
+			require.Equal(t, tc.expectedSizes, reader.sizes)
+		})
+	}
+}
+
+// Test_fdRead_stream ensures reads from stdin block until a streaming reader,
+// such as a pipe, has data.
+func Test_fdRead_stream(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithStdin(pr))
+	defer r.Close(testCtx)
+
+	ok := mod.Memory().Write(0, []byte{
+		8, 0, 0, 0, // = iovs[0].offset
+		6, 0, 0, 0, // = iovs[0].length
+	})
+	require.True(t, ok)
+
+	resultNread := uint32(16)
+	results := make(chan error)
+	go func() {
+		_, err := mod.ExportedFunction(FdReadName).Call(testCtx, uint64(sys.FdStdin), 0, 1, uint64(resultNread))
+		results <- err
+	}()
+
+	_, err := pw.Write([]byte("wazero"))
+	require.NoError(t, err)
+	require.NoError(t, <-results)
+
+	nread, ok := mod.Memory().ReadUint32Le(resultNread)
+	require.True(t, ok)
+	require.Equal(t, uint32(6), nread)
+
+	actual, ok := mod.Memory().Read(8, nread)
+	require.True(t, ok)
+	require.Equal(t, "wazero", string(actual))
+}
+
+// Test_fdWrite_append ensures appends via different file descriptors to the
+// same file don't overwrite each other.
+func Test_fdWrite_append(t *testing.T) {
+	tests := []struct {
+		name      string
+		openFlags int
+		setFlags  bool
+	}{
+		{name: "opened with FD_APPEND", openFlags: os.O_RDWR | os.O_APPEND},
+		{name: "FD_APPEND via fd_fdstat_set_flags", openFlags: os.O_RDWR, setFlags: true},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeFile(t, tmpDir, "log", []byte("0"))
+			writeFS, err := syscallfs.NewDirFS(tmpDir)
+			require.NoError(t, err)
+
+			mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(writeFS))
+			defer r.Close(testCtx)
+			fsc := mod.(*wasm.CallContext).Sys.FS()
+
+			var fds []uint32
+			for i := 0; i < 2; i++ {
+				fd, err := fsc.OpenFile("log", tc.openFlags, 0)
+				require.NoError(t, err)
+				if tc.setFlags {
+					requireErrno(t, ErrnoSuccess, mod, FdFdstatSetFlagsName, uint64(fd), uint64(FD_APPEND))
+				}
+				fds = append(fds, fd)
+			}
+
+			write := func(fd uint32, s string) {
+				iovs, resultNwritten := uint32(0), uint32(8)
+				ok := mod.Memory().Write(iovs, []byte{
+					16, 0, 0, 0, // = iovs[0].offset
+					byte(len(s)), 0, 0, 0, // = iovs[0].length
+				})
+				require.True(t, ok)
+				require.True(t, mod.Memory().WriteString(16, s))
+				requireErrno(t, ErrnoSuccess, mod, FdWriteName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNwritten))
+			}
+
+			// Interleave writes between the file descriptors.
+			write(fds[0], "a")
+			write(fds[1], "bb")
+			write(fds[0], "ccc")
+
+			for _, fd := range fds {
+				require.NoError(t, fsc.CloseFile(fd))
+			}
+			require.Equal(t, "0abbccc", string(readFile(t, tmpDir, "log")))
+		})
+	}
+}
+
+// Test_fdWrite_buffered ensures buffered writes are persisted when the module
+// is closed, even if the guest never closed the file.
+func Test_fdWrite_buffered(t *testing.T) {
+	tmpDir := t.TempDir()
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(syscallfs.NewBufferedFS(dirFS, 4096)))
+	defer r.Close(testCtx)
 
-	fd := sys.FdStdout
-	requireErrno(t, ErrnoSuccess, mod, FdWriteName, uint64(fd), uint64(iovs), uint64(iovsCount), uint64(resultNwritten))
-	// Should not amplify logging
-	require.Zero(t, len(log.Bytes()))
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+	fd, err := fsc.OpenFile("buffered", os.O_RDWR|os.O_CREATE, 0o600)
+	require.NoError(t, err)
 
-	actual, ok := mod.Memory().Read(0, uint32(len(expectedMemory)))
+	iovs, resultNwritten := uint32(1), uint32(16)
+	ok := mod.Memory().Write(0, []byte{
+		'?',        // `iovs` is after this
+		9, 0, 0, 0, // = iovs[0].offset
+		6, 0, 0, 0, // = iovs[0].length
+		'w', 'a', 'z', 'e', 'r', 'o',
+	})
 	require.True(t, ok)
-	require.Equal(t, expectedMemory, actual)
+
+	requireErrno(t, ErrnoSuccess, mod, FdWriteName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNwritten))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_write(fd=4,iovs=1,iovs_len=1)
+<== (nwritten=6,errno=ESUCCESS)
+`, "\n"+log.String())
+
+	// The write was accepted, but is still buffered.
+	require.Zero(t, len(readFile(t, tmpDir, "buffered")))
+
+	require.NoError(t, mod.Close(testCtx))
+	require.Equal(t, []byte("wazero"), readFile(t, tmpDir, "buffered"))
+}
+
+// Test_fdReadWrite_iovMax ensures fd_read and fd_write reject more than
+// IOV_MAX (1024) iovecs, including counts that would overflow when scaled to
+// bytes, before touching the file or memory.
+func Test_fdReadWrite_iovMax(t *testing.T) {
+	mod, fd, log, r := requireOpenFile(t, t.TempDir(), "file", []byte("wazero"), false)
+	defer r.Close(testCtx)
+
+	// Zero the iovecs, so that the maximum count reads and writes nothing.
+	iovs, resultN := uint32(0), uint32(1024*8)
+	require.True(t, mod.Memory().Write(iovs, make([]byte, 1024*8+4)))
+
+	for _, name := range []string{FdReadName, FdWriteName} {
+		requireErrno(t, ErrnoSuccess, mod, name, uint64(fd), uint64(iovs), 1024, uint64(resultN))
+		requireErrno(t, ErrnoInval, mod, name, uint64(fd), uint64(iovs), 1025, uint64(resultN))
+		requireErrno(t, ErrnoInval, mod, name, uint64(fd), uint64(iovs), math.MaxUint32, uint64(resultN))
+	}
+	require.Equal(t, `
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=1024)
+<== (nread=0,errno=ESUCCESS)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=1025)
+<== (nread=,errno=EINVAL)
+==> wasi_snapshot_preview1.fd_read(fd=4,iovs=0,iovs_len=-1)
+<== (nread=,errno=EINVAL)
+==> wasi_snapshot_preview1.fd_write(fd=4,iovs=0,iovs_len=1024)
+<== (nwritten=0,errno=ESUCCESS)
+==> wasi_snapshot_preview1.fd_write(fd=4,iovs=0,iovs_len=1025)
+<== (nwritten=,errno=EINVAL)
+==> wasi_snapshot_preview1.fd_write(fd=4,iovs=0,iovs_len=-1)
+<== (nwritten=,errno=EINVAL)
+`, "\n"+log.String())
 }
 
 func Test_fdWrite_Errors(t *testing.T) {
@@ -1902,6 +3757,46 @@ func Test_pathCreateDirectory(t *testing.T) {
 	require.Equal(t, pathName, stat.Name())
 }
 
+// Test_pathCreateDirectory_nametoolong ensures a path component longer than
+// NAME_MAX fails with ENAMETOOLONG, instead of a generic error.
+func Test_pathCreateDirectory_nametoolong(t *testing.T) {
+	tmpDir := t.TempDir()
+	fs, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fs))
+	defer r.Close(testCtx)
+
+	pathName := strings.Repeat("a", 300)
+	ok := mod.Memory().Write(0, []byte(pathName))
+	require.True(t, ok)
+
+	requireErrno(t, ErrnoNametoolong, mod, PathCreateDirectoryName, uint64(sys.FdPreopen), 0, uint64(len(pathName)))
+	require.Equal(t, fmt.Sprintf(`
+==> wasi_snapshot_preview1.path_create_directory(fd=3,path=%s)
+<== errno=ENAMETOOLONG
+`, pathName), "\n"+log.String())
+	log.Reset()
+
+	requireErrno(t, ErrnoNametoolong, mod, PathOpenName, uint64(sys.FdPreopen), 0, 0, uint64(len(pathName)), uint64(O_CREAT), 0, 0, 0, 0)
+	require.Equal(t, fmt.Sprintf(`
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=%s,oflags=CREAT,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=,errno=ENAMETOOLONG)
+`, pathName), "\n"+log.String())
+
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Zero(t, len(entries))
+
+	// Only components are limited, not the whole path.
+	pathName = strings.Repeat("a/", 200)
+	require.True(t, len(pathName) > 255)
+	require.NoError(t, os.MkdirAll(path.Join(tmpDir, pathName), 0o700))
+	ok = mod.Memory().Write(0, []byte(pathName+"b"))
+	require.True(t, ok)
+	requireErrno(t, ErrnoSuccess, mod, PathCreateDirectoryName, uint64(sys.FdPreopen), 0, uint64(len(pathName)+1))
+}
+
 func Test_pathCreateDirectory_Errors(t *testing.T) {
 	tmpDir := t.TempDir() // open before loop to ensure no locking problems.
 	fs, err := syscallfs.NewDirFS(tmpDir)
@@ -2007,6 +3902,31 @@ func Test_pathCreateDirectory_Errors(t *testing.T) {
 	}
 }
 
+// fullDiskFS is a syscallfs.FS which has no space left to create directories.
+type fullDiskFS struct{ syscallfs.FS }
+
+func (fullDiskFS) Mkdir(name string, _ fs.FileMode) error {
+	return &fs.PathError{Op: "mkdir", Path: name, Err: syscall.ENOSPC}
+}
+
+func Test_pathCreateDirectory_nospc(t *testing.T) {
+	writeFS, err := syscallfs.NewDirFS(t.TempDir())
+	require.NoError(t, err)
+
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fullDiskFS{writeFS}))
+	defer r.Close(testCtx)
+
+	name := "dir"
+	ok := mod.Memory().Write(0, []byte(name))
+	require.True(t, ok)
+
+	requireErrno(t, ErrnoNospc, mod, PathCreateDirectoryName, uint64(sys.FdPreopen), 0, uint64(len(name)))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.path_create_directory(fd=3,path=dir)
+<== errno=ENOSPC
+`, "\n"+log.String())
+}
+
 func Test_pathFilestatGet(t *testing.T) {
 	file, dir, fileInDir := "animals.txt", "sub", "sub/test.txt"
 
@@ -2037,7 +3957,7 @@ func Test_pathFilestatGet(t *testing.T) {
 			expectedMemory: append(
 				initialMemoryFile,
 				0, 0, 0, 0, 0, 0, 0, 0, // dev
-				0, 0, 0, 0, 0, 0, 0, 0, // ino
+				0xcc, 0xdd, 0x00, 0xf5, 0xa1, 0x2c, 0x99, 0x97, // ino
 				4, 0, 0, 0, 0, 0, 0, 0, // filetype + padding
 				1, 0, 0, 0, 0, 0, 0, 0, // nlink
 				30, 0, 0, 0, 0, 0, 0, 0, // size
@@ -2059,7 +3979,7 @@ func Test_pathFilestatGet(t *testing.T) {
 			expectedMemory: append(
 				initialMemoryFileInDir,
 				0, 0, 0, 0, 0, 0, 0, 0, // dev
-				0, 0, 0, 0, 0, 0, 0, 0, // ino
+				0x90, 0xcc, 0xf2, 0x22, 0x6c, 0xd0, 0xca, 0x4f, // ino
 				4, 0, 0, 0, 0, 0, 0, 0, // filetype + padding
 				1, 0, 0, 0, 0, 0, 0, 0, // nlink
 				14, 0, 0, 0, 0, 0, 0, 0, // size
@@ -2081,7 +4001,7 @@ func Test_pathFilestatGet(t *testing.T) {
 			expectedMemory: append(
 				initialMemoryDir,
 				0, 0, 0, 0, 0, 0, 0, 0, // dev
-				0, 0, 0, 0, 0, 0, 0, 0, // ino
+				0xf5, 0xc2, 0x0f, 0x5d, 0x19, 0x9d, 0x71, 0x82, // ino
 				3, 0, 0, 0, 0, 0, 0, 0, // filetype + padding
 				1, 0, 0, 0, 0, 0, 0, 0, // nlink
 				0, 0, 0, 0, 0, 0, 0, 0, // size
@@ -2172,13 +4092,242 @@ func Test_pathFilestatGet(t *testing.T) {
 	}
 }
 
-// Test_pathFilestatSetTimes only tests it is stubbed for GrainLang per #271
+// Test_pathFilestatGet_preopen ensures "." and the empty path stat the
+// directory of the file descriptor, such as the pre-open root.
+func Test_pathFilestatGet_preopen(t *testing.T) {
+	tmpDir := t.TempDir()
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	st, err := os.Stat(tmpDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		fs           fs.FS
+		expectedMtim uint64
+	}{
+		{name: "fstest.FS", fs: fstest.FS, expectedMtim: 1609459200000000000},
+		{name: "syscallfs.DirFS", fs: dirFS, expectedMtim: uint64(st.ModTime().UnixNano())},
+	}
+
+	for _, tt := range tests {
+		for _, pathName := range []string{".", ""} {
+			tc, pathName := tt, pathName
+
+			t.Run(fmt.Sprintf("%s %q", tc.name, pathName), func(t *testing.T) {
+				mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(tc.fs))
+				defer r.Close(testCtx)
+
+				resultFilestat := uint32(16) // arbitrary offset after the path
+				ok := mod.Memory().Write(0, []byte(pathName))
+				require.True(t, ok)
+
+				requireErrno(t, ErrnoSuccess, mod, PathFilestatGetName, uint64(sys.FdPreopen), 0, 0, uint64(len(pathName)), uint64(resultFilestat))
+
+				filetype, ok := mod.Memory().ReadByte(resultFilestat + 16)
+				require.True(t, ok)
+				require.Equal(t, FILETYPE_DIRECTORY, filetype)
+
+				mtim, ok := mod.Memory().ReadUint64Le(resultFilestat + 48)
+				require.True(t, ok)
+				require.Equal(t, tc.expectedMtim, mtim)
+			})
+		}
+	}
+}
+
+// Test_pathFilestatGet_fdFilestatGet ensures stat of a path is the same as
+// stat of a file descriptor opened from it.
+func Test_pathFilestatGet_fdFilestatGet(t *testing.T) {
+	file := "animals.txt"
+
+	tmpDir := t.TempDir()
+	writeFile(t, tmpDir, file, []byte("bear"))
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		fs   fs.FS
+	}{
+		{name: "fstest.FS", fs: fstest.FS},
+		{name: "syscallfs.DirFS", fs: dirFS},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(tc.fs))
+			defer r.Close(testCtx)
+
+			pathOffset, pathFilestat, fdFilestat := uint32(0), uint32(64), uint32(128)
+			require.True(t, mod.Memory().Write(pathOffset, []byte(file)))
+
+			requireErrno(t, ErrnoSuccess, mod, PathFilestatGetName, uint64(sys.FdPreopen), uint64(0),
+				uint64(pathOffset), uint64(len(file)), uint64(pathFilestat))
+
+			fd := requireOpenFD(t, mod, file)
+			requireErrno(t, ErrnoSuccess, mod, FdFilestatGetName, uint64(fd), uint64(fdFilestat))
+
+			expected, ok := mod.Memory().Read(pathFilestat, 64)
+			require.True(t, ok)
+			actual, ok := mod.Memory().Read(fdFilestat, 64)
+			require.True(t, ok)
+			require.Equal(t, expected, actual)
+			require.Equal(t, FILETYPE_REGULAR_FILE, actual[16])
+		})
+	}
+}
+
+// Test_fdFilestatGet_inode ensures the synthesized inode of a path is the same
+// across separate instantiations, so that test fixtures are reproducible.
+func Test_fdFilestatGet_inode(t *testing.T) {
+	file := "sub/test.txt"
+
+	var inodes []uint64
+	for i := 0; i < 2; i++ {
+		mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fstest.FS))
+
+		fd := requireOpenFD(t, mod, file)
+		requireErrno(t, ErrnoSuccess, mod, FdFilestatGetName, uint64(fd), uint64(0))
+
+		ino, ok := mod.Memory().ReadUint64Le(8)
+		require.True(t, ok)
+		inodes = append(inodes, ino)
+
+		require.NoError(t, r.Close(testCtx))
+	}
+
+	require.Equal(t, sys.SynthesizeInode(file), inodes[0])
+	require.Equal(t, inodes[0], inodes[1])
+}
+
+// Test_pathFilestatSetTimes ensures the times of a path are adjusted per
+// fst_flags, preserving a time not selected.
 func Test_pathFilestatSetTimes(t *testing.T) {
-	log := requireErrnoNosys(t, PathFilestatSetTimesName, 0, 0, 0, 0, 0, 0, 0)
-	require.Equal(t, `
---> wasi_snapshot_preview1.path_filestat_set_times(fd=0,flags=,path=,atim=0,mtim=0,fst_flags=0)
-<-- errno=ENOSYS
-`, log)
+	tmpDir := t.TempDir() // open before loop to ensure no locking problems.
+	file, dir := "file", "dir"
+	writeFile(t, tmpDir, file, []byte("wazero"))
+	mkdir(t, tmpDir, dir)
+
+	writeFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name, pathName             string
+		fd                         uint32
+		fstFlags                   uint16
+		expectedAtim, expectedMtim int64
+		expectedErrno              Errno
+		expectedLog                string
+	}{
+		{
+			name:          "file",
+			pathName:      file,
+			fd:            sys.FdPreopen,
+			fstFlags:      FSTFLAGS_ATIM | FSTFLAGS_MTIM,
+			expectedAtim:  testAtim,
+			expectedMtim:  testMtim,
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_filestat_set_times(fd=3,flags=,path=file,atim=123004000000,mtim=567008000000,fst_flags=5)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "dir preserves atim",
+			pathName:      dir,
+			fd:            sys.FdPreopen,
+			fstFlags:      FSTFLAGS_MTIM,
+			expectedAtim:  initialAtim,
+			expectedMtim:  testMtim,
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_filestat_set_times(fd=3,flags=,path=dir,atim=123004000000,mtim=567008000000,fst_flags=4)
+<== errno=ESUCCESS
+`,
+		},
+		{
+			name:          "mtim and mtim_now",
+			pathName:      file,
+			fd:            sys.FdPreopen,
+			fstFlags:      FSTFLAGS_MTIM | FSTFLAGS_MTIM_NOW,
+			expectedAtim:  initialAtim,
+			expectedMtim:  initialMtim,
+			expectedErrno: ErrnoInval,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_filestat_set_times(fd=3,flags=,path=file,atim=123004000000,mtim=567008000000,fst_flags=12)
+<== errno=EINVAL
+`,
+		},
+		{
+			name:          "undefined bit",
+			pathName:      file,
+			fd:            sys.FdPreopen,
+			fstFlags:      FSTFLAGS_MTIM_NOW << 1,
+			expectedAtim:  initialAtim,
+			expectedMtim:  initialMtim,
+			expectedErrno: ErrnoInval,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_filestat_set_times(fd=3,flags=,path=file,atim=123004000000,mtim=567008000000,fst_flags=16)
+<== errno=EINVAL
+`,
+		},
+		{
+			name:          "not exists",
+			pathName:      "noexist",
+			fd:            sys.FdPreopen,
+			fstFlags:      FSTFLAGS_ATIM,
+			expectedErrno: ErrnoNoent,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_filestat_set_times(fd=3,flags=,path=noexist,atim=123004000000,mtim=567008000000,fst_flags=1)
+<== errno=ENOENT
+`,
+		},
+		{
+			name:          "invalid fd",
+			pathName:      file,
+			fd:            42, // arbitrary invalid fd
+			fstFlags:      FSTFLAGS_ATIM,
+			expectedAtim:  initialAtim,
+			expectedMtim:  initialMtim,
+			expectedErrno: ErrnoBadf,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_filestat_set_times(fd=42,flags=,path=file,atim=123004000000,mtim=567008000000,fst_flags=1)
+<== errno=EBADF
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(writeFS))
+			defer r.Close(testCtx)
+
+			realPath := path.Join(tmpDir, tc.pathName)
+			if tc.expectedErrno != ErrnoNoent {
+				requireChtimes(t, realPath, initialAtim, initialMtim)
+			}
+
+			name := uint32(1) // arbitrary offset
+			ok := mod.Memory().Write(name, []byte(tc.pathName))
+			require.True(t, ok)
+
+			requireErrno(t, tc.expectedErrno, mod, PathFilestatSetTimesName, uint64(tc.fd), uint64(0),
+				uint64(name), uint64(len(tc.pathName)), uint64(testAtim), uint64(testMtim), uint64(tc.fstFlags))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+
+			if tc.expectedErrno != ErrnoNoent {
+				atim, mtim := requireStatTimes(t, realPath)
+				require.Equal(t, tc.expectedAtim, atim)
+				require.Equal(t, tc.expectedMtim, mtim)
+			}
+		})
+	}
 }
 
 // Test_pathLink only tests it is stubbed for GrainLang per #271
@@ -2200,6 +4349,16 @@ func Test_pathOpen(t *testing.T) {
 	fileContents := []byte("012")
 	writeFile(t, dir, fileName, fileContents)
 
+	configDir := t.TempDir()
+	configContents := []byte("debug = true")
+	writeFile(t, configDir, "app.toml", configContents)
+	remapFS := syscallfs.NewRemapFS(writeFS, func(guestPath string) (string, fs.FS, bool) {
+		if guestPath == "config/app.toml" {
+			return "app.toml", os.DirFS(configDir), true
+		}
+		return "", nil, false
+	})
+
 	appendName := "append"
 	appendContents := []byte("345")
 	writeFile(t, dir, appendName, appendContents)
@@ -2208,6 +4367,9 @@ func Test_pathOpen(t *testing.T) {
 	truncContents := []byte("678")
 	writeFile(t, dir, truncName, truncContents)
 
+	creatTruncName := "creat-trunc"
+	writeFile(t, dir, creatTruncName, []byte("9ab"))
+
 	dirName := "dir"
 	mkdir(t, dir, dirName)
 
@@ -2249,6 +4411,18 @@ func Test_pathOpen(t *testing.T) {
 			expectedLog: `
 ==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=file,oflags=,fs_rights_base=,fs_rights_inheriting=,fdflags=)
 <== (opened_fd=4,errno=ESUCCESS)
+`,
+		},
+		{
+			name: "syscallfs.RemapFS",
+			fs:   remapFS,
+			path: func(*testing.T) string { return "config/app.toml" },
+			expected: func(t *testing.T, fsc *sys.FSContext) {
+				requireContents(t, fsc, expectedOpenedFd, "config/app.toml", configContents)
+			},
+			expectedLog: `
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=config/app.toml,oflags=,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=4,errno=ESUCCESS)
 `,
 		},
 		{
@@ -2268,6 +4442,11 @@ func Test_pathOpen(t *testing.T) {
 			path:    func(t *testing.T) (file string) { return appendName },
 			fdflags: FD_APPEND,
 			expected: func(t *testing.T, fsc *sys.FSContext) {
+				// verify the fdflags were recorded for fd_fdstat_get
+				f, ok := fsc.LookupFile(expectedOpenedFd)
+				require.True(t, ok)
+				require.Equal(t, uint16(FD_APPEND), f.FdFlags)
+
 				contents := []byte("hello")
 				_, err := sys.WriterForFile(fsc, expectedOpenedFd).Write(contents)
 				require.NoError(t, err)
@@ -2286,11 +4465,11 @@ func Test_pathOpen(t *testing.T) {
 			name:          "syscallfs.ReadFS O_CREAT",
 			fs:            readFS,
 			oflags:        O_CREAT,
-			expectedErrno: ErrnoNosys,
+			expectedErrno: ErrnoNotcapable,
 			path:          func(*testing.T) string { return "creat" },
 			expectedLog: `
 ==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=creat,oflags=CREAT,fs_rights_base=,fs_rights_inheriting=,fdflags=)
-<== (opened_fd=,errno=ENOSYS)
+<== (opened_fd=,errno=ENOTCAPABLE)
 `,
 		},
 		{
@@ -2318,11 +4497,11 @@ func Test_pathOpen(t *testing.T) {
 			name:          "syscallfs.ReadFS O_CREAT O_TRUNC",
 			fs:            readFS,
 			oflags:        O_CREAT | O_TRUNC,
-			expectedErrno: ErrnoNosys,
+			expectedErrno: ErrnoNotcapable,
 			path:          func(t *testing.T) (file string) { return path.Join(dirName, "O_CREAT-O_TRUNC") },
 			expectedLog: `
 ==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=dir/O_CREAT-O_TRUNC,oflags=CREAT|TRUNC,fs_rights_base=,fs_rights_inheriting=,fdflags=)
-<== (opened_fd=,errno=ENOSYS)
+<== (opened_fd=,errno=ENOTCAPABLE)
 `,
 		},
 		{
@@ -2374,17 +4553,55 @@ func Test_pathOpen(t *testing.T) {
 			expectedLog: `
 ==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=dir,oflags=DIRECTORY,fs_rights_base=,fs_rights_inheriting=,fdflags=)
 <== (opened_fd=4,errno=ESUCCESS)
+`,
+		},
+		{
+			name: "syscallfs.DirFS trailing slash",
+			fs:   writeFS,
+			path: func(*testing.T) string { return dirName + "/" },
+			expected: func(t *testing.T, fsc *sys.FSContext) {
+				f, ok := fsc.LookupFile(expectedOpenedFd)
+				require.True(t, ok)
+				require.True(t, f.IsDir())
+			},
+			expectedLog: `
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=dir/,oflags=,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=4,errno=ESUCCESS)
+`,
+		},
+		{
+			name:          "syscallfs.DirFS trailing slash, but not a directory",
+			fs:            writeFS,
+			path:          func(*testing.T) string { return fileName + "/" },
+			expectedErrno: ErrnoNotdir,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=file/,oflags=,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=,errno=ENOTDIR)
+`,
+		},
+		{
+			name: "syscallfs.DirFS no trailing slash",
+			fs:   writeFS,
+			path: func(*testing.T) string { return dirName },
+			expected: func(t *testing.T, fsc *sys.FSContext) {
+				f, ok := fsc.LookupFile(expectedOpenedFd)
+				require.True(t, ok)
+				require.True(t, f.IsDir())
+			},
+			expectedLog: `
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=dir,oflags=,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=4,errno=ESUCCESS)
 `,
 		},
 		{
 			name:          "syscallfs.ReadFS O_TRUNC",
 			fs:            readFS,
 			oflags:        O_TRUNC,
-			expectedErrno: ErrnoNosys,
+			expectedErrno: ErrnoNotcapable,
 			path:          func(*testing.T) string { return "trunc" },
 			expectedLog: `
 ==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=trunc,oflags=TRUNC,fs_rights_base=,fs_rights_inheriting=,fdflags=)
-<== (opened_fd=,errno=ENOSYS)
+<== (opened_fd=,errno=ENOTCAPABLE)
 `,
 		},
 		{
@@ -2403,8 +4620,36 @@ func Test_pathOpen(t *testing.T) {
 				require.Equal(t, contents, b)
 			},
 			expectedLog: `
-==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=trunc,oflags=TRUNC,fs_rights_base=,fs_rights_inheriting=,fdflags=)
-<== (opened_fd=4,errno=ESUCCESS)
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=trunc,oflags=TRUNC,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=4,errno=ESUCCESS)
+`,
+		},
+		{
+			name:   "syscallfs.DirFS O_CREAT O_TRUNC existing file",
+			fs:     writeFS,
+			path:   func(t *testing.T) (file string) { return creatTruncName },
+			oflags: O_CREAT | O_TRUNC,
+			expected: func(t *testing.T, fsc *sys.FSContext) {
+				require.NoError(t, fsc.CloseFile(expectedOpenedFd))
+
+				// verify the existing contents were truncated
+				b := readFile(t, dir, creatTruncName)
+				require.Equal(t, 0, len(b))
+			},
+			expectedLog: `
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=creat-trunc,oflags=CREAT|TRUNC,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=4,errno=ESUCCESS)
+`,
+		},
+		{
+			name:          "syscallfs.DirFS O_TRUNC directory",
+			fs:            writeFS,
+			path:          func(*testing.T) string { return dirName },
+			oflags:        O_TRUNC,
+			expectedErrno: ErrnoIsdir,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=dir,oflags=TRUNC,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=,errno=EISDIR)
 `,
 		},
 	}
@@ -2445,6 +4690,347 @@ func Test_pathOpen(t *testing.T) {
 	}
 }
 
+func Test_devFS(t *testing.T) {
+	writeFS, err := syscallfs.NewDirFS(t.TempDir())
+	require.NoError(t, err)
+
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(syscallfs.NewDevFS(writeFS)))
+	defer r.Close(testCtx)
+
+	t.Run("write /dev/null", func(t *testing.T) {
+		defer log.Reset()
+
+		fd := requireOpenFD(t, mod, "dev/null")
+		iovs, resultNwritten := uint32(1), uint32(16)
+		ok := mod.Memory().Write(0, []byte{
+			'?',        // `iovs` is after this
+			9, 0, 0, 0, // = iovs[0].offset
+			6, 0, 0, 0, // = iovs[0].length
+			'w', 'a', 'z', 'e', 'r', 'o',
+		})
+		require.True(t, ok)
+
+		requireErrno(t, ErrnoSuccess, mod, FdWriteName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNwritten))
+		require.Equal(t, `
+==> wasi_snapshot_preview1.fd_write(fd=4,iovs=1,iovs_len=1)
+<== (nwritten=6,errno=ESUCCESS)
+`, "\n"+log.String())
+	})
+
+	t.Run("read /dev/zero", func(t *testing.T) {
+		defer log.Reset()
+
+		fd := requireOpenFD(t, mod, "dev/zero")
+		iovs, resultNread := uint32(1), uint32(20)
+		ok := mod.Memory().Write(0, []byte{
+			'?',        // `iovs` is after this
+			9, 0, 0, 0, // = iovs[0].offset
+			10, 0, 0, 0, // = iovs[0].length
+			1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+		})
+		require.True(t, ok)
+
+		requireErrno(t, ErrnoSuccess, mod, FdReadName, uint64(fd), uint64(iovs), uint64(1), uint64(resultNread))
+		require.Equal(t, `
+==> wasi_snapshot_preview1.fd_read(fd=5,iovs=1,iovs_len=1)
+<== (nread=10,errno=ESUCCESS)
+`, "\n"+log.String())
+
+		actual, ok := mod.Memory().Read(9, 10)
+		require.True(t, ok)
+		require.Equal(t, make([]byte, 10), actual)
+	})
+
+	t.Run("filestat /dev/zero", func(t *testing.T) {
+		defer log.Reset()
+
+		pathName := "dev/zero"
+		resultFilestat := uint32(16)
+		ok := mod.Memory().Write(0, []byte(pathName))
+		require.True(t, ok)
+
+		requireErrno(t, ErrnoSuccess, mod, PathFilestatGetName, uint64(sys.FdPreopen), uint64(0), uint64(0), uint64(len(pathName)), uint64(resultFilestat))
+
+		filetype, ok := mod.Memory().ReadByte(resultFilestat + 16)
+		require.True(t, ok)
+		require.Equal(t, FILETYPE_CHARACTER_DEVICE, filetype)
+	})
+}
+
+// Test_pathOpen_seed ensures files created by path_open with O_CREAT can be
+// seeded with initial content the guest reads back.
+func Test_pathOpen_seed(t *testing.T) {
+	dirFS, err := syscallfs.NewDirFS(t.TempDir())
+	require.NoError(t, err)
+	seedFS := syscallfs.NewSeedFS(dirFS, func(guestPath string) ([]byte, bool) {
+		if guestPath == "lock" {
+			return []byte("LOCK"), true
+		}
+		return nil, false
+	})
+
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(seedFS))
+	defer r.Close(testCtx)
+
+	tests := []struct {
+		path             string
+		expectedContents string
+	}{
+		{path: "lock", expectedContents: "LOCK"},
+		{path: "other", expectedContents: ""},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.path, func(t *testing.T) {
+			pathOffset, resultOpenedFd := uint32(0), uint32(16)
+			require.True(t, mod.Memory().Write(pathOffset, []byte(tc.path)))
+
+			requireErrno(t, ErrnoSuccess, mod, PathOpenName, uint64(sys.FdPreopen), uint64(0),
+				uint64(pathOffset), uint64(len(tc.path)), uint64(O_CREAT), 0, 0, 0, uint64(resultOpenedFd))
+			fd, ok := mod.Memory().ReadUint32Le(resultOpenedFd)
+			require.True(t, ok)
+
+			iovs, resultNread := uint32(32), uint32(48)
+			require.True(t, mod.Memory().Write(iovs, []byte{
+				64, 0, 0, 0, // = iovs[0].offset
+				8, 0, 0, 0, // = iovs[0].length
+			}))
+
+			requireErrno(t, ErrnoSuccess, mod, FdReadName, uint64(fd), uint64(iovs), 1, uint64(resultNread))
+			nread, ok := mod.Memory().ReadUint32Le(resultNread)
+			require.True(t, ok)
+
+			actual, ok := mod.Memory().Read(64, nread)
+			require.True(t, ok)
+			require.Equal(t, tc.expectedContents, string(actual))
+
+			requireErrno(t, ErrnoSuccess, mod, FdCloseName, uint64(fd))
+		})
+	}
+}
+
+// Test_pathOpen_dirFD ensures paths resolve relative to a directory opened by
+// the guest, not only the pre-open.
+func Test_pathOpen_dirFD(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFile(t, tmpDir, "file", []byte("root"))
+	mkdir(t, tmpDir, "dir")
+	writeFile(t, tmpDir, "dir/file", []byte("dir"))
+	mkdir(t, tmpDir, "dir/sub")
+	writeFile(t, tmpDir, "dir/sub/file", []byte("sub"))
+
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(dirFS))
+	defer r.Close(testCtx)
+
+	pathOpen := func(dirFD uint32, path string, oflags uint16) uint32 {
+		pathOffset, resultOpenedFd := uint32(0), uint32(16)
+		require.True(t, mod.Memory().Write(pathOffset, []byte(path)))
+
+		requireErrno(t, ErrnoSuccess, mod, PathOpenName, uint64(dirFD), uint64(0),
+			uint64(pathOffset), uint64(len(path)), uint64(oflags), 0, 0, 0, uint64(resultOpenedFd))
+		fd, ok := mod.Memory().ReadUint32Le(resultOpenedFd)
+		require.True(t, ok)
+		return fd
+	}
+
+	readAll := func(fd uint32) string {
+		iovs, resultNread := uint32(32), uint32(48)
+		require.True(t, mod.Memory().Write(iovs, []byte{
+			64, 0, 0, 0, // = iovs[0].offset
+			8, 0, 0, 0, // = iovs[0].length
+		}))
+
+		requireErrno(t, ErrnoSuccess, mod, FdReadName, uint64(fd), uint64(iovs), 1, uint64(resultNread))
+		nread, ok := mod.Memory().ReadUint32Le(resultNread)
+		require.True(t, ok)
+
+		actual, ok := mod.Memory().Read(64, nread)
+		require.True(t, ok)
+		return string(actual)
+	}
+
+	dirFD := pathOpen(sys.FdPreopen, "dir", O_DIRECTORY)
+	require.Equal(t, "dir", readAll(pathOpen(dirFD, "file", 0)))
+
+	// Nested directories resolve relative to their parent FD, too.
+	subFD := pathOpen(dirFD, "sub", O_DIRECTORY)
+	require.Equal(t, "sub", readAll(pathOpen(subFD, "file", 0)))
+
+	// The pre-open is unaffected.
+	require.Equal(t, "root", readAll(pathOpen(sys.FdPreopen, "file", 0)))
+}
+
+// Test_pathOpen_tempFile ensures temp-file names a guest derives from
+// "random_get", like mkstemp, are reproducible given a fixed rand source,
+// including the O_CREAT|O_EXCL retry on collision.
+func Test_pathOpen_tempFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	fs, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	// A fixed suffix source: each six-byte suffix repeats the next byte.
+	randSource := bytes.NewReader([]byte("aaaaaabbbbbb"))
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fs).WithRandSource(randSource))
+	defer r.Close(testCtx)
+
+	// mkstemp replaces "XXXXXX" with a random suffix, retrying on EEXIST.
+	mkstemp := func(template string) string {
+		suffix, name, resultOpenedFd := uint32(0), uint32(16), uint32(64)
+		for {
+			requireErrno(t, ErrnoSuccess, mod, RandomGetName, uint64(suffix), 6)
+			b, ok := mod.Memory().Read(suffix, 6)
+			require.True(t, ok)
+			pathName := strings.Replace(template, "XXXXXX", string(b), 1)
+			require.True(t, mod.Memory().WriteString(name, pathName))
+
+			results, err := mod.ExportedFunction(PathOpenName).Call(testCtx, uint64(sys.FdPreopen), 0,
+				uint64(name), uint64(len(pathName)), uint64(O_CREAT|O_EXCL), 0, 0, 0, uint64(resultOpenedFd))
+			require.NoError(t, err)
+			if errno := Errno(results[0]); errno == ErrnoExist {
+				continue
+			} else {
+				require.Equal(t, ErrnoSuccess, errno, ErrnoName(errno))
+			}
+			return pathName
+		}
+	}
+
+	// Collide with the first predicted name, so the guest must retry.
+	writeFile(t, tmpDir, "tmp.aaaaaa", nil)
+
+	require.Equal(t, "tmp.bbbbbb", mkstemp("tmp.XXXXXX"))
+	_, err = os.Stat(path.Join(tmpDir, "tmp.bbbbbb"))
+	require.NoError(t, err)
+}
+
+// Test_pathOpen_denied ensures path_open rejects denied flags, even when the
+// pre-open is writable.
+func Test_pathOpen_denied(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFile(t, tmpDir, "file", []byte("wazero"))
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().
+		WithFS(syscallfs.NewDenyOpenFS(dirFS, os.O_CREATE)))
+	defer r.Close(testCtx)
+
+	tests := []struct {
+		name          string
+		path          string
+		oflags        uint16
+		expectedErrno Errno
+		expectedLog   string
+	}{
+		{
+			name:          "O_CREAT denied",
+			path:          "creat",
+			oflags:        O_CREAT,
+			expectedErrno: ErrnoNotcapable,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=creat,oflags=CREAT,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=,errno=ENOTCAPABLE)
+`,
+		},
+		{
+			name:          "plain open allowed",
+			path:          "file",
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=file,oflags=,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=4,errno=ESUCCESS)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			defer log.Reset()
+
+			pathOffset, resultOpenedFd := uint32(0), uint32(16)
+			require.True(t, mod.Memory().Write(pathOffset, []byte(tc.path)))
+
+			requireErrno(t, tc.expectedErrno, mod, PathOpenName, uint64(sys.FdPreopen), uint64(0),
+				uint64(pathOffset), uint64(len(tc.path)), uint64(tc.oflags), 0, 0, 0, uint64(resultOpenedFd))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+		})
+	}
+
+	// The denied file wasn't created.
+	_, err = os.Stat(path.Join(tmpDir, "creat"))
+	require.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func Test_pathOpen_permissionDenied(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not enforced on windows")
+	} else if os.Getuid() == 0 {
+		t.Skip("root can open files regardless of permissions")
+	}
+
+	tmpDir := t.TempDir()
+	writeFile(t, tmpDir, "file", []byte("wazero"))
+	require.NoError(t, os.Chmod(path.Join(tmpDir, "file"), 0o000))
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(dirFS))
+	defer r.Close(testCtx)
+
+	pathName := "file"
+	pathOffset, resultOpenedFd := uint32(0), uint32(16)
+	require.True(t, mod.Memory().Write(pathOffset, []byte(pathName)))
+
+	requireErrno(t, ErrnoAcces, mod, PathOpenName, uint64(sys.FdPreopen), uint64(0),
+		uint64(pathOffset), uint64(len(pathName)), 0, 0, 0, 0, uint64(resultOpenedFd))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.path_open(fd=3,dirflags=,path=file,oflags=,fs_rights_base=,fs_rights_inheriting=,fdflags=)
+<== (opened_fd=,errno=EACCES)
+`, "\n"+log.String())
+}
+
+// errFS returns the same error when opening any path.
+type errFS struct{ err error }
+
+func (e errFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: e.err}
+}
+
+func Test_pathOpen_permissionErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectedErrno Errno
+	}{
+		{name: "EACCES", err: syscall.EACCES, expectedErrno: ErrnoAcces},
+		{name: "fs.ErrPermission", err: fs.ErrPermission, expectedErrno: ErrnoAcces},
+		{name: "EPERM", err: syscall.EPERM, expectedErrno: ErrnoPerm},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithFS(errFS{tc.err}))
+			defer r.Close(testCtx)
+
+			pathName := "file"
+			pathOffset, resultOpenedFd := uint32(0), uint32(16)
+			require.True(t, mod.Memory().Write(pathOffset, []byte(pathName)))
+
+			requireErrno(t, tc.expectedErrno, mod, PathOpenName, uint64(sys.FdPreopen), uint64(0),
+				uint64(pathOffset), uint64(len(pathName)), 0, 0, 0, 0, uint64(resultOpenedFd))
+		})
+	}
+}
+
 func requireOpenFD(t *testing.T, mod api.Module, path string) uint32 {
 	fsc := mod.(*wasm.CallContext).Sys.FS()
 
@@ -2840,6 +5426,113 @@ func Test_pathRename(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// Test_pathRename_dirToDir ensures renaming a directory onto another is only
+// allowed when the target is empty.
+func Test_pathRename_dirToDir(t *testing.T) {
+	tests := []struct {
+		name          string
+		targetFile    string
+		expectedErrno Errno
+		expectedLog   string
+	}{
+		{
+			name:          "target not empty",
+			targetFile:    "file",
+			expectedErrno: ErrnoNotempty,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_rename(fd=3,old_path=source,new_fd=3,new_path=target)
+<== errno=ENOTEMPTY
+`,
+		},
+		{
+			name:          "target empty",
+			expectedErrno: ErrnoSuccess,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_rename(fd=3,old_path=source,new_fd=3,new_path=target)
+<== errno=ESUCCESS
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.expectedErrno == ErrnoSuccess && runtime.GOOS == "windows" {
+				t.Skip("windows doesn't let you overwrite an existing directory")
+			}
+
+			tmpDir := t.TempDir()
+			mkdir(t, tmpDir, "source")
+			mkdir(t, tmpDir, "target")
+			if tc.targetFile != "" {
+				writeFile(t, tmpDir, path.Join("target", tc.targetFile), []byte("wazero"))
+			}
+
+			fs, err := syscallfs.NewDirFS(tmpDir)
+			require.NoError(t, err)
+
+			mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fs))
+			defer r.Close(testCtx)
+
+			oldPath, newPath := uint32(0), uint32(16)
+			require.True(t, mod.Memory().Write(oldPath, []byte("source")))
+			require.True(t, mod.Memory().Write(newPath, []byte("target")))
+
+			requireErrno(t, tc.expectedErrno, mod, PathRenameName,
+				uint64(sys.FdPreopen), uint64(oldPath), uint64(len("source")),
+				uint64(sys.FdPreopen), uint64(newPath), uint64(len("target")))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+		})
+	}
+}
+
+// Test_pathRename_intoDescendant ensures a directory can't be renamed into
+// itself, and that the tree is left intact.
+func Test_pathRename_intoDescendant(t *testing.T) {
+	tmpDir := t.TempDir()
+	mkdir(t, tmpDir, "a")
+	mkdir(t, tmpDir, "a/b")
+	writeFile(t, tmpDir, "a/b/file", []byte("wazero"))
+
+	fs, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(fs))
+	defer r.Close(testCtx)
+
+	for _, newPathName := range []string{"a/b", "a/b/c", "a/c"} {
+		oldPath, newPath := uint32(0), uint32(16)
+		require.True(t, mod.Memory().WriteString(oldPath, "a"))
+		require.True(t, mod.Memory().WriteString(newPath, newPathName))
+
+		requireErrno(t, ErrnoInval, mod, PathRenameName,
+			uint64(sys.FdPreopen), uint64(oldPath), uint64(len("a")),
+			uint64(sys.FdPreopen), uint64(newPath), uint64(len(newPathName)))
+	}
+	require.Equal(t, `
+==> wasi_snapshot_preview1.path_rename(fd=3,old_path=a,new_fd=3,new_path=a/b)
+<== errno=EINVAL
+==> wasi_snapshot_preview1.path_rename(fd=3,old_path=a,new_fd=3,new_path=a/b/c)
+<== errno=EINVAL
+==> wasi_snapshot_preview1.path_rename(fd=3,old_path=a,new_fd=3,new_path=a/c)
+<== errno=EINVAL
+`, "\n"+log.String())
+
+	// The tree is unchanged.
+	require.Equal(t, "wazero", string(readFile(t, tmpDir, "a/b/file")))
+	_, err = os.Stat(path.Join(tmpDir, "a", "c"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// A sibling with the same prefix isn't a descendant.
+	oldPath, newPath := uint32(0), uint32(16)
+	require.True(t, mod.Memory().WriteString(oldPath, "a"))
+	require.True(t, mod.Memory().WriteString(newPath, "ab"))
+	requireErrno(t, ErrnoSuccess, mod, PathRenameName,
+		uint64(sys.FdPreopen), uint64(oldPath), uint64(len("a")),
+		uint64(sys.FdPreopen), uint64(newPath), uint64(len("ab")))
+}
+
 func Test_pathRename_Errors(t *testing.T) {
 	tmpDir := t.TempDir() // open before loop to ensure no locking problems.
 	fs, err := syscallfs.NewDirFS(tmpDir)
@@ -3049,6 +5742,65 @@ func Test_pathUnlinkFile(t *testing.T) {
 	require.Error(t, err)
 }
 
+// unlinkEpermFS is a syscallfs.FS which returns EPERM when unlinking, as some
+// platforms do for directories.
+type unlinkEpermFS struct{ syscallfs.FS }
+
+func (unlinkEpermFS) Unlink(name string) error {
+	return &fs.PathError{Op: "unlink", Path: name, Err: syscall.EPERM}
+}
+
+func Test_pathUnlinkFile_eperm(t *testing.T) {
+	tmpDir := t.TempDir() // open before loop to ensure no locking problems.
+	dirFS, err := syscallfs.NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithFS(unlinkEpermFS{dirFS}))
+	defer r.Close(testCtx)
+
+	dir := "dir"
+	mkdir(t, tmpDir, dir)
+	file := "file"
+	writeFile(t, tmpDir, file, []byte{})
+
+	tests := []struct {
+		name, pathName string
+		expectedErrno  Errno
+		expectedLog    string
+	}{
+		{
+			name:          "dir",
+			pathName:      dir,
+			expectedErrno: ErrnoIsdir,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_unlink_file(fd=3,path=dir)
+<== errno=EISDIR
+`,
+		},
+		{
+			name:          "file",
+			pathName:      file,
+			expectedErrno: ErrnoPerm,
+			expectedLog: `
+==> wasi_snapshot_preview1.path_unlink_file(fd=3,path=file)
+<== errno=EPERM
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			defer log.Reset()
+
+			mod.Memory().Write(0, []byte(tc.pathName))
+
+			requireErrno(t, tc.expectedErrno, mod, PathUnlinkFileName, uint64(sys.FdPreopen), 0, uint64(len(tc.pathName)))
+			require.Equal(t, tc.expectedLog, "\n"+log.String())
+		})
+	}
+}
+
 func Test_pathUnlinkFile_Errors(t *testing.T) {
 	tmpDir := t.TempDir() // open before loop to ensure no locking problems.
 	fs, err := syscallfs.NewDirFS(tmpDir)
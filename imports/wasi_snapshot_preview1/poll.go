@@ -2,6 +2,7 @@ package wasi_snapshot_preview1
 
 import (
 	"context"
+	"time"
 
 	"github.com/tetratelabs/wazero/api"
 	internalsys "github.com/tetratelabs/wazero/internal/sys"
@@ -16,7 +17,8 @@ import (
 //
 //   - in: pointer to the subscriptions (48 bytes each)
 //   - out: pointer to the resulting events (32 bytes each)
-//   - nsubscriptions: count of subscriptions, zero returns ErrnoInval.
+//   - nsubscriptions: count of subscriptions, zero or more than
+//     pollSubscriptionsMax returns ErrnoInval.
 //   - resultNevents: count of events.
 //
 // Result (Errno)
@@ -26,12 +28,17 @@ import (
 //   - ErrnoNotsup: a parameters is valid, but not yet supported.
 //   - ErrnoFault: there is not enough memory to read the subscriptions or
 //     write results.
+//   - ErrnoIntr: the context was done while sleeping on a clock event.
 //
 // # Notes
 //
-//   - Since the `out` pointer nests Errno, the result is always ErrnoSuccess.
+//   - Since the `out` pointer nests Errno, the result is otherwise
+//     ErrnoSuccess.
 //   - importPollOneoff shows this signature in the WebAssembly 1.0 Text Format.
 //   - This is similar to `poll` in POSIX.
+//   - Events are written in a deterministic order: those without an error
+//     first, then the rest, each in the order of their subscription. Match
+//     an event to its subscription by `userdata`.
 //
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#poll_oneoff
 // See https://linux.die.net/man/3/poll
@@ -41,19 +48,34 @@ var pollOneoff = newHostFunc(
 	"in", "out", "nsubscriptions", "result.nevents",
 )
 
+// pollSubscriptionsMax is the default maximum count of subscriptions accepted
+// by poll_oneoff. This bounds the memory allocated to process them.
+//
+// See wazero.ModuleConfig WithPollSubscriptionLimit
+const pollSubscriptionsMax = 1024
+
 func pollOneoffFn(ctx context.Context, mod api.Module, params []uint64) Errno {
 	in := uint32(params[0])
 	out := uint32(params[1])
 	nsubscriptions := uint32(params[2])
 	resultNevents := uint32(params[3])
 
-	if nsubscriptions == 0 {
+	limit := uint32(pollSubscriptionsMax)
+	if l := mod.(*wasm.CallContext).Sys.PollSubscriptionLimit; l > 0 {
+		limit = l
+	}
+	if nsubscriptions == 0 || nsubscriptions > limit {
 		return ErrnoInval
 	}
 
 	mem := mod.Memory()
 
-	// Ensure capacity prior to the read loop to reduce error handling.
+	// Ensure capacity prior to the read loop to reduce error handling. Check
+	// the sizes without overflow, as the limit may be large.
+	memSize := uint64(mem.Size())
+	if uint64(nsubscriptions)*48 > memSize || uint64(nsubscriptions)*32 > memSize {
+		return ErrnoFault
+	}
 	inBuf, ok := mem.Read(in, nsubscriptions*48)
 	if !ok {
 		return ErrnoFault
@@ -69,10 +91,11 @@ func pollOneoffFn(ctx context.Context, mod api.Module, params []uint64) Errno {
 		return ErrnoFault
 	}
 
-	// Loop through all subscriptions and write their output.
+	// Process all subscriptions before writing any events, so that they can
+	// be written in a deterministic order.
+	errnos := make([]Errno, nsubscriptions) // errno for each subscription
 	for i := uint32(0); i < nsubscriptions; i++ {
 		inOffset := i * 48
-		outOffset := i * 32
 
 		eventType := inBuf[inOffset+8] // +8 past userdata
 		var errno Errno                // errno for this specific event
@@ -80,28 +103,53 @@ func pollOneoffFn(ctx context.Context, mod api.Module, params []uint64) Errno {
 		case EventTypeClock: // handle later
 			// +8 past userdata +8 name alignment
 			errno = processClockEvent(ctx, mod, inBuf[inOffset+8+8:])
+			if errno == ErrnoIntr {
+				return errno // Like POSIX poll, the call itself is interrupted.
+			}
 		case EventTypeFdRead, EventTypeFdWrite:
-			// +8 past userdata +4 FD alignment
-			errno = processFDEvent(mod, eventType, inBuf[inOffset+8+4:])
+			// +8 past userdata +8 FD alignment
+			errno = processFDEvent(mod, eventType, inBuf[inOffset+8+8:])
 		default:
 			return ErrnoInval
 		}
+		errnos[i] = errno
+	}
 
-		// Write the event corresponding to the processed subscription.
-		// https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-event-struct
-		copy(outBuf, inBuf[inOffset:inOffset+8]) // userdata
-		outBuf[outOffset+8] = byte(errno)        // uint16, but safe as < 255
-		outBuf[outOffset+9] = 0
-		le.PutUint32(outBuf[outOffset+10:], uint32(eventType))
-		// TODO: When FD events are supported, write outOffset+16
+	// Write ready events first, then the others, each in subscription order.
+	outOffset := uint32(0)
+	for _, ready := range [...]bool{true, false} {
+		for i, errno := range errnos {
+			if (errno == ErrnoSuccess) != ready {
+				continue
+			}
+			writeEvent(outBuf[outOffset:outOffset+32], inBuf[uint32(i)*48:], errno)
+			outOffset += 32
+		}
 	}
 	return ErrnoSuccess
 }
 
+// writeEvent writes the event corresponding to the processed subscription.
+//
+// See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-event-struct
+func writeEvent(outBuf, inBuf []byte, errno Errno) {
+	eventType := inBuf[8]
+	copy(outBuf, inBuf[0:8]) // userdata
+	outBuf[8] = byte(errno)  // uint16, but safe as < 255
+	outBuf[9] = 0
+	le.PutUint32(outBuf[10:], uint32(eventType))
+	// fd_readwrite is zero as the count of bytes available isn't known.
+	for i := 16; i < 32; i++ {
+		outBuf[i] = 0
+	}
+}
+
 // processClockEvent supports only relative name events, as that's what's used
 // to implement sleep in various compilers including Rust, Zig and TinyGo.
-func processClockEvent(_ context.Context, mod api.Module, inBuf []byte) Errno {
-	_ /* ID */ = le.Uint32(inBuf[0:8])          // See below
+//
+// The sleep ends early with ErrnoIntr if the context is done first.
+func processClockEvent(ctx context.Context, mod api.Module, inBuf []byte) Errno {
+	id := le.Uint32(inBuf[0:8])                 // See below
 	timeout := le.Uint64(inBuf[8:16])           // nanos if relative
 	_ /* precision */ = le.Uint64(inBuf[16:24]) // Unused
 	flags := le.Uint16(inBuf[24:32])
@@ -117,15 +165,53 @@ func processClockEvent(_ context.Context, mod api.Module, inBuf []byte) Errno {
 
 	// https://linux.die.net/man/3/clock_settime says relative timers are
 	// unaffected. Since this function only supports relative timeout, we can
-	// skip name ID validation and use a single sleep function.
-
+	// skip name ID validation and use a single sleep function. The exception
+	// is a clock left at its default with RuntimeConfig.WithStrictClocks.
 	sysCtx := mod.(*wasm.CallContext).Sys
-	sysCtx.Nanosleep(int64(timeout))
-	return ErrnoSuccess
+	if (id == ClockIDRealtime && !sysCtx.WalltimeSupported()) ||
+		(id == ClockIDMonotonic && !sysCtx.NanotimeSupported()) {
+		return ErrnoNotsup
+	}
+
+	done := ctx.Done()
+	if done == nil { // the context can never be done, e.g. context.Background
+		sysCtx.Nanosleep(int64(timeout))
+		return ErrnoSuccess
+	}
+
+	// time.Sleep cannot be interrupted, so use a timer which can be stopped.
+	if sysCtx.SysNanosleep {
+		timer := time.NewTimer(time.Duration(timeout))
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			return ErrnoSuccess
+		case <-done:
+			return ErrnoIntr
+		}
+	}
+
+	// A custom sys.Nanosleep cannot be interrupted, so sleep in another
+	// goroutine. If the context is done first, that goroutine finishes in the
+	// background.
+	slept := make(chan struct{})
+	go func() {
+		sysCtx.Nanosleep(int64(timeout))
+		close(slept)
+	}()
+
+	select {
+	case <-slept:
+		return ErrnoSuccess
+	case <-done:
+		return ErrnoIntr
+	}
 }
 
 // processFDEvent returns a validation error or ErrnoNotsup as file or socket
-// subscriptions are not yet supported.
+// subscriptions are not yet supported. The exception is reading stdin, which
+// is always ready, as fd_read blocks until data is available.
 func processFDEvent(mod api.Module, eventType byte, inBuf []byte) Errno {
 	fd := le.Uint32(inBuf)
 	fsc := mod.(*wasm.CallContext).Sys.FS()
@@ -136,6 +222,8 @@ func processFDEvent(mod api.Module, eventType byte, inBuf []byte) Errno {
 	if eventType == EventTypeFdRead {
 		if _, ok := fsc.LookupFile(fd); !ok {
 			errno = ErrnoBadf
+		} else if fd == internalsys.FdStdin {
+			errno = ErrnoSuccess
 		}
 	} else if eventType == EventTypeFdWrite && internalsys.WriterForFile(fsc, fd) == nil {
 		errno = ErrnoBadf
@@ -1,7 +1,12 @@
 package wasi_snapshot_preview1_test
 
 import (
+	"context"
+	"encoding/binary"
+	"math"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/internal/sys"
@@ -55,6 +60,173 @@ func Test_pollOneoff(t *testing.T) {
 	require.Equal(t, nsubscriptions, nevents)
 }
 
+// Test_pollOneoff_sleep ensures a relative clock subscription sleeps at least
+// the requested duration against the real clock, as used by Rust's
+// thread::sleep on wasip1.
+func Test_pollOneoff_sleep(t *testing.T) {
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithSysNanotime().WithSysNanosleep())
+	defer r.Close(testCtx)
+
+	timeout := 20 * time.Millisecond
+
+	in := make([]byte, 48)
+	copy(in, []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07}) // userdata
+	in[8] = EventTypeClock
+	in[16] = ClockIDMonotonic
+	binary.LittleEndian.PutUint64(in[24:], uint64(timeout)) // timeout (ns)
+	// precision and flags (relative) are zero
+
+	out := uint32(128)           // past in
+	resultNevents := uint32(512) // past out
+	require.True(t, mod.Memory().Write(0, in))
+
+	start := time.Now()
+	requireErrno(t, ErrnoSuccess, mod, PollOneoffName, uint64(0), uint64(out), uint64(1), uint64(resultNevents))
+	elapsed := time.Since(start)
+
+	require.True(t, elapsed >= timeout, "slept %s, which is less than %s", elapsed, timeout)
+	// Allow generous jitter as CI hosts can be slow.
+	require.True(t, elapsed < timeout+500*time.Millisecond, "slept %s, which is too long", elapsed)
+
+	errno, ok := mod.Memory().ReadByte(out + 8)
+	require.True(t, ok)
+	require.Equal(t, byte(ErrnoSuccess), errno)
+}
+
+// Test_pollOneoff_sleepCancel ensures a sleep ends early with EINTR when the
+// context is cancelled, without leaving a goroutine sleeping.
+func Test_pollOneoff_sleepCancel(t *testing.T) {
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithSysNanotime().WithSysNanosleep())
+	defer r.Close(testCtx)
+
+	timeout, cancelAfter := 5*time.Second, 50*time.Millisecond
+
+	in := make([]byte, 48)
+	in[8] = EventTypeClock
+	in[16] = ClockIDMonotonic
+	binary.LittleEndian.PutUint64(in[24:], uint64(timeout)) // timeout (ns)
+	// precision and flags (relative) are zero
+
+	out := uint32(128)           // past in
+	resultNevents := uint32(512) // past out
+	require.True(t, mod.Memory().Write(0, in))
+
+	goroutines := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(testCtx)
+	defer cancel()
+	time.AfterFunc(cancelAfter, cancel)
+
+	start := time.Now()
+	results, err := mod.ExportedFunction(PollOneoffName).Call(ctx, uint64(0), uint64(out), uint64(1), uint64(resultNevents))
+	elapsed := time.Since(start)
+	require.NoError(t, err)
+	require.Equal(t, uint64(ErrnoIntr), results[0])
+
+	require.True(t, elapsed >= cancelAfter, "returned after %s, before cancellation", elapsed)
+	// Allow generous jitter as CI hosts can be slow.
+	require.True(t, elapsed < cancelAfter+500*time.Millisecond, "returned after %s, which is too long", elapsed)
+
+	// Wait for the time.AfterFunc goroutine to exit.
+	for i := 0; i < 50 && runtime.NumGoroutine() > goroutines; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Equal(t, goroutines, runtime.NumGoroutine())
+}
+
+// Test_pollOneoff_strictClocks ensures clock subscriptions are unsupported for
+// clocks left at their defaults, per RuntimeConfig.WithStrictClocks.
+func Test_pollOneoff_strictClocks(t *testing.T) {
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig().WithSysNanotime())
+	defer r.Close(testCtx)
+	mod.(*wasm.CallContext).Sys.StrictClocks = true
+
+	tests := []struct {
+		name          string
+		clockID       byte
+		expectedErrno Errno
+	}{
+		{name: "realtime unconfigured", clockID: ClockIDRealtime, expectedErrno: ErrnoNotsup},
+		{name: "monotonic configured", clockID: ClockIDMonotonic, expectedErrno: ErrnoSuccess},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			in := make([]byte, 48)
+			in[8] = EventTypeClock
+			in[16] = tc.clockID
+			// timeout, precision and flags (relative) are zero
+
+			out := uint32(128)           // past in
+			resultNevents := uint32(512) // past out
+			require.True(t, mod.Memory().Write(0, in))
+
+			requireErrno(t, ErrnoSuccess, mod, PollOneoffName, uint64(0), uint64(out), uint64(1), uint64(resultNevents))
+
+			errno, ok := mod.Memory().ReadByte(out + 8)
+			require.True(t, ok)
+			require.Equal(t, byte(tc.expectedErrno), errno)
+		})
+	}
+}
+
+// Test_pollOneoff_order ensures events are written for each subscription, with
+// the ready ones first, each in subscription order.
+func Test_pollOneoff_order(t *testing.T) {
+	mod, r, _ := requireProxyModule(t, wazero.NewModuleConfig())
+	defer r.Close(testCtx)
+
+	in := make([]byte, 3*48)
+	// subscription 0 reads an invalid FD, so it isn't ready.
+	binary.LittleEndian.PutUint64(in[0:], 100) // userdata
+	in[8] = EventTypeFdRead
+	in[16] = 42 // arbitrary invalid FD
+	// subscription 1 is a timer, which fires as sleep returns immediately.
+	binary.LittleEndian.PutUint64(in[48:], 101) // userdata
+	in[48+8] = EventTypeClock
+	in[48+16] = ClockIDMonotonic
+	binary.LittleEndian.PutUint64(in[48+24:], 1) // timeout (ns)
+	// subscription 2 reads stdin, which is always ready.
+	binary.LittleEndian.PutUint64(in[96:], 102) // userdata
+	in[96+8] = EventTypeFdRead
+	in[96+16] = byte(sys.FdStdin)
+
+	out := uint32(256)           // past in
+	resultNevents := uint32(512) // past out
+	maskMemory(t, mod, 1024)
+	require.True(t, mod.Memory().Write(0, in))
+
+	requireErrno(t, ErrnoSuccess, mod, PollOneoffName, uint64(0), uint64(out), uint64(3), uint64(resultNevents))
+
+	nevents, ok := mod.Memory().ReadUint32Le(resultNevents)
+	require.True(t, ok)
+	require.Equal(t, uint32(3), nevents)
+
+	events, ok := mod.Memory().Read(out, nevents*32)
+	require.True(t, ok)
+
+	type event struct {
+		userdata  uint64
+		errno     Errno
+		eventType byte
+	}
+	var actual []event
+	for i := uint32(0); i < nevents; i++ {
+		e := events[i*32:]
+		actual = append(actual, event{
+			userdata:  binary.LittleEndian.Uint64(e),
+			errno:     Errno(binary.LittleEndian.Uint16(e[8:])),
+			eventType: e[10],
+		})
+	}
+	require.Equal(t, []event{
+		{userdata: 101, errno: ErrnoSuccess, eventType: EventTypeClock},
+		{userdata: 102, errno: ErrnoSuccess, eventType: EventTypeFdRead},
+		{userdata: 100, errno: ErrnoBadf, eventType: EventTypeFdRead},
+	}, actual)
+}
+
 func Test_pollOneoff_Errors(t *testing.T) {
 	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig())
 	defer r.Close(testCtx)
@@ -108,6 +280,17 @@ func Test_pollOneoff_Errors(t *testing.T) {
 			expectedLog: `
 ==> wasi_snapshot_preview1.poll_oneoff(in=0,out=128,nsubscriptions=0,result.nevents=512)
 <== errno=EINVAL
+`,
+		},
+		{
+			name:           "nsubscriptions over limit",
+			nsubscriptions: 1025,
+			out:            128, // past in
+			resultNevents:  512, // past out
+			expectedErrno:  ErrnoInval,
+			expectedLog: `
+==> wasi_snapshot_preview1.poll_oneoff(in=0,out=128,nsubscriptions=1025,result.nevents=512)
+<== errno=EINVAL
 `,
 		},
 		{
@@ -115,8 +298,8 @@ func Test_pollOneoff_Errors(t *testing.T) {
 			nsubscriptions: 1,
 			mem: []byte{
 				0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, // userdata
-				EventTypeFdRead, 0x0, 0x0, 0x0,
-				byte(sys.FdStdin), 0x0, 0x0, 0x0, // valid readable FD
+				EventTypeFdRead, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, // event type and padding
+				byte(sys.FdStdout), 0x0, 0x0, 0x0, // valid FD, but not stdin
 				'?', // stopped after encoding
 			},
 			expectedErrno: ErrnoSuccess,
@@ -163,3 +346,36 @@ func Test_pollOneoff_Errors(t *testing.T) {
 		})
 	}
 }
+
+// Test_pollOneoff_limit ensures a subscription count whose size overflows
+// uint32 fails before allocating memory to process it.
+func Test_pollOneoff_limit(t *testing.T) {
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithPollSubscriptionLimit(math.MaxUint32))
+	defer r.Close(testCtx)
+
+	// 48 times this wraps to 32 in uint32, which would otherwise pass bounds
+	// checks, then allocate hundreds of megabytes.
+	nsubscriptions := uint32(0x05555556)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	requireErrno(t, ErrnoFault, mod, PollOneoffName, 0, 128, uint64(nsubscriptions), 512)
+	runtime.ReadMemStats(&after)
+
+	require.True(t, after.TotalAlloc-before.TotalAlloc < 1<<20, "allocated %d bytes", after.TotalAlloc-before.TotalAlloc)
+	require.Equal(t, `
+==> wasi_snapshot_preview1.poll_oneoff(in=0,out=128,nsubscriptions=89478486,result.nevents=512)
+<== errno=EFAULT
+`, "\n"+log.String())
+}
+
+func Test_pollOneoff_configuredLimit(t *testing.T) {
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithPollSubscriptionLimit(1))
+	defer r.Close(testCtx)
+
+	requireErrno(t, ErrnoInval, mod, PollOneoffName, 0, 128, 2, 512)
+	require.Equal(t, `
+==> wasi_snapshot_preview1.poll_oneoff(in=0,out=128,nsubscriptions=2,result.nevents=512)
+<== errno=EINVAL
+`, "\n"+log.String())
+}
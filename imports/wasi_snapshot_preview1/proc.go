@@ -17,6 +17,10 @@ import (
 //
 //   - exitCode: exit code.
 //
+// Note: The full uint32 exit code is preserved in sys.ExitError ExitCode,
+// even though POSIX exit codes are 8-bit. Use sys.ExitError ExitCodeByte for
+// the value a shell would observe, e.g. exit(256) is zero.
+//
 // See https://github.com/WebAssembly/WASI/blob/main/phases/snapshot/docs.md#proc_exit
 var procExit = &wasm.HostFunc{
 	ExportNames: []string{ProcExitName},
@@ -1,11 +1,16 @@
 package wasi_snapshot_preview1_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 	"github.com/tetratelabs/wazero/internal/testing/require"
 	. "github.com/tetratelabs/wazero/internal/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/internal/wasm"
+	"github.com/tetratelabs/wazero/internal/wasm/binary"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
 	"github.com/tetratelabs/wazero/sys"
 )
 
@@ -14,9 +19,10 @@ func Test_procExit(t *testing.T) {
 	defer r.Close(testCtx)
 
 	tests := []struct {
-		name        string
-		exitCode    uint32
-		expectedLog string
+		name             string
+		exitCode         uint32
+		expectedExitByte byte
+		expectedLog      string
 	}{
 		{
 			name:     "success (exitcode 0)",
@@ -26,10 +32,27 @@ func Test_procExit(t *testing.T) {
 `,
 		},
 		{
-			name:     "arbitrary non-zero exitcode",
-			exitCode: 42,
+			name:             "arbitrary non-zero exitcode",
+			exitCode:         42,
+			expectedExitByte: 42,
 			expectedLog: `
 ==> wasi_snapshot_preview1.proc_exit(rval=42)
+`,
+		},
+		{
+			name:             "exitcode 1",
+			exitCode:         1,
+			expectedExitByte: 1,
+			expectedLog: `
+==> wasi_snapshot_preview1.proc_exit(rval=1)
+`,
+		},
+		{
+			name:             "exitcode larger than 8 bits",
+			exitCode:         256,
+			expectedExitByte: 0,
+			expectedLog: `
+==> wasi_snapshot_preview1.proc_exit(rval=256)
 `,
 		},
 	}
@@ -46,11 +69,65 @@ func Test_procExit(t *testing.T) {
 			sysErr, ok := err.(*sys.ExitError)
 			require.True(t, ok, err)
 			require.Equal(t, tc.exitCode, sysErr.ExitCode())
+			require.Equal(t, tc.expectedExitByte, sysErr.ExitCodeByte())
 			require.Equal(t, tc.expectedLog, "\n"+log.String())
 		})
 	}
 }
 
+// Test_procExit_start ensures instantiation returns the same errors as
+// api.Function Call when "_start" exits or traps.
+func Test_procExit_start(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  []byte
+		check func(t *testing.T, err error)
+	}{
+		{
+			name: "proc_exit(3)",
+			body: []byte{wasm.OpcodeI32Const, 3, wasm.OpcodeCall, 0, wasm.OpcodeEnd},
+			check: func(t *testing.T, err error) {
+				var exitErr *sys.ExitError
+				require.True(t, errors.As(err, &exitErr), err)
+				require.Equal(t, uint32(3), exitErr.ExitCode())
+			},
+		},
+		{
+			name: "trap",
+			body: []byte{wasm.OpcodeUnreachable, wasm.OpcodeEnd},
+			check: func(t *testing.T, err error) {
+				var exitErr *sys.ExitError
+				require.False(t, errors.As(err, &exitErr), err)
+				require.ErrorIs(t, err, wasmruntime.ErrRuntimeUnreachable)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			r := wazero.NewRuntime(testCtx)
+			defer r.Close(testCtx)
+			wasi_snapshot_preview1.MustInstantiate(testCtx, r)
+
+			bin := binary.EncodeModule(&wasm.Module{
+				TypeSection: []*wasm.FunctionType{{Params: []wasm.ValueType{wasm.ValueTypeI32}}, {}},
+				ImportSection: []*wasm.Import{
+					{Module: wasi_snapshot_preview1.ModuleName, Name: ProcExitName, Type: wasm.ExternTypeFunc, DescFunc: 0},
+				},
+				FunctionSection: []wasm.Index{1},
+				CodeSection:     []*wasm.Code{{Body: tc.body}},
+				ExportSection:   []*wasm.Export{{Name: "_start", Type: wasm.ExternTypeFunc, Index: 1}},
+			})
+
+			_, err := r.InstantiateModuleFromBinary(testCtx, bin)
+			require.Error(t, err)
+			tc.check(t, err)
+		})
+	}
+}
+
 // Test_procRaise only tests it is stubbed for GrainLang per #271
 func Test_procRaise(t *testing.T) {
 	log := requireErrnoNosys(t, ProcRaiseName, 0)
@@ -2,7 +2,9 @@ package wasi_snapshot_preview1
 
 import (
 	"context"
+	"errors"
 	"io"
+	"syscall"
 
 	"github.com/tetratelabs/wazero/api"
 	. "github.com/tetratelabs/wazero/internal/wasi_snapshot_preview1"
@@ -21,6 +23,8 @@ import (
 //
 // The return value is ErrnoSuccess except the following error conditions:
 //   - ErrnoFault: `buf` or `bufLen` point to an offset out of memory
+//   - ErrnoAgain: the random source returned syscall.EAGAIN, e.g. to emulate
+//     GRND_NONBLOCK when there isn't enough entropy. The guest should retry.
 //   - ErrnoIo: a file system error
 //
 // For example, if underlying random source was seeded like
@@ -32,6 +36,9 @@ import (
 //	[]byte{?, 0x53, 0x8c, 0x7f, 0x96, 0xb1, ?}
 //	    buf --^
 //
+// Note: When `bufLen` is zero, this succeeds without reading the random source
+// or checking `buf`.
+//
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-random_getbuf-pointeru8-bufLen-size---errno
 var randomGet = newHostFunc(RandomGetName, randomGetFn, []api.ValueType{i32, i32}, "buf", "buf_len")
 
@@ -40,13 +47,20 @@ func randomGetFn(_ context.Context, mod api.Module, params []uint64) Errno {
 	randSource := sysCtx.RandSource()
 	buf, bufLen := uint32(params[0]), uint32(params[1])
 
+	// Don't touch memory or the source when there is nothing to read.
+	if bufLen == 0 {
+		return ErrnoSuccess
+	}
+
 	randomBytes, ok := mod.Memory().Read(buf, bufLen)
 	if !ok { // out-of-range
 		return ErrnoFault
 	}
 
 	// We can ignore the returned n as it only != byteCount on error
-	if _, err := io.ReadAtLeast(randSource, randomBytes, int(bufLen)); err != nil {
+	if _, err := io.ReadAtLeast(randSource, randomBytes, int(bufLen)); errors.Is(err, syscall.EAGAIN) {
+		return ErrnoAgain
+	} else if err != nil {
 		return ErrnoIo
 	}
 
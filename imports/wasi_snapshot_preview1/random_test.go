@@ -2,12 +2,16 @@ package wasi_snapshot_preview1_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
+	"syscall"
 	"testing"
 	"testing/iotest"
 
 	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
 	"github.com/tetratelabs/wazero/internal/testing/require"
 	. "github.com/tetratelabs/wazero/internal/wasi_snapshot_preview1"
 )
@@ -39,6 +43,39 @@ func Test_randomGet(t *testing.T) {
 	require.Equal(t, expectedMemory, actual)
 }
 
+// countingReader counts calls to Read.
+type countingReader struct{ reads int }
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.reads++
+	return len(p), nil
+}
+
+// Test_randomGet_zeroLength ensures a zero-length request succeeds without
+// touching memory or the random source, even at the end of memory.
+func Test_randomGet_zeroLength(t *testing.T) {
+	randSource := &countingReader{}
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().WithRandSource(randSource))
+	defer r.Close(testCtx)
+
+	memorySize := mod.Memory().Size()
+	maskMemory(t, mod, int(memorySize))
+	before, ok := mod.Memory().Read(0, memorySize)
+	require.True(t, ok)
+	before = append([]byte{}, before...)
+
+	requireErrno(t, ErrnoSuccess, mod, RandomGetName, uint64(memorySize), 0)
+	require.Equal(t, `
+==> wasi_snapshot_preview1.random_get(buf=65536,buf_len=0)
+<== errno=ESUCCESS
+`, "\n"+log.String())
+
+	after, ok := mod.Memory().Read(0, memorySize)
+	require.True(t, ok)
+	require.Equal(t, before, after)
+	require.Zero(t, randSource.reads)
+}
+
 func Test_randomGet_Errors(t *testing.T) {
 	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig())
 	defer r.Close(testCtx)
@@ -118,3 +155,84 @@ func Test_randomGet_SourceError(t *testing.T) {
 		})
 	}
 }
+
+// wouldBlockReader returns syscall.EAGAIN the first time it is read, and
+// fills the buffer with ones after that.
+type wouldBlockReader struct{ blocked bool }
+
+func (r *wouldBlockReader) Read(p []byte) (int, error) {
+	if !r.blocked {
+		r.blocked = true
+		return 0, syscall.EAGAIN
+	}
+	for i := range p {
+		p[i] = 1
+	}
+	return len(p), nil
+}
+
+// Test_randomGet_Again ensures a random source that would block results in
+// ErrnoAgain, so that the guest can retry.
+func Test_randomGet_Again(t *testing.T) {
+	mod, r, log := requireProxyModule(t, wazero.NewModuleConfig().
+		WithRandSource(&wouldBlockReader{}))
+	defer r.Close(testCtx)
+
+	offset, length := uint32(1), uint32(5) // arbitrary offset and length
+
+	// The first call would block.
+	requireErrno(t, ErrnoAgain, mod, RandomGetName, uint64(offset), uint64(length))
+
+	// The guest retries, which succeeds.
+	requireErrno(t, ErrnoSuccess, mod, RandomGetName, uint64(offset), uint64(length))
+	require.Equal(t, `
+==> wasi_snapshot_preview1.random_get(buf=1,buf_len=5)
+<== errno=EAGAIN
+==> wasi_snapshot_preview1.random_get(buf=1,buf_len=5)
+<== errno=ESUCCESS
+`, "\n"+log.String())
+
+	actual, ok := mod.Memory().Read(offset, length)
+	require.True(t, ok)
+	require.Equal(t, []byte{1, 1, 1, 1, 1}, actual)
+}
+
+// Test_randomGet_interceptor ensures an experimental.WASIInterceptor replaces
+// random_get until it is removed.
+func Test_randomGet_interceptor(t *testing.T) {
+	interceptors := map[string]experimental.WASIInterceptor{
+		RandomGetName: func(context.Context, api.Module, []uint64) (uint32, bool) {
+			return uint32(ErrnoNosys), true
+		},
+	}
+	ctx := experimental.WithWASIInterceptors(testCtx, interceptors)
+
+	mod, r, log := requireProxyModuleWithContext(t, ctx, wazero.NewModuleConfig())
+	defer r.Close(testCtx)
+
+	randomGet := func() Errno {
+		results, err := mod.ExportedFunction(RandomGetName).Call(testCtx, 0, 5)
+		require.NoError(t, err)
+		return Errno(results[0])
+	}
+
+	require.Equal(t, ErrnoNosys, randomGet())
+
+	// Interceptors can defer to the real implementation.
+	interceptors[RandomGetName] = func(context.Context, api.Module, []uint64) (uint32, bool) {
+		return 0, false
+	}
+	require.Equal(t, ErrnoSuccess, randomGet())
+
+	delete(interceptors, RandomGetName)
+	require.Equal(t, ErrnoSuccess, randomGet())
+
+	require.Equal(t, `
+==> wasi_snapshot_preview1.random_get(buf=0,buf_len=5)
+<== errno=ENOSYS
+==> wasi_snapshot_preview1.random_get(buf=0,buf_len=5)
+<== errno=ESUCCESS
+==> wasi_snapshot_preview1.random_get(buf=0,buf_len=5)
+<== errno=ESUCCESS
+`, "\n"+log.String())
+}
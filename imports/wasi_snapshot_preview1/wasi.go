@@ -22,6 +22,7 @@ import (
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
 	. "github.com/tetratelabs/wazero/internal/wasi_snapshot_preview1"
 	"github.com/tetratelabs/wazero/internal/wasm"
 )
@@ -76,20 +77,21 @@ func NewBuilder(r wazero.Runtime) Builder {
 type builder struct{ r wazero.Runtime }
 
 // hostModuleBuilder returns a new wazero.HostModuleBuilder for ModuleName
-func (b *builder) hostModuleBuilder() wazero.HostModuleBuilder {
+func (b *builder) hostModuleBuilder(ctx context.Context) wazero.HostModuleBuilder {
 	ret := b.r.NewHostModuleBuilder(ModuleName)
-	exportFunctions(ret)
+	interceptors, _ := ctx.Value(InterceptorsKey{}).(map[string]experimental.WASIInterceptor)
+	exportFunctions(ret, interceptors)
 	return ret
 }
 
 // Compile implements Builder.Compile
 func (b *builder) Compile(ctx context.Context) (wazero.CompiledModule, error) {
-	return b.hostModuleBuilder().Compile(ctx)
+	return b.hostModuleBuilder(ctx).Compile(ctx)
 }
 
 // Instantiate implements Builder.Instantiate
 func (b *builder) Instantiate(ctx context.Context) (api.Closer, error) {
-	return b.hostModuleBuilder().Instantiate(ctx)
+	return b.hostModuleBuilder(ctx).Instantiate(ctx)
 }
 
 // FunctionExporter exports functions into a wazero.HostModuleBuilder.
@@ -129,7 +131,7 @@ type functionExporter struct{}
 
 // ExportFunctions implements FunctionExporter.ExportFunctions
 func (functionExporter) ExportFunctions(builder wazero.HostModuleBuilder) {
-	exportFunctions(builder)
+	exportFunctions(builder, nil)
 }
 
 // ## Translation notes
@@ -161,8 +163,13 @@ func (functionExporter) ExportFunctions(builder wazero.HostModuleBuilder) {
 
 // exportFunctions adds all go functions that implement wasi.
 // These should be exported in the module named ModuleName.
-func exportFunctions(builder wazero.HostModuleBuilder) {
+//
+// When interceptors is non-nil, each function checks it before running.
+func exportFunctions(builder wazero.HostModuleBuilder, interceptors map[string]experimental.WASIInterceptor) {
 	exporter := builder.(wasm.HostFuncExporter)
+	if interceptors != nil {
+		exporter = &interceptingExporter{exporter, interceptors}
+	}
 
 	// Note: these are ordered per spec for consistency even if the resulting
 	// map can't guarantee that.
@@ -282,6 +289,47 @@ func (f wasiFunc) Call(ctx context.Context, mod api.Module, stack []uint64) {
 	stack[0] = uint64(f(ctx, mod, stack))
 }
 
+// interceptingExporter wraps each implemented function exported in an
+// interceptableFunc. This is resolved once when the module is built, so
+// there's no overhead on calls when no interceptors are configured.
+type interceptingExporter struct {
+	wasm.HostFuncExporter
+	interceptors map[string]experimental.WASIInterceptor
+}
+
+// ExportHostFunc implements wasm.HostFuncExporter
+func (e *interceptingExporter) ExportHostFunc(fn *wasm.HostFunc) {
+	if goFunc, ok := fn.Code.GoFunc.(wasiFunc); ok {
+		intercepted := *fn
+		intercepted.Code = &wasm.Code{IsHostFunction: true, GoFunc: &interceptableFunc{
+			name:         fn.Name,
+			goFunc:       goFunc,
+			interceptors: e.interceptors,
+		}}
+		fn = &intercepted
+	}
+	e.HostFuncExporter.ExportHostFunc(fn)
+}
+
+// interceptableFunc calls any experimental.WASIInterceptor registered for
+// name instead of goFunc.
+type interceptableFunc struct {
+	name         string
+	goFunc       wasiFunc
+	interceptors map[string]experimental.WASIInterceptor
+}
+
+// Call implements the same method as documented on api.GoModuleFunction.
+func (f *interceptableFunc) Call(ctx context.Context, mod api.Module, stack []uint64) {
+	if interceptor := f.interceptors[f.name]; interceptor != nil {
+		if errno, handled := interceptor(ctx, mod, stack); handled {
+			stack[0] = uint64(errno)
+			return
+		}
+	}
+	f.goFunc.Call(ctx, mod, stack)
+}
+
 // stubFunction stubs for GrainLang per #271.
 func stubFunction(name string, paramTypes []wasm.ValueType, paramNames ...string) *wasm.HostFunc {
 	return &wasm.HostFunc{
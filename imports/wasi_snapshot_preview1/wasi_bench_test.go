@@ -2,14 +2,18 @@ package wasi_snapshot_preview1_test
 
 import (
 	"embed"
+	"encoding/binary"
 	"io/fs"
 	"os"
+	"path"
+	"strconv"
 	"testing"
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 	"github.com/tetratelabs/wazero/internal/sys"
+	"github.com/tetratelabs/wazero/internal/syscallfs"
 	"github.com/tetratelabs/wazero/internal/testing/proxy"
 	. "github.com/tetratelabs/wazero/internal/wasi_snapshot_preview1"
 	"github.com/tetratelabs/wazero/internal/wasm"
@@ -52,6 +56,39 @@ func Benchmark_ArgsEnviron(b *testing.B) {
 	}
 }
 
+// Benchmark_environGet_large shows the time to write environment variables
+// grows linearly with their count.
+func Benchmark_environGet_large(b *testing.B) {
+	for _, count := range []int{50, 500} {
+		config := wazero.NewModuleConfig()
+		for i := 0; i < count; i++ {
+			config = config.WithEnv("KEY"+strconv.Itoa(i), "value"+strconv.Itoa(i))
+		}
+
+		b.Run(strconv.Itoa(count), func(b *testing.B) {
+			r := wazero.NewRuntime(testCtx)
+			defer r.Close(testCtx)
+
+			mod, err := instantiateProxyModule(r, config)
+			if err != nil {
+				b.Fatal(err)
+			}
+			fn := mod.ExportedFunction(EnvironGetName)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// environ offsets are written at zero, before the buffer.
+				results, err := fn.Call(testCtx, uint64(0), uint64(4096))
+				if err != nil {
+					b.Fatal(err)
+				}
+				requireEsuccess(b, results)
+			}
+		})
+	}
+}
+
 type money struct{}
 
 // Read implements io.Reader by returning endless '$'.
@@ -227,6 +264,147 @@ func Benchmark_fdReaddir(b *testing.B) {
 	}
 }
 
+// Benchmark_fdReaddir_large reads all entries of a directory with 10k files
+// in pages, as a guest would.
+func Benchmark_fdReaddir_large(b *testing.B) {
+	tmpDir := b.TempDir()
+	for i := 0; i < 10000; i++ {
+		if err := os.WriteFile(path.Join(tmpDir, strconv.Itoa(i)), nil, 0o600); err != nil {
+			b.Fatal(err)
+		}
+	}
+	dirFS := os.DirFS(tmpDir)
+
+	r := wazero.NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	mod, err := instantiateProxyModule(r, wazero.NewModuleConfig().WithFS(dirFS))
+	if err != nil {
+		b.Fatal(err)
+	}
+	fn := mod.ExportedFunction(FdReaddirName)
+
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+	fd, err := fsc.OpenFile(".", os.O_RDONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	f, ok := fsc.LookupFile(fd)
+	if !ok {
+		b.Fatal("couldn't open fd ", fd)
+	}
+	defer fsc.CloseFile(fd) //nolint
+
+	resultBufused := uint32(0) // where to write the amount used out of bufLen
+	buf := uint32(8)           // where to start the dirents
+	bufLen := uint32(8096)     // allow up to 8KB buffer usage
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		// Recreate the file under the file-descriptor
+		if err = f.File.Close(); err != nil {
+			b.Fatal(err)
+		}
+		if f.File, err = dirFS.Open("."); err != nil {
+			b.Fatal(err)
+		}
+		f.ReadDir = nil
+		b.StartTimer()
+
+		// Read pages until the directory is exhausted, using the d_next of
+		// the last complete entry as the cookie.
+		for cookie, count := uint64(0), 0; ; {
+			results, err := fn.Call(testCtx, uint64(fd), uint64(buf), uint64(bufLen), cookie, uint64(resultBufused))
+			if err != nil {
+				b.Fatal(err)
+			}
+			requireEsuccess(b, results)
+
+			bufused, _ := mod.Memory().ReadUint32Le(resultBufused)
+			dirents, _ := mod.Memory().Read(buf, bufused)
+			for pos := uint32(0); pos+DirentSize <= bufused; {
+				nameLen := binary.LittleEndian.Uint32(dirents[pos+16:])
+				if pos+DirentSize+nameLen > bufused {
+					break // truncated
+				}
+				cookie = binary.LittleEndian.Uint64(dirents[pos:])
+				pos += DirentSize + nameLen
+				count++
+			}
+			if bufused < bufLen {
+				if count != 10000 {
+					b.Fatal("expected 10000 entries, but read ", count)
+				}
+				break
+			}
+		}
+	}
+}
+
+// Benchmark_fdWrite_buffered shows the impact of buffering on guests that
+// write a single byte at a time, as each unbuffered write is a syscall.
+func Benchmark_fdWrite_buffered(b *testing.B) {
+	benches := []struct {
+		name     string
+		buffered bool
+	}{
+		{name: "unbuffered"},
+		{name: "buffered", buffered: true},
+	}
+
+	for _, bb := range benches {
+		bc := bb
+
+		b.Run(bc.name, func(b *testing.B) {
+			dirFS, err := syscallfs.NewDirFS(b.TempDir())
+			if err != nil {
+				b.Fatal(err)
+			}
+			testFS := dirFS
+			if bc.buffered {
+				testFS = syscallfs.NewBufferedFS(dirFS, 4096)
+			}
+
+			r := wazero.NewRuntime(testCtx)
+			defer r.Close(testCtx)
+
+			mod, err := instantiateProxyModule(r, wazero.NewModuleConfig().WithFS(testFS))
+			if err != nil {
+				b.Fatal(err)
+			}
+			fn := mod.ExportedFunction(FdWriteName)
+
+			fsc := mod.(*wasm.CallContext).Sys.FS()
+			fd, err := fsc.OpenFile("file", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer fsc.CloseFile(fd) //nolint
+
+			mod.Memory().Write(0, []byte{
+				8, 0, 0, 0, // = iovs[0].offset
+				1, 0, 0, 0, // = iovs[0].length
+				'a',
+			})
+
+			b.ResetTimer()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				// Write 1000 single bytes, like a naive serializer would.
+				for j := 0; j < 1000; j++ {
+					results, err := fn.Call(testCtx, uint64(fd), uint64(0), uint64(1), uint64(16))
+					if err != nil {
+						b.Fatal(err)
+					}
+					requireEsuccess(b, results)
+				}
+			}
+		})
+	}
+}
+
 func Benchmark_pathFilestat(b *testing.B) {
 	embedFS, err := fs.Sub(testdata, "testdata")
 	if err != nil {
@@ -389,6 +567,65 @@ func Benchmark_fdWrite(b *testing.B) {
 	}
 }
 
+// Benchmark_fdWrite_large compares a single 1MB fd_write, which writes
+// directly from the guest memory, to a writer that copies it first.
+func Benchmark_fdWrite_large(b *testing.B) {
+	const size = 1 << 20 // 1MB
+
+	discard := func(p []byte) (n int, err error) { return len(p), nil }
+	benches := []struct {
+		name   string
+		stdout writerFunc
+	}{
+		{
+			name:   "no copy",
+			stdout: discard,
+		},
+		{
+			name: "copy",
+			stdout: func(p []byte) (n int, err error) {
+				buf := make([]byte, len(p))
+				copy(buf, p)
+				return discard(buf)
+			},
+		},
+	}
+
+	for _, bb := range benches {
+		bc := bb
+
+		b.Run(bc.name, func(b *testing.B) {
+			r := wazero.NewRuntime(testCtx)
+			defer r.Close(testCtx)
+
+			mod, err := instantiateProxyModule(r, wazero.NewModuleConfig().WithStdout(bc.stdout))
+			if err != nil {
+				b.Fatal(err)
+			}
+			fn := mod.ExportedFunction(FdWriteName)
+
+			// Grow memory to fit the iovec and the data after it.
+			if _, ok := mod.Memory().Grow(size / 65536); !ok {
+				b.Fatal("couldn't grow memory")
+			}
+			iovs, resultNwritten := uint32(0), uint32(8)
+			mod.Memory().WriteUint32Le(iovs, 16)     // = iovs[0].offset
+			mod.Memory().WriteUint32Le(iovs+4, size) // = iovs[0].length
+
+			b.SetBytes(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				results, err := fn.Call(testCtx, uint64(sys.FdStdout), uint64(iovs), uint64(1), uint64(resultNwritten))
+				if err != nil {
+					b.Fatal(err)
+				}
+				requireEsuccess(b, results)
+			}
+		})
+	}
+}
+
 // instantiateProxyModule instantiates a guest that re-exports WASI functions.
 func instantiateProxyModule(r wazero.Runtime, config wazero.ModuleConfig) (api.Module, error) {
 	wasiModuleCompiled, err := wasi_snapshot_preview1.NewBuilder(r).Compile(testCtx)
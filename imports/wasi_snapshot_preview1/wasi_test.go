@@ -83,10 +83,16 @@ func maskMemory(t *testing.T, mod api.Module, size int) {
 }
 
 func requireProxyModule(t *testing.T, config wazero.ModuleConfig) (api.Module, api.Closer, *bytes.Buffer) {
+	return requireProxyModuleWithContext(t, testCtx, config)
+}
+
+// requireProxyModuleWithContext is like requireProxyModule, except it
+// compiles the WASI module with ctx, e.g. to add experimental features.
+func requireProxyModuleWithContext(t *testing.T, ctx context.Context, config wazero.ModuleConfig) (api.Module, api.Closer, *bytes.Buffer) {
 	var log bytes.Buffer
 
 	// Set context to one that has an experimental listener
-	ctx := context.WithValue(testCtx, FunctionListenerFactoryKey{}, proxy.NewLoggingListenerFactory(&log))
+	ctx = context.WithValue(ctx, FunctionListenerFactoryKey{}, proxy.NewLoggingListenerFactory(&log))
 
 	r := wazero.NewRuntime(ctx)
 
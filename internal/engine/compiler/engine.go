@@ -20,6 +20,7 @@ import (
 	"github.com/tetratelabs/wazero/internal/wasmdebug"
 	"github.com/tetratelabs/wazero/internal/wasmruntime"
 	"github.com/tetratelabs/wazero/internal/wazeroir"
+	"github.com/tetratelabs/wazero/sys"
 )
 
 // NOTE: The offset of many of the struct fields defined here are referenced from
@@ -734,6 +735,8 @@ func callFrameOffset(funcType *wasm.FunctionType) (ret int) {
 func (ce *callEngine) deferredOnCall(recovered interface{}) (err error) {
 	if recovered != nil {
 		builder := wasmdebug.NewErrorBuilder()
+		var stack []api.FunctionDefinition
+		var stackTraceEnabled bool
 
 		// Unwinds call frames from the values stack, starting from the
 		// current function `ce.fn`, and the current stack base pointer `ce.stackBasePointerInBytes`.
@@ -754,6 +757,11 @@ func (ce *callEngine) deferredOnCall(recovered interface{}) (err error) {
 				}
 			}
 			builder.AddFrame(def.DebugName(), def.ParamTypes(), def.ResultTypes(), sources)
+			stack = append(stack, def)
+			// The outermost frame is the function called, so its module decides.
+			if p := fn.parent; p != nil && p.sourceModule != nil {
+				stackTraceEnabled = p.sourceModule.StackTraceEnabled
+			}
 
 			callFrameOffset := callFrameOffset(source.Type)
 			if stackBasePointer != 0 {
@@ -766,6 +774,9 @@ func (ce *callEngine) deferredOnCall(recovered interface{}) (err error) {
 			}
 		}
 		err = builder.FromRecovered(recovered)
+		if _, ok := err.(*sys.ExitError); !ok && stackTraceEnabled {
+			err = &experimental.StackTraceError{Err: err, Stack: stack}
+		}
 	}
 
 	// Allows the reuse of CallEngine.
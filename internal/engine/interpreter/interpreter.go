@@ -18,6 +18,7 @@ import (
 	"github.com/tetratelabs/wazero/internal/wasmdebug"
 	"github.com/tetratelabs/wazero/internal/wasmruntime"
 	"github.com/tetratelabs/wazero/internal/wazeroir"
+	"github.com/tetratelabs/wazero/sys"
 )
 
 // callStackCeiling is the maximum WebAssembly call frame stack height. This allows wazero to raise
@@ -101,6 +102,22 @@ type callEngine struct {
 	compiled *function
 	// source is the FunctionInstance from which compiled is created from.
 	source *wasm.FunctionInstance
+
+	// instructionCount is incremented per operation executed when non-nil.
+	// See experimental.WithInstructionCounter
+	instructionCount *uint64
+
+	// stackSnapshot is filled in during host function calls when non-nil.
+	// See experimental.WithStackSnapshots
+	stackSnapshot *wasmruntime.Stack
+
+	// profiler is ticked per operation executed when non-nil.
+	// See experimental.WithProfiler
+	profiler *wasmruntime.Profiler
+
+	// interrupt is true when branches check if the context is done.
+	// See wazero.RuntimeConfig WithInterruptOnContextDone
+	interrupt bool
 }
 
 func (e *moduleEngine) newCallEngine(source *wasm.FunctionInstance, compiled *function) *callEngine {
@@ -796,7 +813,23 @@ func (ce *callEngine) call(ctx context.Context, m *wasm.CallContext, tf *functio
 		return nil, fmt.Errorf("expected %d params, but passed %d", paramSignature, paramCount)
 	}
 
+	// Count instructions if the context has a counter, restoring that of any
+	// outer call to this engine on return.
+	prevCount := ce.instructionCount
+	ce.instructionCount, _ = ctx.Value(wasmruntime.InstructionCounterKey{}).(*uint64)
+	prevSnapshot := ce.stackSnapshot
+	ce.stackSnapshot, _ = ctx.Value(wasmruntime.StackKey{}).(*wasmruntime.Stack)
+	prevProfiler := ce.profiler
+	ce.profiler, _ = ctx.Value(wasmruntime.ProfilerKey{}).(*wasmruntime.Profiler)
+	prevInterrupt := ce.interrupt
+	ce.interrupt = ctx.Value(wasmruntime.InterruptKey{}) != nil
+
 	defer func() {
+		ce.instructionCount = prevCount
+		ce.stackSnapshot = prevSnapshot
+		ce.profiler = prevProfiler
+		ce.interrupt = prevInterrupt
+
 		// If the module closed during the call, and the call didn't err for another reason, set an ExitError.
 		if err == nil {
 			err = m.FailIfClosed()
@@ -827,6 +860,8 @@ func (ce *callEngine) call(ctx context.Context, m *wasm.CallContext, tf *functio
 func (ce *callEngine) recoverOnCall(v interface{}) (err error) {
 	builder := wasmdebug.NewErrorBuilder()
 	frameCount := len(ce.frames)
+	stack := make([]api.FunctionDefinition, 0, frameCount)
+	var stackTraceEnabled bool
 	for i := 0; i < frameCount; i++ {
 		frame := ce.popFrame()
 		def := frame.f.source.Definition
@@ -835,8 +870,14 @@ func (ce *callEngine) recoverOnCall(v interface{}) (err error) {
 			sources = frame.f.parent.source.DWARFLines.Line(body[frame.pc].sourcePC)
 		}
 		builder.AddFrame(def.DebugName(), def.ParamTypes(), def.ResultTypes(), sources)
+		stack = append(stack, def)
+		// The outermost frame is the function called, so its module decides.
+		stackTraceEnabled = frame.f.parent.source.StackTraceEnabled
 	}
 	err = builder.FromRecovered(v)
+	if _, ok := err.(*sys.ExitError); !ok && stackTraceEnabled {
+		err = &experimental.StackTraceError{Err: err, Stack: stack}
+	}
 
 	// Allows the reuse of CallEngine.
 	ce.stack, ce.frames = ce.stack[:0], ce.frames[:0]
@@ -860,6 +901,17 @@ func (ce *callEngine) callGoFunc(ctx context.Context, callCtx *wasm.CallContext,
 		params := stack[:f.source.Type.ParamNumInUint64]
 		ctx = lsn.Before(ctx, callCtx, f.source.Definition, params)
 	}
+	if s := ce.stackSnapshot; s != nil {
+		// Restore the snapshot after the call, as the host function may have
+		// called back into Wasm, which in turn called another host function.
+		prev := *s
+		defer func() { *s = prev }()
+		s.Caller = nil
+		if len(ce.frames) > 0 {
+			s.Caller = ce.frames[len(ce.frames)-1].f.source.Definition
+		}
+		s.Values = append(make([]uint64, 0, len(ce.stack)), ce.stack...)
+	}
 	frame := &callFrame{f: f}
 	ce.pushFrame(frame)
 
@@ -897,7 +949,21 @@ func (ce *callEngine) callNativeFunc(ctx context.Context, callCtx *wasm.CallCont
 	ce.pushFrame(frame)
 	body := frame.f.parent.body
 	bodyLen := uint64(len(body))
+	instructionCount := ce.instructionCount
+	profiler := ce.profiler
+	// When enabled, branches check the context, so that loops can be
+	// interrupted when it is canceled or its deadline passes.
+	var done <-chan struct{}
+	if ce.interrupt {
+		done = ctx.Done()
+	}
 	for frame.pc < bodyLen {
+		if instructionCount != nil {
+			*instructionCount++
+		}
+		if profiler != nil {
+			profiler.Tick(f.source.Definition)
+		}
 		op := body[frame.pc]
 		// TODO: add description of each operation/case
 		// on, for example, how many args are used,
@@ -906,8 +972,14 @@ func (ce *callEngine) callNativeFunc(ctx context.Context, callCtx *wasm.CallCont
 		case wazeroir.OperationKindUnreachable:
 			panic(wasmruntime.ErrRuntimeUnreachable)
 		case wazeroir.OperationKindBr:
+			if done != nil {
+				checkDone(ctx, done)
+			}
 			frame.pc = op.us[0]
 		case wazeroir.OperationKindBrIf:
+			if done != nil {
+				checkDone(ctx, done)
+			}
 			if ce.popValue() > 0 {
 				ce.drop(op.rs[0])
 				frame.pc = op.us[0]
@@ -916,6 +988,9 @@ func (ce *callEngine) callNativeFunc(ctx context.Context, callCtx *wasm.CallCont
 				frame.pc = op.us[1]
 			}
 		case wazeroir.OperationKindBrTable:
+			if done != nil {
+				checkDone(ctx, done)
+			}
 			if v := uint64(ce.popValue()); v < uint64(len(op.us)-1) {
 				ce.drop(op.rs[v+1])
 				frame.pc = op.us[v+1]
@@ -4150,6 +4225,15 @@ func (ce *callEngine) callNativeFunc(ctx context.Context, callCtx *wasm.CallCont
 	ce.popFrame()
 }
 
+// checkDone panics with the context error when done is closed.
+func checkDone(ctx context.Context, done <-chan struct{}) {
+	select {
+	case <-done:
+		panic(ctx.Err())
+	default:
+	}
+}
+
 // callerMemory returns the caller context memory.
 func (ce *callEngine) callerMemory() *wasm.MemoryInstance {
 	// Search through the call frame stack from the top until we find a non host function.
@@ -3,6 +3,7 @@ package adhoc
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"math"
 	"strconv"
 	"testing"
@@ -10,11 +11,13 @@ import (
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
 	"github.com/tetratelabs/wazero/internal/platform"
 	"github.com/tetratelabs/wazero/internal/testing/proxy"
 	"github.com/tetratelabs/wazero/internal/testing/require"
 	"github.com/tetratelabs/wazero/internal/wasm"
 	"github.com/tetratelabs/wazero/internal/wasm/binary"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
 	"github.com/tetratelabs/wazero/sys"
 )
 
@@ -32,6 +35,7 @@ var moduleConfig = wazero.NewModuleConfig()
 var tests = map[string]func(t *testing.T, r wazero.Runtime){
 	"huge stack":                                        testHugeStack,
 	"unreachable":                                       testUnreachable,
+	"host function panic in start function":             testHostPanicInStart,
 	"recursive entry":                                   testRecursiveEntry,
 	"host func memory":                                  testHostFuncMemory,
 	"host function with context parameter":              testHostFunctionContextParameter,
@@ -57,6 +61,65 @@ func TestEngineInterpreter(t *testing.T) {
 	runAllTests(t, tests, wazero.NewRuntimeConfigInterpreter())
 }
 
+func TestEngineCompiler_StackTrace(t *testing.T) {
+	if !platform.CompilerSupported() {
+		t.Skip()
+	}
+	testStackTrace(t, wazero.NewRuntimeConfigCompiler())
+}
+
+func TestEngineInterpreter_StackTrace(t *testing.T) {
+	testStackTrace(t, wazero.NewRuntimeConfigInterpreter())
+}
+
+func testStackTrace(t *testing.T, config wazero.RuntimeConfig) {
+	bin := binary.EncodeModule(&wasm.Module{
+		TypeSection:     []*wasm.FunctionType{{}},
+		FunctionSection: []wasm.Index{0, 0, 0},
+		ExportSection:   []*wasm.Export{{Name: "a", Type: wasm.ExternTypeFunc, Index: 0}},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{wasm.OpcodeCall, 1, wasm.OpcodeEnd}},     // a calls b
+			{Body: []byte{wasm.OpcodeCall, 2, wasm.OpcodeEnd}},     // b calls c
+			{Body: []byte{wasm.OpcodeUnreachable, wasm.OpcodeEnd}}, // c traps
+		},
+		NameSection: &wasm.NameSection{
+			FunctionNames: wasm.NameMap{{Index: 0, Name: "a"}, {Index: 1, Name: "b"}, {Index: 2, Name: "c"}},
+		},
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		r := wazero.NewRuntimeWithConfig(testCtx, config)
+		defer r.Close(testCtx)
+
+		mod, err := r.InstantiateModuleFromBinary(testCtx, bin)
+		require.NoError(t, err)
+
+		_, err = mod.ExportedFunction("a").Call(testCtx)
+		var stErr *experimental.StackTraceError
+		require.False(t, errors.As(err, &stErr))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		r := wazero.NewRuntimeWithConfig(testCtx, config.WithStackTrace(true))
+		defer r.Close(testCtx)
+
+		mod, err := r.InstantiateModuleFromBinary(testCtx, bin)
+		require.NoError(t, err)
+
+		_, err = mod.ExportedFunction("a").Call(testCtx)
+		require.ErrorIs(t, err, wasmruntime.ErrRuntimeUnreachable)
+
+		var stErr *experimental.StackTraceError
+		require.True(t, errors.As(err, &stErr))
+
+		var names []string
+		for _, def := range stErr.Stack {
+			names = append(names, def.Name())
+		}
+		require.Equal(t, []string{"c", "b", "a"}, names)
+	})
+}
+
 func runAllTests(t *testing.T, tests map[string]func(t *testing.T, r wazero.Runtime), config wazero.RuntimeConfig) {
 	for name, testf := range tests {
 		name := name   // pin
@@ -220,6 +283,32 @@ wasm stack trace:
 	require.Equal(t, exp, err.Error())
 }
 
+// testHostPanicInStart ensures a host function panic during instantiation is
+// returned as an error, instead of crashing the process.
+func testHostPanicInStart(t *testing.T, r wazero.Runtime) {
+	_, err := r.NewHostModuleBuilder("host").
+		NewFunctionBuilder().WithFunc(func() { panic("host bug") }).Export("panic").
+		Instantiate(testCtx)
+	require.NoError(t, err)
+
+	bin := binary.EncodeModule(&wasm.Module{
+		TypeSection:     []*wasm.FunctionType{{}},
+		ImportSection:   []*wasm.Import{{Module: "host", Name: "panic", Type: wasm.ExternTypeFunc, DescFunc: 0}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection:     []*wasm.Code{{Body: []byte{wasm.OpcodeCall, 0, wasm.OpcodeEnd}}},
+		ExportSection:   []*wasm.Export{{Name: "_start", Type: wasm.ExternTypeFunc, Index: 1}},
+	})
+
+	_, err = r.InstantiateModuleFromBinary(testCtx, bin)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "host bug (recovered by wazero)")
+
+	// The runtime is still usable after the panic.
+	mod, err := r.InstantiateModuleFromBinary(testCtx, binary.EncodeModule(&wasm.Module{}))
+	require.NoError(t, err)
+	require.NoError(t, mod.Close(testCtx))
+}
+
 func testRecursiveEntry(t *testing.T, r wazero.Runtime) {
 	hostfunc := func(ctx context.Context, mod api.Module) {
 		_, err := mod.ExportedFunction("called_by_host_func").Call(testCtx)
@@ -0,0 +1,11 @@
+package platform
+
+import "os"
+
+// Fdatasync is like os.File Sync, except it doesn't need to flush metadata
+// not required to read the data back, such as the modification time.
+//
+// On platforms without fdatasync, this falls back to os.File Sync.
+func Fdatasync(f *os.File) error {
+	return fdatasync(f)
+}
@@ -0,0 +1,13 @@
+package platform
+
+import (
+	"os"
+	"syscall"
+)
+
+// sysFdatasync is a variable so that tests can verify it is called.
+var sysFdatasync = syscall.Fdatasync
+
+func fdatasync(f *os.File) error {
+	return sysFdatasync(int(f.Fd()))
+}
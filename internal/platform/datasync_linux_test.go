@@ -0,0 +1,26 @@
+package platform
+
+import (
+	"os"
+	"path"
+	"syscall"
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestFdatasync_usesFdatasync(t *testing.T) {
+	f, err := os.Create(path.Join(t.TempDir(), "file"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	var calledFd int
+	sysFdatasync = func(fd int) error {
+		calledFd = fd
+		return syscall.Fdatasync(fd)
+	}
+	defer func() { sysFdatasync = syscall.Fdatasync }()
+
+	require.NoError(t, Fdatasync(f))
+	require.Equal(t, int(f.Fd()), calledFd)
+}
@@ -0,0 +1,23 @@
+package platform
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestFdatasync(t *testing.T) {
+	f, err := os.Create(path.Join(t.TempDir(), "file"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("wazero"))
+	require.NoError(t, err)
+	require.NoError(t, Fdatasync(f))
+
+	// Syncing a closed file fails.
+	require.NoError(t, f.Close())
+	require.Error(t, Fdatasync(f))
+}
@@ -80,6 +80,17 @@ insert:
 	goto insert
 }
 
+// InsertAt inserts the given file to the table at the given fd, replacing
+// any file already mapped to it.
+func (t *FileTable) InsertAt(file *FileEntry, fd uint32) {
+	if n := int(fd/64) + 1; n > len(t.masks) {
+		t.Grow(n)
+	}
+	index, shift := fd/64, fd%64
+	t.files[fd] = file
+	t.masks[index] |= uint64(1 << shift)
+}
+
 // Lookup returns the file associated with the given fd (may be nil).
 func (t *FileTable) Lookup(fd uint32) (file *FileEntry, found bool) {
 	if i := int(fd); i >= 0 && i < len(t.files) {
@@ -90,6 +90,29 @@ func TestFileTable(t *testing.T) {
 	}
 }
 
+func TestFileTable_InsertAt(t *testing.T) {
+	table := new(sys.FileTable)
+
+	v0 := &sys.FileEntry{Name: "1"}
+	v1 := &sys.FileEntry{Name: "2"}
+
+	// Inserting beyond the current capacity grows the table.
+	table.InsertAt(v0, 100)
+	if v, ok := table.Lookup(100); !ok || v != v0 {
+		t.Errorf("wrong value for key '100': want=%v got=%v", v0, v)
+	}
+
+	// Inserting at an occupied fd replaces the existing file.
+	table.InsertAt(v1, 100)
+	if v, ok := table.Lookup(100); !ok || v != v1 {
+		t.Errorf("wrong value for key '100': want=%v got=%v", v1, v)
+	}
+
+	if n := table.Len(); n != 1 {
+		t.Errorf("wrong table length: want=1 got=%d", n)
+	}
+}
+
 func BenchmarkFileTableInsert(b *testing.B) {
 	table := new(sys.FileTable)
 	entry := new(sys.FileEntry)
@@ -3,9 +3,11 @@ package sys
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"io/fs"
 	"os"
+	pathutil "path"
 	"syscall"
 	"time"
 
@@ -34,6 +36,7 @@ const (
 const (
 	modeDevice     = uint32(fs.ModeDevice | 0o640)
 	modeCharDevice = uint32(fs.ModeCharDevice | 0o640)
+	modeNamedPipe  = uint32(fs.ModeNamedPipe | 0o640)
 )
 
 type stdioFileWriter struct {
@@ -158,8 +161,16 @@ type FileEntry struct {
 	// IsPreopen is a directory that is lazily opened.
 	IsPreopen bool
 
+	// FdFlags are the flags of this file descriptor, set when opened or
+	// after, e.g. via "path_open" or "fd_fdstat_set_flags" in
+	// "wasi_snapshot_preview1".
+	FdFlags uint16
+
 	isDirectory bool
 
+	// inode is zero for stdio, as it isn't opened from the file system.
+	inode uint64
+
 	// File is always non-nil.
 	File fs.File
 
@@ -168,6 +179,27 @@ type FileEntry struct {
 	ReadDir *ReadDir
 }
 
+// Inode returns the synthesized inode of the file, as Golang FS doesn't
+// expose one, or zero if stdio. See SynthesizeInode
+func (f *FileEntry) Inode() uint64 {
+	return f.inode
+}
+
+// SynthesizeInode returns a file serial number for the path, which is relative
+// to the pre-open, e.g. "" or "." for the root, and "dir/file.txt".
+//
+// The result is a stable FNV-1a hash of the cleaned path, so the same path
+// always has the same inode, regardless of instantiation or the order files
+// were opened in. This allows tests to predict values.
+func SynthesizeInode(path string) uint64 {
+	if path = pathutil.Clean(path); path == "." || path == "/" {
+		path = ""
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(path))
+	return h.Sum64()
+}
+
 // IsDir returns true if the file is a directory.
 func (f *FileEntry) IsDir() bool {
 	if f.IsPreopen || f.isDirectory {
@@ -177,6 +209,16 @@ func (f *FileEntry) IsDir() bool {
 	return f.isDirectory
 }
 
+// IsStdio returns true if the file is a stdio stream, regardless of its file
+// descriptor. For example, this is false for a file renumbered to FdStdout.
+func (f *FileEntry) IsStdio() bool {
+	switch f.File.(type) {
+	case *stdioFileReader, *stdioFileWriter:
+		return true
+	}
+	return false
+}
+
 // Stat returns the underlying stat of this file.
 func (f *FileEntry) Stat() (stat fs.FileInfo, err error) {
 	stat, err = f.File.Stat()
@@ -194,6 +236,8 @@ type ReadDir struct {
 	// Entries is the contents of the last fs.ReadDirFile call. Notably,
 	// directory listing are not rewindable, so we keep entries around in case
 	// the caller mis-estimated their buffer and needs a few still cached.
+	//
+	// When a listing starts, this is a snapshot of the whole directory.
 	Entries []fs.DirEntry
 }
 
@@ -205,6 +249,32 @@ type FSContext struct {
 	// (or directories) and defaults to empty.
 	// TODO: This is unguarded, so not goroutine-safe!
 	openedFiles FileTable
+
+	// FileListener is notified by OpenFile and CloseFile, if set.
+	//
+	// See experimental.FileListener
+	FileListener func(fd uint32, path string, opened bool)
+
+	// ErrnoMapper is consulted by MapError, if set.
+	//
+	// See wazero.ModuleConfig WithFSErrnoMapper
+	ErrnoMapper func(error) (syscall.Errno, bool)
+
+	// ReadChunkSize when positive limits the size of each read from a file.
+	//
+	// See wazero.ModuleConfig WithFSReadChunkSize
+	ReadChunkSize uint32
+}
+
+// MapError returns the syscall.Errno ErrnoMapper maps the error to, or the
+// error unchanged when there is no mapping.
+func (c *FSContext) MapError(err error) error {
+	if c.ErrnoMapper != nil && err != nil {
+		if errno, ok := c.ErrnoMapper(err); ok {
+			return errno
+		}
+	}
+	return err
 }
 
 // NewFSContext creates a FSContext with stdio streams and an optional
@@ -224,6 +294,7 @@ func NewFSContext(stdin io.Reader, stdout, stderr io.Writer, preopened syscallfs
 
 	fsc.openedFiles.Insert(&FileEntry{
 		IsPreopen: true,
+		inode:     SynthesizeInode(""),
 		File:      &lazyDir{fs: preopened},
 	})
 	return fsc, nil
@@ -245,9 +316,26 @@ func stdioWriter(w io.Writer, defaultStat stdioFileInfo) *FileEntry {
 	return &FileEntry{File: &stdioFileWriter{w: w, s: s}}
 }
 
+// stdioStat returns the mode of a stdio stream. Besides *os.File, streams
+// which implement Stat reporting fs.ModeCharDevice are terminals, such as a
+// pseudo-terminal managed by the host. A stream redirected to a regular
+// *os.File returns its stat, so that it has a modification time.
 func stdioStat(f interface{}, defaultStat stdioFileInfo) fs.FileInfo {
-	if f, ok := f.(*os.File); ok && platform.IsTerminal(f.Fd()) {
-		return stdioFileInfo{defaultStat[0], modeCharDevice}
+	switch f := f.(type) {
+	case *os.File:
+		if platform.IsTerminal(f.Fd()) {
+			return stdioFileInfo{defaultStat[0], modeCharDevice}
+		} else if st, err := f.Stat(); err != nil {
+			break
+		} else if st.Mode()&fs.ModeNamedPipe != 0 {
+			return stdioFileInfo{defaultStat[0], modeNamedPipe}
+		} else if st.Mode().IsRegular() {
+			return st
+		}
+	case interface{ Stat() (fs.FileInfo, error) }:
+		if st, err := f.Stat(); err == nil && st.Mode()&fs.ModeCharDevice != 0 {
+			return stdioFileInfo{defaultStat[0], modeCharDevice}
+		}
 	}
 	return defaultStat
 }
@@ -280,7 +368,10 @@ func (c *FSContext) OpenFile(path string, flag int, perm fs.FileMode) (uint32, e
 		if path == "/" || path == "." {
 			path = ""
 		}
-		newFD := c.openedFiles.Insert(&FileEntry{Name: path, File: f})
+		newFD := c.openedFiles.Insert(&FileEntry{Name: path, inode: SynthesizeInode(path), File: f})
+		if c.FileListener != nil {
+			c.FileListener(newFD, path, true)
+		}
 		return newFD, nil
 	}
 }
@@ -291,6 +382,28 @@ func (c *FSContext) LookupFile(fd uint32) (*FileEntry, bool) {
 	return f, ok
 }
 
+// PreopenCount returns the count of pre-opened directories in the table,
+// which "fd_prestat_get" in "wasi_snapshot_preview1" exposes to the guest.
+func (c *FSContext) PreopenCount() (n int) {
+	c.openedFiles.Range(func(_ uint32, f *FileEntry) bool {
+		if f.IsPreopen {
+			n++
+		}
+		return true
+	})
+	return
+}
+
+// PreopenPath returns the path of the pre-opened directory at the file
+// descriptor, or false if the file descriptor isn't a pre-open.
+func (c *FSContext) PreopenPath(fd uint32) (string, bool) {
+	if f, ok := c.openedFiles.Lookup(fd); !ok || !f.IsPreopen {
+		return "", false
+	}
+	// TODO: multiple pre-opens
+	return c.fs.Path(), true
+}
+
 // CloseFile returns any error closing the existing file.
 func (c *FSContext) CloseFile(fd uint32) error {
 	f, ok := c.openedFiles.Lookup(fd)
@@ -298,9 +411,115 @@ func (c *FSContext) CloseFile(fd uint32) error {
 		return syscall.EBADF
 	}
 	c.openedFiles.Delete(fd)
+	if c.FileListener != nil {
+		c.FileListener(fd, f.Name, false)
+	}
 	return f.File.Close()
 }
 
+// Renumber moves the file at the file descriptor `from` to `to`, closing the
+// file previously open at `to`. The whole FileEntry moves, so the offset and
+// FdFlags of `from` are preserved.
+//
+// This returns syscall.EBADF if either file descriptor isn't open, or
+// syscall.ENOTSUP if either is a pre-opened directory.
+func (c *FSContext) Renumber(from, to uint32) error {
+	fromFile, ok := c.openedFiles.Lookup(from)
+	if !ok {
+		return syscall.EBADF
+	}
+	toFile, ok := c.openedFiles.Lookup(to)
+	if !ok {
+		return syscall.EBADF
+	}
+	if fromFile.IsPreopen || toFile.IsPreopen {
+		return syscall.ENOTSUP
+	}
+	if from == to {
+		return nil
+	}
+
+	c.openedFiles.Delete(from)
+	c.openedFiles.InsertAt(fromFile, to)
+	if c.FileListener != nil {
+		c.FileListener(to, toFile.Name, false)
+		c.FileListener(from, fromFile.Name, false)
+		c.FileListener(to, fromFile.Name, true)
+	}
+	// Like dup2 in POSIX, errors closing the replaced file are ignored, as
+	// the renumbering already succeeded.
+	_ = toFile.File.Close()
+	return nil
+}
+
+// FileOffset returns the current offset of the file descriptor, or false if
+// it isn't open or isn't seekable, e.g. stdio.
+func (c *FSContext) FileOffset(fd uint32) (int64, bool) {
+	f, ok := c.openedFiles.Lookup(fd)
+	if !ok {
+		return 0, false
+	}
+	seeker, ok := f.File.(io.Seeker)
+	if !ok {
+		return 0, false
+	}
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
+	}
+	return offset, true
+}
+
+// SetFileOffset sets the offset of the file descriptor relative to the start
+// of the file, so that the guest's next read or write begins there.
+//
+// This returns syscall.EBADF if the file descriptor isn't open, or
+// syscall.ESPIPE if it isn't seekable, e.g. stdio.
+func (c *FSContext) SetFileOffset(fd uint32, offset int64) error {
+	f, ok := c.openedFiles.Lookup(fd)
+	if !ok {
+		return syscall.EBADF
+	}
+	seeker, ok := f.File.(io.Seeker)
+	if !ok {
+		return syscall.ESPIPE
+	}
+	_, err := seeker.Seek(offset, io.SeekStart)
+	return err
+}
+
+// Rewind resets the file descriptor, so that the guest's next read begins at
+// the start. Files seek to offset zero, while directories are reopened, as
+// fs.ReadDirFile cannot seek back to its first entry.
+//
+// This returns syscall.EBADF if the file descriptor isn't open, or
+// syscall.ESPIPE if it isn't seekable, e.g. stdio.
+//
+// Note: Directories are reopened by the name they were opened with, which can
+// drift on rename.
+func (c *FSContext) Rewind(fd uint32) error {
+	f, ok := c.openedFiles.Lookup(fd)
+	if !ok {
+		return syscall.EBADF
+	}
+	if !f.IsDir() {
+		return c.SetFileOffset(fd, 0)
+	}
+
+	name := f.Name
+	if name == "" {
+		name = "."
+	}
+	file, err := c.fs.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	_ = f.File.Close()
+	f.File = file
+	f.ReadDir = nil
+	return nil
+}
+
 // Close implements api.Closer
 func (c *FSContext) Close(context.Context) (err error) {
 	// Close any files opened in this context
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"syscall"
 	"testing"
 	"testing/fstest"
 
@@ -95,6 +96,43 @@ func TestNewFSContext(t *testing.T) {
 	}
 }
 
+func TestFSContext_Preopen(t *testing.T) {
+	dirfs, err := syscallfs.NewDirFS(".")
+	require.NoError(t, err)
+
+	t.Run("preopen", func(t *testing.T) {
+		fsc, err := NewFSContext(nil, nil, nil, dirfs)
+		require.NoError(t, err)
+		defer fsc.Close(testCtx)
+
+		require.Equal(t, 1, fsc.PreopenCount())
+
+		path, ok := fsc.PreopenPath(FdPreopen)
+		require.True(t, ok)
+		require.Equal(t, dirfs.Path(), path)
+
+		// Opened files aren't pre-opens, even when directories.
+		fd, err := fsc.OpenFile(".", os.O_RDONLY, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, fsc.PreopenCount())
+
+		for _, fd := range []uint32{FdStdin, FdStdout, FdStderr, fd, 42} {
+			_, ok := fsc.PreopenPath(fd)
+			require.False(t, ok)
+		}
+	})
+
+	t.Run("no preopen", func(t *testing.T) {
+		fsc, err := NewFSContext(nil, nil, nil, syscallfs.EmptyFS)
+		require.NoError(t, err)
+		defer fsc.Close(testCtx)
+
+		require.Equal(t, 0, fsc.PreopenCount())
+		_, ok := fsc.PreopenPath(FdPreopen)
+		require.False(t, ok)
+	})
+}
+
 func TestEmptyFSContext(t *testing.T) {
 	testFS, err := NewFSContext(nil, nil, nil, syscallfs.EmptyFS)
 	require.NoError(t, err)
@@ -153,3 +191,141 @@ func TestContext_Close_Error(t *testing.T) {
 	// Paths should clear even under error
 	require.Zero(t, fsc.openedFiles.Len(), "expected no opened files")
 }
+
+func TestContext_FileOffset(t *testing.T) {
+	testFS := syscallfs.Adapt(fstest.MapFS{"foo": &fstest.MapFile{Data: []byte("wazero")}})
+
+	fsc, err := NewFSContext(nil, nil, nil, testFS)
+	require.NoError(t, err)
+	defer fsc.Close(testCtx)
+
+	fd, err := fsc.OpenFile("foo", os.O_RDONLY, 0)
+	require.NoError(t, err)
+
+	offset, ok := fsc.FileOffset(fd)
+	require.True(t, ok)
+	require.Zero(t, offset)
+
+	require.NoError(t, fsc.SetFileOffset(fd, 4))
+	offset, ok = fsc.FileOffset(fd)
+	require.True(t, ok)
+	require.Equal(t, int64(4), offset)
+
+	t.Run("not open", func(t *testing.T) {
+		_, ok := fsc.FileOffset(42)
+		require.False(t, ok)
+		require.Equal(t, syscall.EBADF, fsc.SetFileOffset(42, 0))
+	})
+
+	t.Run("not seekable", func(t *testing.T) {
+		_, ok := fsc.FileOffset(FdStdout)
+		require.False(t, ok)
+		require.Equal(t, syscall.ESPIPE, fsc.SetFileOffset(FdStdout, 0))
+	})
+}
+
+func TestContext_Renumber(t *testing.T) {
+	testFS := syscallfs.Adapt(fstest.MapFS{
+		"foo": &fstest.MapFile{Data: []byte("wazero")},
+		"bar": &fstest.MapFile{Data: []byte("wazero")},
+	})
+
+	fsc, err := NewFSContext(nil, nil, nil, testFS)
+	require.NoError(t, err)
+	defer fsc.Close(testCtx)
+
+	from, err := fsc.OpenFile("foo", os.O_RDONLY, 0)
+	require.NoError(t, err)
+	to, err := fsc.OpenFile("bar", os.O_RDONLY, 0)
+	require.NoError(t, err)
+
+	fromFile, ok := fsc.LookupFile(from)
+	require.True(t, ok)
+	fromFile.FdFlags = 1
+	require.NoError(t, fsc.SetFileOffset(from, 3))
+
+	require.NoError(t, fsc.Renumber(from, to))
+
+	// The source is no longer open.
+	_, ok = fsc.LookupFile(from)
+	require.False(t, ok)
+
+	// The destination holds the whole entry of the source.
+	toFile, ok := fsc.LookupFile(to)
+	require.True(t, ok)
+	require.Equal(t, fromFile, toFile)
+	offset, ok := fsc.FileOffset(to)
+	require.True(t, ok)
+	require.Equal(t, int64(3), offset)
+
+	t.Run("not open", func(t *testing.T) {
+		require.Equal(t, syscall.EBADF, fsc.Renumber(42, to))
+		require.Equal(t, syscall.EBADF, fsc.Renumber(to, 42))
+	})
+
+	t.Run("preopen", func(t *testing.T) {
+		require.Equal(t, syscall.ENOTSUP, fsc.Renumber(to, FdPreopen))
+		require.Equal(t, syscall.ENOTSUP, fsc.Renumber(FdPreopen, to))
+	})
+}
+
+func TestContext_Rewind(t *testing.T) {
+	testFS := syscallfs.Adapt(fstest.MapFS{
+		"foo":     &fstest.MapFile{Data: []byte("wazero")},
+		"dir/bar": &fstest.MapFile{},
+	})
+
+	fsc, err := NewFSContext(nil, nil, nil, testFS)
+	require.NoError(t, err)
+	defer fsc.Close(testCtx)
+
+	t.Run("file", func(t *testing.T) {
+		fd, err := fsc.OpenFile("foo", os.O_RDONLY, 0)
+		require.NoError(t, err)
+		require.NoError(t, fsc.SetFileOffset(fd, 4))
+
+		require.NoError(t, fsc.Rewind(fd))
+		offset, ok := fsc.FileOffset(fd)
+		require.True(t, ok)
+		require.Zero(t, offset)
+	})
+
+	t.Run("dir", func(t *testing.T) {
+		fd, err := fsc.OpenFile("dir", os.O_RDONLY, 0)
+		require.NoError(t, err)
+		f, ok := fsc.LookupFile(fd)
+		require.True(t, ok)
+		entries, err := f.File.(fs.ReadDirFile).ReadDir(-1)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(entries))
+		f.ReadDir = &ReadDir{CountRead: 1}
+
+		require.NoError(t, fsc.Rewind(fd))
+		require.Nil(t, f.ReadDir)
+		entries, err = f.File.(fs.ReadDirFile).ReadDir(-1)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(entries))
+	})
+
+	t.Run("not open", func(t *testing.T) {
+		require.Equal(t, syscall.EBADF, fsc.Rewind(42))
+	})
+
+	t.Run("not seekable", func(t *testing.T) {
+		require.Equal(t, syscall.ESPIPE, fsc.Rewind(FdStdout))
+	})
+}
+
+func TestSynthesizeInode(t *testing.T) {
+	// The root has the same inode regardless of how it is written.
+	root := SynthesizeInode("")
+	require.Equal(t, root, SynthesizeInode("."))
+	require.Equal(t, root, SynthesizeInode("/"))
+
+	// Paths are cleaned before hashing.
+	require.Equal(t, SynthesizeInode("sub/test.txt"), SynthesizeInode("sub/./test.txt"))
+
+	// Different paths have different inodes.
+	require.NotEqual(t, root, SynthesizeInode("animals.txt"))
+	require.NotEqual(t, SynthesizeInode("sub"), SynthesizeInode("sub/test.txt"))
+}
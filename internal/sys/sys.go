@@ -27,6 +27,24 @@ type Context struct {
 	nanosleep          *sys.Nanosleep
 	randSource         io.Reader
 	fsc                *FSContext
+
+	// walltimeDefault and nanotimeDefault are true when the corresponding
+	// clock wasn't configured.
+	walltimeDefault, nanotimeDefault bool
+
+	// StrictClocks makes clocks left at their defaults unsupported.
+	// See wazero.RuntimeConfig WithStrictClocks
+	StrictClocks bool
+
+	// PollSubscriptionLimit when positive overrides the default limit of
+	// subscriptions per poll.
+	// See wazero.ModuleConfig WithPollSubscriptionLimit
+	PollSubscriptionLimit uint32
+
+	// SysNanosleep is true when Nanosleep is time.Sleep, so callers can use a
+	// timer instead, which can be stopped.
+	// See wazero.ModuleConfig WithSysNanosleep
+	SysNanosleep bool
 }
 
 // Args is like os.Args and defaults to nil.
@@ -78,6 +96,18 @@ func (c *Context) Nanotime() int64 {
 	return (*(c.nanotime))()
 }
 
+// WalltimeSupported returns false when StrictClocks is set and Walltime
+// wasn't configured.
+func (c *Context) WalltimeSupported() bool {
+	return !c.StrictClocks || !c.walltimeDefault
+}
+
+// NanotimeSupported returns false when StrictClocks is set and Nanotime
+// wasn't configured.
+func (c *Context) NanotimeSupported() bool {
+	return !c.StrictClocks || !c.nanotimeDefault
+}
+
 // NanotimeResolution returns resolution of Nanotime.
 func (c *Context) NanotimeResolution() sys.ClockResolution {
 	return c.nanotimeResolution
@@ -162,6 +192,7 @@ func NewContext(
 	} else {
 		sysCtx.walltime = platform.NewFakeWalltime()
 		sysCtx.walltimeResolution = sys.ClockResolution(time.Microsecond.Nanoseconds())
+		sysCtx.walltimeDefault = true
 	}
 
 	if nanotime != nil {
@@ -173,6 +204,7 @@ func NewContext(
 	} else {
 		sysCtx.nanotime = platform.NewFakeNanotime()
 		sysCtx.nanotimeResolution = sys.ClockResolution(time.Nanosecond)
+		sysCtx.nanotimeDefault = true
 	}
 
 	if nanosleep != nil {
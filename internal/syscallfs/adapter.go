@@ -38,12 +38,24 @@ func (ro *adapter) Path() string {
 
 // OpenFile implements FS.OpenFile
 func (ro *adapter) OpenFile(path string, flag int, perm fs.FileMode) (fs.File, error) {
+	// Like os.OpenFile, a trailing slash requires the path to be a directory.
+	dirOnly := len(path) > 1 && path[len(path)-1] == '/'
 	path = cleanPath(path)
 	f, err := ro.fs.Open(path)
 
 	if err != nil {
 		return nil, err
-	} else if osF, ok := f.(*os.File); ok {
+	} else if dirOnly {
+		if st, err := f.Stat(); err != nil {
+			_ = f.Close()
+			return nil, err
+		} else if !st.IsDir() {
+			_ = f.Close()
+			return nil, syscall.ENOTDIR
+		}
+	}
+
+	if osF, ok := f.(*os.File); ok {
 		// If this is an OS file, it has same portability issues as dirFS.
 		return maybeWrapFile(osF), nil
 	}
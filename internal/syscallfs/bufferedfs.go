@@ -0,0 +1,116 @@
+package syscallfs
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// NewBufferedFS buffers writes to host files opened for writing by the input
+// FS, using a buffer of the given size. This reduces the amount of syscalls
+// made for guests that write a small amount of bytes at a time, such as one.
+//
+// Buffered writes are flushed when the file is closed or synced, which
+// includes when the module is closed. They are also flushed before any
+// operation that observes the file, such as a read, seek or stat.
+//
+// Note: Files which are not *os.File, or not opened for writing, are returned
+// as-is.
+func NewBufferedFS(fs FS, size int) FS {
+	return &bufferedFS{FS: fs, size: size}
+}
+
+type bufferedFS struct {
+	FS
+	size int
+}
+
+// Open implements the same method as documented on fs.FS
+func (b *bufferedFS) Open(name string) (fs.File, error) {
+	panic(fmt.Errorf("unexpected to call fs.FS.Open(%s)", name))
+}
+
+// OpenFile implements FS.OpenFile
+func (b *bufferedFS) OpenFile(path string, flag int, perm fs.FileMode) (fs.File, error) {
+	f, err := b.FS.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	switch flag & (os.O_RDONLY | os.O_WRONLY | os.O_RDWR) {
+	case os.O_WRONLY, os.O_RDWR:
+		if osf, ok := f.(*os.File); ok {
+			return &bufferedFile{file: osf, w: bufio.NewWriterSize(osf, b.size)}, nil
+		}
+	}
+	return f, nil
+}
+
+// bufferedFile buffers writes to the underlying file until it is observed.
+type bufferedFile struct {
+	file *os.File
+	w    *bufio.Writer
+}
+
+// Stat implements fs.File
+func (f *bufferedFile) Stat() (fs.FileInfo, error) {
+	if err := f.w.Flush(); err != nil {
+		return nil, err
+	}
+	return f.file.Stat()
+}
+
+// Read implements fs.File
+func (f *bufferedFile) Read(p []byte) (int, error) {
+	if err := f.w.Flush(); err != nil {
+		return 0, err
+	}
+	return f.file.Read(p)
+}
+
+// ReadAt implements io.ReaderAt
+func (f *bufferedFile) ReadAt(p []byte, off int64) (int, error) {
+	if err := f.w.Flush(); err != nil {
+		return 0, err
+	}
+	return f.file.ReadAt(p, off)
+}
+
+// Seek implements io.Seeker
+func (f *bufferedFile) Seek(offset int64, whence int) (int64, error) {
+	if err := f.w.Flush(); err != nil {
+		return 0, err
+	}
+	return f.file.Seek(offset, whence)
+}
+
+// Write implements io.Writer
+func (f *bufferedFile) Write(p []byte) (int, error) {
+	return f.w.Write(p)
+}
+
+// WriteAt implements io.WriterAt
+func (f *bufferedFile) WriteAt(p []byte, off int64) (int, error) {
+	if err := f.w.Flush(); err != nil {
+		return 0, err
+	}
+	return f.file.WriteAt(p, off)
+}
+
+// Sync flushes buffered writes before syncing the underlying file.
+func (f *bufferedFile) Sync() error {
+	if err := f.w.Flush(); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+// Close implements fs.File
+func (f *bufferedFile) Close() error {
+	err := f.w.Flush()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
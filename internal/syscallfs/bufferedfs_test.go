@@ -0,0 +1,65 @@
+package syscallfs
+
+import (
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestNewBufferedFS(t *testing.T) {
+	tmpDir := t.TempDir()
+	dirFS, err := NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	testFS := NewBufferedFS(dirFS, 4096)
+
+	t.Run("flush on close", func(t *testing.T) {
+		f, err := testFS.OpenFile("close", os.O_RDWR|os.O_CREATE, 0o600)
+		require.NoError(t, err)
+
+		for _, b := range []byte("wazero") {
+			n, err := f.(io.Writer).Write([]byte{b})
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+		}
+
+		// Nothing is written to the host until the file is closed.
+		b, err := os.ReadFile(path.Join(tmpDir, "close"))
+		require.NoError(t, err)
+		require.Zero(t, len(b))
+
+		require.NoError(t, f.Close())
+
+		b, err = os.ReadFile(path.Join(tmpDir, "close"))
+		require.NoError(t, err)
+		require.Equal(t, "wazero", string(b))
+	})
+
+	t.Run("flush on seek", func(t *testing.T) {
+		f, err := testFS.OpenFile("seek", os.O_RDWR|os.O_CREATE, 0o600)
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = f.(io.Writer).Write([]byte("wazero"))
+		require.NoError(t, err)
+
+		_, err = f.(io.Seeker).Seek(0, io.SeekStart)
+		require.NoError(t, err)
+
+		b, err := io.ReadAll(f)
+		require.NoError(t, err)
+		require.Equal(t, "wazero", string(b))
+	})
+
+	t.Run("read-only not buffered", func(t *testing.T) {
+		f, err := testFS.OpenFile("close", os.O_RDONLY, 0)
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, ok := f.(*os.File)
+		require.True(t, ok)
+	})
+}
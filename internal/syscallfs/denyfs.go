@@ -0,0 +1,38 @@
+package syscallfs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ErrNotCapable is returned when an operation is denied by the host, as
+// opposed to by file permissions. For example, NewDenyOpenFS returns this.
+var ErrNotCapable = errors.New("not capable")
+
+// NewDenyOpenFS rejects OpenFile with any of the denied flags, such as
+// os.O_CREATE or os.O_APPEND, regardless of whether the input FS is writable.
+// Denied opens fail with ErrNotCapable before reaching the input FS.
+//
+// Note: os.O_RDONLY is zero, so cannot be denied.
+func NewDenyOpenFS(fs FS, denied int) FS {
+	return &denyOpenFS{FS: fs, denied: denied}
+}
+
+type denyOpenFS struct {
+	FS
+	denied int
+}
+
+// Open implements the same method as documented on fs.FS
+func (d *denyOpenFS) Open(name string) (fs.File, error) {
+	panic(fmt.Errorf("unexpected to call fs.FS.Open(%s)", name))
+}
+
+// OpenFile implements FS.OpenFile
+func (d *denyOpenFS) OpenFile(path string, flag int, perm fs.FileMode) (fs.File, error) {
+	if flag&d.denied != 0 {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: ErrNotCapable}
+	}
+	return d.FS.OpenFile(path, flag, perm)
+}
@@ -0,0 +1,33 @@
+package syscallfs
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestNewDenyOpenFS(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(tmpDir, "animals.txt"), []byte("bear"), 0o600))
+
+	dirFS, err := NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	testFS := NewDenyOpenFS(dirFS, os.O_CREATE|os.O_APPEND)
+
+	t.Run("denied", func(t *testing.T) {
+		_, err := testFS.OpenFile("creat", os.O_RDWR|os.O_CREATE, 0o600)
+		require.True(t, errors.Is(err, ErrNotCapable))
+
+		// The file wasn't created.
+		_, err = os.Stat(path.Join(tmpDir, "creat"))
+		require.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		requireFileContents(t, testFS, "animals.txt", "bear")
+	})
+}
@@ -0,0 +1,132 @@
+package syscallfs
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+const (
+	devNullPath = "dev/null"
+	devZeroPath = "dev/zero"
+)
+
+// NewDevFS synthesizes the character devices "/dev/null" and "/dev/zero" on
+// top of the input FS. Any other path falls through to the input FS.
+//
+//   - "/dev/null" reads EOF and discards writes.
+//   - "/dev/zero" reads an infinite amount of zero bytes and discards writes.
+//
+// Guests commonly open these, even when the host filesystem doesn't have
+// them, such as on Windows.
+func NewDevFS(fs FS) FS {
+	return &devFS{fs}
+}
+
+type devFS struct{ fs FS }
+
+// Open implements the same method as documented on fs.FS
+func (d *devFS) Open(name string) (fs.File, error) {
+	panic(fmt.Errorf("unexpected to call fs.FS.Open(%s)", name))
+}
+
+// Path implements FS.Path
+func (d *devFS) Path() string {
+	return d.fs.Path()
+}
+
+// OpenFile implements FS.OpenFile
+func (d *devFS) OpenFile(path string, flag int, perm fs.FileMode) (fs.File, error) {
+	switch path {
+	case devNullPath:
+		return &devFile{name: "null"}, nil
+	case devZeroPath:
+		return &devFile{name: "zero", zero: true}, nil
+	}
+	return d.fs.OpenFile(path, flag, perm)
+}
+
+// Mkdir implements FS.Mkdir
+func (d *devFS) Mkdir(path string, perm fs.FileMode) error {
+	if isDevPath(path) {
+		return syscall.EEXIST
+	}
+	return d.fs.Mkdir(path, perm)
+}
+
+// Rename implements FS.Rename
+func (d *devFS) Rename(from, to string) error {
+	if isDevPath(from) || isDevPath(to) {
+		return syscall.EPERM
+	}
+	return d.fs.Rename(from, to)
+}
+
+// Rmdir implements FS.Rmdir
+func (d *devFS) Rmdir(path string) error {
+	if isDevPath(path) {
+		return syscall.ENOTDIR
+	}
+	return d.fs.Rmdir(path)
+}
+
+// Unlink implements FS.Unlink
+func (d *devFS) Unlink(path string) error {
+	if isDevPath(path) {
+		return syscall.EPERM
+	}
+	return d.fs.Unlink(path)
+}
+
+// Utimes implements FS.Utimes
+func (d *devFS) Utimes(path string, atimeNsec, mtimeNsec int64) error {
+	if isDevPath(path) {
+		return nil // devices have no meaningful timestamps
+	}
+	return d.fs.Utimes(path, atimeNsec, mtimeNsec)
+}
+
+func isDevPath(path string) bool {
+	return path == devNullPath || path == devZeroPath
+}
+
+// devFile is a character device which discards writes. Reads are either EOF
+// or, when zero is true, an infinite stream of zero bytes.
+type devFile struct {
+	name string
+	zero bool
+}
+
+// Stat implements fs.File
+func (f *devFile) Stat() (fs.FileInfo, error) { return devFileInfo(f.name), nil }
+
+// Read implements fs.File
+func (f *devFile) Read(p []byte) (int, error) {
+	if !f.zero {
+		return 0, io.EOF
+	}
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// Write implements io.Writer
+func (f *devFile) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// Close implements fs.File
+func (f *devFile) Close() error { return nil }
+
+// devFileInfo is the fs.FileInfo of a devFile, whose value is its name.
+type devFileInfo string
+
+func (n devFileInfo) Name() string     { return string(n) }
+func (devFileInfo) Size() int64        { return 0 }
+func (devFileInfo) Mode() fs.FileMode  { return fs.ModeCharDevice | 0o666 }
+func (devFileInfo) ModTime() time.Time { return time.Unix(0, 0) }
+func (devFileInfo) IsDir() bool        { return false }
+func (devFileInfo) Sys() interface{}   { return nil }
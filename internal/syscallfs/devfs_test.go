@@ -0,0 +1,57 @@
+package syscallfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestNewDevFS(t *testing.T) {
+	dirFS, err := NewDirFS(t.TempDir())
+	require.NoError(t, err)
+
+	testFS := NewDevFS(dirFS)
+
+	t.Run("/dev/null", func(t *testing.T) {
+		f, err := testFS.OpenFile("dev/null", os.O_RDWR, 0)
+		require.NoError(t, err)
+		defer f.Close()
+
+		n, err := f.(io.Writer).Write([]byte("wazero"))
+		require.NoError(t, err)
+		require.Equal(t, 6, n)
+
+		n, err = f.Read(make([]byte, 10))
+		require.Equal(t, io.EOF, err)
+		require.Zero(t, n)
+
+		st, err := f.Stat()
+		require.NoError(t, err)
+		require.Equal(t, fs.ModeCharDevice, st.Mode().Type())
+	})
+
+	t.Run("/dev/zero", func(t *testing.T) {
+		f, err := testFS.OpenFile("dev/zero", os.O_RDONLY, 0)
+		require.NoError(t, err)
+		defer f.Close()
+
+		buf := []byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+		n, err := f.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, 10, n)
+		require.Equal(t, make([]byte, 10), buf)
+	})
+
+	t.Run("fall through", func(t *testing.T) {
+		_, err := testFS.OpenFile("dev/random", os.O_RDONLY, 0)
+		requireErrno(t, syscall.ENOENT, err)
+	})
+
+	t.Run("unlink", func(t *testing.T) {
+		requireErrno(t, syscall.EPERM, testFS.Unlink("dev/null"))
+	})
+}
@@ -186,6 +186,31 @@ func TestDirFS_Rename(t *testing.T) {
 		require.NoError(t, err)
 		require.False(t, s.IsDir())
 	})
+	t.Run("dir to non-empty dir", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		testFS, err := NewDirFS(tmpDir)
+		require.NoError(t, err)
+
+		dir1 := "dir1"
+		dir1Path := pathutil.Join(tmpDir, dir1)
+		require.NoError(t, os.Mkdir(dir1Path, 0o700))
+
+		dir2 := "dir2"
+		dir2Path := pathutil.Join(tmpDir, dir2)
+		require.NoError(t, os.Mkdir(dir2Path, 0o700))
+
+		// add a file to the target directory
+		require.NoError(t, os.WriteFile(pathutil.Join(dir2Path, "file2"), []byte{2}, 0o600))
+
+		err = testFS.Rename(dir1, dir2)
+		require.Equal(t, syscall.ENOTEMPTY, err)
+
+		// Show neither directory changed
+		_, err = os.Stat(dir1Path)
+		require.NoError(t, err)
+		_, err = os.Stat(pathutil.Join(dir2Path, "file2"))
+		require.NoError(t, err)
+	})
 	t.Run("file to file", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		testFS, err := NewDirFS(tmpDir)
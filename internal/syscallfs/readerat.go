@@ -0,0 +1,183 @@
+package syscallfs
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+	"time"
+)
+
+// NewReaderAtFS returns a read-only FS with a single file `name` whose
+// content is the first `size` bytes of `ra`. This exposes a large blob, such
+// as a memory-mapped database, to the guest without copying it.
+//
+// Reads at an offset, such as fd_pread, delegate directly to io.ReaderAt,
+// while reads and seeks track an offset per open file.
+func NewReaderAtFS(name string, ra io.ReaderAt, size int64) FS {
+	return &readerAtFS{name: name, ra: ra, size: size}
+}
+
+type readerAtFS struct {
+	name string
+	ra   io.ReaderAt
+	size int64
+}
+
+// Open implements the same method as documented on fs.FS
+func (r *readerAtFS) Open(name string) (fs.File, error) {
+	panic(fmt.Errorf("unexpected to call fs.FS.Open(%s)", name))
+}
+
+// Path implements FS.Path
+func (r *readerAtFS) Path() string {
+	return "/"
+}
+
+// OpenFile implements FS.OpenFile
+func (r *readerAtFS) OpenFile(path string, flag int, perm fs.FileMode) (fs.File, error) {
+	// Like readFS, return ENOSYS if opened for anything except reads.
+	switch flag & (os.O_RDONLY | os.O_WRONLY | os.O_RDWR) {
+	case os.O_WRONLY, os.O_RDWR:
+		return nil, syscall.ENOSYS
+	}
+
+	switch path {
+	case ".", "":
+		return &readerAtDir{fs: r}, nil
+	case r.name:
+		return &readerAtFile{fs: r}, nil
+	}
+	return nil, syscall.ENOENT
+}
+
+// Mkdir implements FS.Mkdir
+func (r *readerAtFS) Mkdir(path string, perm fs.FileMode) error {
+	return syscall.ENOSYS
+}
+
+// Rename implements FS.Rename
+func (r *readerAtFS) Rename(from, to string) error {
+	return syscall.ENOSYS
+}
+
+// Rmdir implements FS.Rmdir
+func (r *readerAtFS) Rmdir(path string) error {
+	return syscall.ENOSYS
+}
+
+// Unlink implements FS.Unlink
+func (r *readerAtFS) Unlink(path string) error {
+	return syscall.ENOSYS
+}
+
+// Utimes implements FS.Utimes
+func (r *readerAtFS) Utimes(path string, atimeNsec, mtimeNsec int64) error {
+	return syscall.ENOSYS
+}
+
+// readerAtFile is the single file of a readerAtFS.
+type readerAtFile struct {
+	fs     *readerAtFS
+	offset int64
+}
+
+// Stat implements fs.File
+func (f *readerAtFile) Stat() (fs.FileInfo, error) {
+	return readerAtFileInfo{name: f.fs.name, size: f.fs.size, mode: 0o444}, nil
+}
+
+// Read implements fs.File
+func (f *readerAtFile) Read(p []byte) (n int, err error) {
+	n, err = f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil // unlike io.ReaderAt, io.Reader returns EOF on the next read.
+	}
+	return
+}
+
+// ReadAt implements io.ReaderAt
+func (f *readerAtFile) ReadAt(p []byte, off int64) (n int, err error) {
+	if off >= f.fs.size {
+		return 0, io.EOF
+	}
+	l := len(p)
+	if remaining := f.fs.size - off; int64(l) > remaining {
+		p = p[:remaining]
+	}
+	// io.ReaderAt requires an error when reading less than len(p).
+	if n, err = f.fs.ra.ReadAt(p, off); err == nil && n < l {
+		err = io.EOF
+	}
+	return
+}
+
+// Seek implements io.Seeker
+func (f *readerAtFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.fs.size
+	default:
+		return 0, syscall.EINVAL
+	}
+	if offset < 0 {
+		return 0, syscall.EINVAL
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// Close implements fs.File
+func (f *readerAtFile) Close() error { return nil }
+
+// readerAtDir is the root directory of a readerAtFS, which only contains the
+// single file.
+type readerAtDir struct {
+	fs   *readerAtFS
+	read bool
+}
+
+// Stat implements fs.File
+func (d *readerAtDir) Stat() (fs.FileInfo, error) {
+	return readerAtFileInfo{name: ".", mode: fs.ModeDir | 0o555}, nil
+}
+
+// Read implements fs.File
+func (d *readerAtDir) Read([]byte) (int, error) {
+	return 0, syscall.EISDIR
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *readerAtDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.read {
+		if n > 0 {
+			return nil, io.EOF
+		}
+		return nil, nil
+	}
+	d.read = true
+	info := readerAtFileInfo{name: d.fs.name, size: d.fs.size, mode: 0o444}
+	return []fs.DirEntry{fs.FileInfoToDirEntry(info)}, nil
+}
+
+// Close implements fs.File
+func (d *readerAtDir) Close() error { return nil }
+
+// readerAtFileInfo is the fs.FileInfo of a file or directory in a readerAtFS.
+type readerAtFileInfo struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func (i readerAtFileInfo) Name() string      { return i.name }
+func (i readerAtFileInfo) Size() int64       { return i.size }
+func (i readerAtFileInfo) Mode() fs.FileMode { return i.mode }
+func (readerAtFileInfo) ModTime() time.Time  { return time.Unix(0, 0) }
+func (i readerAtFileInfo) IsDir() bool       { return i.mode.IsDir() }
+func (readerAtFileInfo) Sys() interface{}    { return nil }
@@ -0,0 +1,95 @@
+package syscallfs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestNewReaderAtFS(t *testing.T) {
+	blob := make([]byte, 1024*1024)
+	for i := range blob {
+		blob[i] = byte(i)
+	}
+	testFS := NewReaderAtFS("blob", bytes.NewReader(blob), int64(len(blob)))
+	require.True(t, IsReadOnly(testFS))
+
+	t.Run("ReadAt", func(t *testing.T) {
+		f, err := testFS.OpenFile("blob", os.O_RDONLY, 0)
+		require.NoError(t, err)
+		defer f.Close()
+
+		buf := make([]byte, 16)
+		n, err := f.(io.ReaderAt).ReadAt(buf, 512*1024)
+		require.NoError(t, err)
+		require.Equal(t, 16, n)
+		require.Equal(t, blob[512*1024:512*1024+16], buf)
+
+		// Reading across the size is EOF, as ReadAt must return an error
+		// when it reads less than len(buf).
+		n, err = f.(io.ReaderAt).ReadAt(buf, int64(len(blob)-4))
+		require.Equal(t, io.EOF, err)
+		require.Equal(t, 4, n)
+		require.Equal(t, blob[len(blob)-4:], buf[:n])
+
+		// Reading past the size is EOF.
+		_, err = f.(io.ReaderAt).ReadAt(buf, int64(len(blob)))
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("Seek and Read", func(t *testing.T) {
+		f, err := testFS.OpenFile("blob", os.O_RDONLY, 0)
+		require.NoError(t, err)
+		defer f.Close()
+
+		off, err := f.(io.Seeker).Seek(-4, io.SeekEnd)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(blob)-4), off)
+
+		buf := make([]byte, 16)
+		n, err := f.Read(buf)
+		require.NoError(t, err)
+		require.Equal(t, blob[len(blob)-4:], buf[:n])
+
+		_, err = f.Read(buf)
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		st, err := StatPath(testFS, "blob")
+		require.NoError(t, err)
+		require.Equal(t, int64(len(blob)), st.Size())
+		require.True(t, st.Mode().IsRegular())
+	})
+
+	t.Run("ReadDir", func(t *testing.T) {
+		f, err := testFS.OpenFile(".", os.O_RDONLY, 0)
+		require.NoError(t, err)
+		defer f.Close()
+
+		entries, err := f.(fs.ReadDirFile).ReadDir(-1)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(entries))
+		require.Equal(t, "blob", entries[0].Name())
+
+		entries, err = f.(fs.ReadDirFile).ReadDir(1)
+		require.Equal(t, io.EOF, err)
+		require.Zero(t, len(entries))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := testFS.OpenFile("other", os.O_RDONLY, 0)
+		requireErrno(t, syscall.ENOENT, err)
+	})
+
+	t.Run("write", func(t *testing.T) {
+		_, err := testFS.OpenFile("blob", os.O_RDWR, 0)
+		requireErrno(t, syscall.ENOSYS, err)
+		requireErrno(t, syscall.ENOSYS, testFS.Unlink("blob"))
+	})
+}
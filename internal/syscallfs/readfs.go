@@ -21,6 +21,27 @@ func NewReadFS(fs FS) FS {
 
 type readFS struct{ fs FS }
 
+// IsReadOnly returns true if the input FS cannot be written, because it was
+// masked with NewReadFS or adapted from a fs.FS. Wrappers in this package are
+// read-only when the FS they wrap is.
+func IsReadOnly(fs FS) bool {
+	switch fs := fs.(type) {
+	case *readFS, *adapter, *readerAtFS:
+		return true
+	case *bufferedFS:
+		return IsReadOnly(fs.FS)
+	case *devFS:
+		return IsReadOnly(fs.fs)
+	case *remapFS:
+		return IsReadOnly(fs.fs)
+	case *seedFS:
+		return IsReadOnly(fs.FS)
+	case *denyOpenFS:
+		return IsReadOnly(fs.FS)
+	}
+	return false
+}
+
 // Open implements the same method as documented on fs.FS
 func (r *readFS) Open(name string) (fs.File, error) {
 	panic(fmt.Errorf("unexpected to call fs.FS.Open(%s)", name))
@@ -99,3 +99,19 @@ func TestReadFS_TestFS(t *testing.T) {
 	// Run TestFS via the adapter
 	require.NoError(t, fstest.TestFS(&testFSAdapter{testFS}))
 }
+
+func TestIsReadOnly(t *testing.T) {
+	dirFS, err := NewDirFS(t.TempDir())
+	require.NoError(t, err)
+	readFS := NewReadFS(dirFS)
+
+	require.False(t, IsReadOnly(dirFS))
+	require.True(t, IsReadOnly(readFS))
+	require.True(t, IsReadOnly(Adapt(fstest.FS)))
+
+	// Wrappers are read-only when what they wrap is.
+	require.False(t, IsReadOnly(NewDevFS(dirFS)))
+	require.True(t, IsReadOnly(NewDevFS(readFS)))
+	require.True(t, IsReadOnly(NewBufferedFS(readFS, 4096)))
+	require.True(t, IsReadOnly(NewSeedFS(readFS, nil)))
+}
@@ -0,0 +1,87 @@
+package syscallfs
+
+import (
+	"fmt"
+	"io/fs"
+	"syscall"
+)
+
+// RemapFunc returns the path and file system to resolve a guest path with, or
+// false if the path should resolve against the wrapped file system.
+//
+// Note: The input is relative to the file system, e.g. "config/app.toml", not
+// "/config/app.toml".
+type RemapFunc func(guestPath string) (realPath string, fs fs.FS, ok bool)
+
+// NewRemapFS intercepts paths before they resolve against the input FS. This
+// allows special-casing guest paths, such as "/dev/urandom", or mapping a
+// sub-directory, such as "/config", to a different host directory.
+//
+// Any path not matched by the RemapFunc falls through to the input FS.
+func NewRemapFS(fs FS, remap RemapFunc) FS {
+	return &remapFS{fs: fs, remap: remap}
+}
+
+type remapFS struct {
+	fs    FS
+	remap RemapFunc
+}
+
+// resolve returns the file system and path to use for the given guest path.
+func (r *remapFS) resolve(path string) (FS, string, bool) {
+	if realPath, mapped, ok := r.remap(path); ok {
+		return Adapt(mapped), realPath, true
+	}
+	return r.fs, path, false
+}
+
+// Open implements the same method as documented on fs.FS
+func (r *remapFS) Open(name string) (fs.File, error) {
+	panic(fmt.Errorf("unexpected to call fs.FS.Open(%s)", name))
+}
+
+// Path implements FS.Path
+func (r *remapFS) Path() string {
+	return r.fs.Path()
+}
+
+// OpenFile implements FS.OpenFile
+func (r *remapFS) OpenFile(path string, flag int, perm fs.FileMode) (fs.File, error) {
+	fs, path, _ := r.resolve(path)
+	return fs.OpenFile(path, flag, perm)
+}
+
+// Mkdir implements FS.Mkdir
+func (r *remapFS) Mkdir(path string, perm fs.FileMode) error {
+	fs, path, _ := r.resolve(path)
+	return fs.Mkdir(path, perm)
+}
+
+// Rename implements FS.Rename
+func (r *remapFS) Rename(from, to string) error {
+	fromFS, from, fromMapped := r.resolve(from)
+	_, to, toMapped := r.resolve(to)
+	// Like a mount point, a remapped path cannot be renamed across.
+	if fromMapped || toMapped {
+		return syscall.EXDEV
+	}
+	return fromFS.Rename(from, to)
+}
+
+// Rmdir implements FS.Rmdir
+func (r *remapFS) Rmdir(path string) error {
+	fs, path, _ := r.resolve(path)
+	return fs.Rmdir(path)
+}
+
+// Unlink implements FS.Unlink
+func (r *remapFS) Unlink(path string) error {
+	fs, path, _ := r.resolve(path)
+	return fs.Unlink(path)
+}
+
+// Utimes implements FS.Utimes
+func (r *remapFS) Utimes(path string, atimeNsec, mtimeNsec int64) error {
+	fs, path, _ := r.resolve(path)
+	return fs.Utimes(path, atimeNsec, mtimeNsec)
+}
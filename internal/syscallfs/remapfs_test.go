@@ -0,0 +1,56 @@
+package syscallfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"syscall"
+	"testing"
+	gofstest "testing/fstest"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestNewRemapFS(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(tmpDir, "animals.txt"), []byte("bear"), 0o600))
+
+	configFS := gofstest.MapFS{"app.toml": &gofstest.MapFile{Data: []byte("debug = true")}}
+	dirFS, err := NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	testFS := NewRemapFS(dirFS, func(guestPath string) (string, fs.FS, bool) {
+		if guestPath == "config/app.toml" {
+			return "app.toml", configFS, true
+		}
+		return "", nil, false
+	})
+
+	t.Run("Path", func(t *testing.T) {
+		require.Equal(t, dirFS.Path(), testFS.Path())
+	})
+
+	t.Run("remapped", func(t *testing.T) {
+		requireFileContents(t, testFS, "config/app.toml", "debug = true")
+	})
+
+	t.Run("fall through", func(t *testing.T) {
+		requireFileContents(t, testFS, "animals.txt", "bear")
+	})
+
+	t.Run("rename across remap", func(t *testing.T) {
+		err := testFS.Rename("animals.txt", "config/app.toml")
+		requireErrno(t, syscall.EXDEV, err)
+	})
+}
+
+func requireFileContents(t *testing.T, testFS FS, path, expected string) {
+	f, err := testFS.OpenFile(path, os.O_RDONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(b))
+}
@@ -0,0 +1,78 @@
+package syscallfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// SeedFunc returns the initial content of a file created at the guest path,
+// or false if it should be created empty.
+//
+// Note: The input is relative to the file system, e.g. "lock", not "/lock".
+type SeedFunc func(guestPath string) (initialContent []byte, ok bool)
+
+// NewSeedFS writes initial content to files newly created by OpenFile with
+// os.O_CREATE, before returning them. For example, this allows a lock-file to
+// start with a magic value. Files that already existed are not changed.
+//
+// Creation is detected atomically by opening with os.O_EXCL first, so a file
+// created concurrently by another process is never overwritten.
+func NewSeedFS(fs FS, seed SeedFunc) FS {
+	return &seedFS{FS: fs, seed: seed}
+}
+
+type seedFS struct {
+	FS
+	seed SeedFunc
+}
+
+// Open implements the same method as documented on fs.FS
+func (s *seedFS) Open(name string) (fs.File, error) {
+	panic(fmt.Errorf("unexpected to call fs.FS.Open(%s)", name))
+}
+
+// OpenFile implements FS.OpenFile
+func (s *seedFS) OpenFile(path string, flag int, perm fs.FileMode) (fs.File, error) {
+	if flag&os.O_CREATE == 0 {
+		return s.FS.OpenFile(path, flag, perm)
+	}
+	content, ok := s.seed(path)
+	if !ok {
+		return s.FS.OpenFile(path, flag, perm)
+	}
+
+	f, err := s.FS.OpenFile(path, flag|os.O_EXCL, perm)
+	if flag&os.O_EXCL == 0 && errors.Is(err, syscall.EEXIST) {
+		return s.FS.OpenFile(path, flag, perm) // not created, so don't seed.
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = seedFile(f, content); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// seedFile writes the content to the newly created file, then rewinds it so
+// that the caller can read the content back.
+func seedFile(f fs.File, content []byte) error {
+	w, ok := f.(io.Writer)
+	if !ok {
+		return syscall.EBADF
+	}
+	if _, err := w.Write(content); err != nil {
+		return err
+	}
+	if s, ok := f.(io.Seeker); ok {
+		if _, err := s.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package syscallfs
+
+import (
+	"os"
+	"path"
+	"syscall"
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestNewSeedFS(t *testing.T) {
+	tmpDir := t.TempDir()
+	dirFS, err := NewDirFS(tmpDir)
+	require.NoError(t, err)
+
+	testFS := NewSeedFS(dirFS, func(guestPath string) ([]byte, bool) {
+		if guestPath == "lock" {
+			return []byte("LOCK"), true
+		}
+		return nil, false
+	})
+
+	t.Run("seeded on create", func(t *testing.T) {
+		f, err := testFS.OpenFile("lock", os.O_RDWR|os.O_CREATE, 0o600)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		requireFileContents(t, testFS, "lock", "LOCK")
+	})
+
+	t.Run("not seeded when exists", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path.Join(tmpDir, "lock"), []byte("1234"), 0o600))
+
+		f, err := testFS.OpenFile("lock", os.O_RDWR|os.O_CREATE, 0o600)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		requireFileContents(t, testFS, "lock", "1234")
+	})
+
+	t.Run("O_EXCL when exists", func(t *testing.T) {
+		_, err := testFS.OpenFile("lock", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+		requireErrno(t, syscall.EEXIST, err)
+	})
+
+	t.Run("other files empty", func(t *testing.T) {
+		f, err := testFS.OpenFile("other", os.O_RDWR|os.O_CREATE, 0o600)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		requireFileContents(t, testFS, "other", "")
+	})
+}
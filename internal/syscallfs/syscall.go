@@ -20,7 +20,13 @@ func adjustUnlinkError(err error) error {
 }
 
 func rename(old, new string) error {
-	return syscall.Rename(old, new)
+	err := syscall.Rename(old, new)
+	// POSIX allows EEXIST or ENOTEMPTY when new is a non-empty directory, so
+	// consistently use the latter.
+	if err == syscall.EEXIST {
+		return syscall.ENOTEMPTY
+	}
+	return err
 }
 
 func maybeWrapFile(f file) file {
@@ -74,6 +74,10 @@ func rename(old, new string) (err error) {
 		}
 
 		if oldIsDir && newIsDir {
+			// Like POSIX, a non-empty directory cannot be overwritten.
+			if !isEmptyDir(new) {
+				return syscall.ENOTEMPTY
+			}
 			// Windows doesn't let you overwrite a directory. If we aim to
 			// allow this, we'll have to delete here and retry.
 			return syscall.EINVAL
@@ -86,6 +90,17 @@ func rename(old, new string) (err error) {
 	return
 }
 
+// isEmptyDir returns true if the directory has no entries.
+func isEmptyDir(dir string) bool {
+	f, err := os.Open(dir)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	_, err = f.Readdirnames(1)
+	return err == io.EOF
+}
+
 // maybeWrapFile deals with errno portability issues in Windows. This code is
 // likely to change as we complete syscall support needed for WASI and GOOS=js.
 //
@@ -71,6 +71,8 @@ type FS interface {
 	//   - syscall.ENOENT: `from` or `to` don't exist.
 	//   - syscall.ENOTDIR: `from` is a directory and `to` exists, but is a file.
 	//   - syscall.EISDIR: `from` is a file and `to` exists, but is a directory.
+	//   - syscall.ENOTEMPTY: `from` is a directory and `to` exists, but is a
+	//     non-empty directory.
 	//
 	// # Notes
 	//
@@ -88,6 +88,21 @@ func testOpen_Read(t *testing.T, tmpDir string, testFS FS) {
 		require.Equal(t, file1, entries[0].Name())
 	})
 
+	t.Run("dir with trailing slash", func(t *testing.T) {
+		f, err := testFS.OpenFile(dir+"/", os.O_RDONLY, 0)
+		require.NoError(t, err)
+		defer f.Close()
+
+		st, err := f.Stat()
+		require.NoError(t, err)
+		require.True(t, st.IsDir())
+	})
+
+	t.Run("file with trailing slash", func(t *testing.T) {
+		_, err := testFS.OpenFile(file+"/", os.O_RDONLY, 0)
+		requireErrno(t, syscall.ENOTDIR, err)
+	})
+
 	t.Run("file exists", func(t *testing.T) {
 		f, err := testFS.OpenFile(file, os.O_RDONLY, 0)
 		require.NoError(t, err)
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io/fs"
 	"syscall"
+
+	"github.com/tetratelabs/wazero/internal/syscallfs"
 )
 
 // Errno is neither uint16 nor an alias for parity with wasm.ValueType.
@@ -175,9 +177,12 @@ const (
 	ErrnoTxtbsy
 	// ErrnoXdev Cross-device link.
 	ErrnoXdev
-
-	// Note: ErrnoNotcapable was removed by WASI maintainers.
+	// ErrnoNotcapable Extension: Capabilities insufficient.
+	//
+	// Note: This was removed by WASI maintainers after snapshot-01, so is
+	// only returned when the host explicitly denies an operation.
 	// See https://github.com/WebAssembly/wasi-libc/pull/294
+	ErrnoNotcapable
 )
 
 var errnoToString = [...]string{
@@ -267,6 +272,14 @@ var errnoToString = [...]string{
 // Errno.
 func ToErrno(err error) Errno {
 	switch {
+	case errors.Is(err, syscallfs.ErrNotCapable):
+		return ErrnoNotcapable
+	case errors.Is(err, syscall.EAGAIN):
+		return ErrnoAgain
+	case errors.Is(err, syscall.EPERM): // before fs.ErrPermission, which it matches
+		return ErrnoPerm
+	case errors.Is(err, syscall.EACCES), errors.Is(err, fs.ErrPermission):
+		return ErrnoAcces
 	case errors.Is(err, syscall.EBADF), errors.Is(err, fs.ErrClosed):
 		return ErrnoBadf
 	case errors.Is(err, syscall.EINVAL), errors.Is(err, fs.ErrInvalid):
@@ -283,6 +296,18 @@ func ToErrno(err error) Errno {
 		return ErrnoNosys
 	case errors.Is(err, syscall.ENOTDIR):
 		return ErrnoNotdir
+	case errors.Is(err, syscall.ENOSPC):
+		return ErrnoNospc
+	case errors.Is(err, syscall.EDQUOT):
+		return ErrnoDquot
+	case errors.Is(err, syscall.EROFS):
+		return ErrnoRofs
+	case errors.Is(err, syscall.EFBIG):
+		return ErrnoFbig
+	case errors.Is(err, syscall.ENOTSUP):
+		return ErrnoNotsup
+	case errors.Is(err, syscall.ENAMETOOLONG):
+		return ErrnoNametoolong
 	default:
 		return ErrnoIo
 	}
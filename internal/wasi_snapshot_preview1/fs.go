@@ -85,6 +85,21 @@ var fdflagNames = [...]string{
 	"SYNC",
 }
 
+// fstflags are the flags used by fd_filestat_set_times and
+// path_filestat_set_times to select which timestamps to adjust.
+// See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#-fstflags-flagsu16
+const (
+	// FSTFLAGS_ATIM sets the access time to the value of the atim parameter.
+	FSTFLAGS_ATIM uint16 = 1 << iota //nolint
+	// FSTFLAGS_ATIM_NOW sets the access time to the current walltime.
+	FSTFLAGS_ATIM_NOW
+	// FSTFLAGS_MTIM sets the modification time to the value of the mtim
+	// parameter.
+	FSTFLAGS_MTIM
+	// FSTFLAGS_MTIM_NOW sets the modification time to the current walltime.
+	FSTFLAGS_MTIM_NOW
+)
+
 // See https://github.com/WebAssembly/WASI/blob/snapshot-01/phases/snapshot/docs.md#lookupflags
 const (
 	// LOOKUP_SYMLINK_FOLLOW expands a path if it resolves into a symbolic
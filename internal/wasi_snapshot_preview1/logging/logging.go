@@ -138,7 +138,7 @@ func (i logFilestat) Log(_ context.Context, mod api.Module, w logging.Writer, pa
 		w.WriteString(",size=")              //nolint
 		writeI64(w, le.Uint64(buf[32:]))
 		w.WriteString(",mtim=") //nolint
-		writeI64(w, le.Uint64(buf[40:]))
+		writeI64(w, le.Uint64(buf[48:]))
 		w.WriteString("}") //nolint
 	}
 }
@@ -153,9 +153,9 @@ func (i logFdstat) Log(_ context.Context, mod api.Module, w logging.Writer, para
 		w.WriteString(",fdflags=")                            //nolint
 		w.WriteString(FdFlagsString(int(le.Uint16(buf[2:])))) //nolint
 		w.WriteString(",fs_rights_base=")                     //nolint
-		w.WriteString(RightsString(int(le.Uint16(buf[8:]))))  //nolint
+		w.WriteString(RightsString(int(le.Uint64(buf[8:]))))  //nolint
 		w.WriteString(",fs_rights_inheriting=")               //nolint
-		w.WriteString(RightsString(int(le.Uint16(buf[16:])))) //nolint
+		w.WriteString(RightsString(int(le.Uint64(buf[16:])))) //nolint
 		w.WriteString("}")                                    //nolint
 	}
 }
@@ -9,6 +9,10 @@ import (
 // InternalModuleName is not named ModuleName, to avoid a clash on dot imports.
 const InternalModuleName = "wasi_snapshot_preview1"
 
+// InterceptorsKey is a context.Context Value key. Its associated value is a
+// map[string]experimental.WASIInterceptor keyed by WASI function name.
+type InterceptorsKey struct{}
+
 func flagsString(names []string, f int) string {
 	var builder strings.Builder
 	first := true
@@ -2,11 +2,16 @@ package wasm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/platform"
 	internalsys "github.com/tetratelabs/wazero/internal/sys"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
 	"github.com/tetratelabs/wazero/sys"
 )
 
@@ -56,6 +61,21 @@ type CallContext struct {
 
 	// CodeCloser is non-nil when the code should be closed after this module.
 	CodeCloser api.Closer
+
+	// ExitCodePolicy is non-nil when a sys.ExitError returned from an
+	// exported function should be converted by this policy instead.
+	ExitCodePolicy func(exitCode uint32) error
+
+	// ExecutionBudget is positive when the cumulative wall-clock time of calls
+	// to exported functions is limited.
+	ExecutionBudget time.Duration
+
+	// executionMux guards executionSpent, as calls can be concurrent.
+	executionMux sync.Mutex
+	// executionSpent is the cumulative wall-clock time of calls so far.
+	executionSpent time.Duration
+	// executionNanotime when non-nil overrides platform.Nanotime for tests.
+	executionNanotime func() int64
 }
 
 // FailIfClosed returns a sys.ExitError if CloseWithExitCode was called.
@@ -95,6 +115,7 @@ func (m *CallContext) CloseWithExitCode(ctx context.Context, exitCode uint32) er
 	if !closed {
 		return nil
 	}
+	m.s.zeroDefined(m.module)
 	_ = m.s.deleteModule(m.Name())
 	if m.CodeCloser == nil {
 		return err
@@ -122,7 +143,10 @@ func (m *CallContext) close(ctx context.Context, exitCode uint32) (c bool, err e
 
 // Memory implements the same method as documented on api.Module.
 func (m *CallContext) Memory() api.Memory {
-	return m.module.Memory
+	if mem := m.module.Memory; mem != nil {
+		return mem
+	}
+	return nil // don't return a typed nil, which isn't == nil.
 }
 
 // ExportedMemory implements the same method as documented on api.Module.
@@ -150,6 +174,20 @@ func (m *CallContext) ExportedMemoryDefinitions() map[string]api.MemoryDefinitio
 	return map[string]api.MemoryDefinition{}
 }
 
+// ExportedTableDefinitions implements the same method as documented on
+// api.Module.
+func (m *CallContext) ExportedTableDefinitions() map[string]api.TableDefinition {
+	result := map[string]api.TableDefinition{}
+	for name, exp := range m.module.Exports {
+		if exp.Type == ExternTypeTable {
+			if def := m.module.Tables[exp.Index].definition; def != nil {
+				result[name] = def
+			}
+		}
+	}
+	return result
+}
+
 // ExportedFunction implements the same method as documented on api.Module.
 func (m *CallContext) ExportedFunction(name string) api.Function {
 	exp, err := m.module.getExport(name, ExternTypeFunc)
@@ -206,7 +244,87 @@ func (f *function) Definition() api.FunctionDefinition {
 
 // Call implements the same method as documented on api.Function.
 func (f *function) Call(ctx context.Context, params ...uint64) (ret []uint64, err error) {
-	return f.ce.Call(ctx, f.fi.Module.CallCtx, params)
+	callCtx := f.fi.Module.CallCtx
+	if s := callCtx.s; s != nil && s.InterruptOnContextDone && ctx.Done() != nil {
+		ctx = context.WithValue(ctx, wasmruntime.InterruptKey{}, struct{}{})
+	}
+	if callCtx.ExecutionBudget > 0 {
+		ret, err = callCtx.callWithBudget(ctx, f.ce, params)
+	} else {
+		ret, err = f.ce.Call(ctx, callCtx, params)
+	}
+	if policy := callCtx.ExitCodePolicy; policy != nil {
+		if exitErr, ok := err.(*sys.ExitError); ok {
+			// When the exit interrupted the call, there are no results, so
+			// return zeros in case the caller reads them.
+			if err = policy(exitErr.ExitCode()); err == nil && ret == nil {
+				ret = make([]uint64, len(f.fi.Type.Results))
+			}
+		}
+	}
+	return
+}
+
+// executionBudgetKey is a context.Context Value key whose value is the
+// *CallContext of a call being timed by callWithBudget.
+type executionBudgetKey struct{}
+
+// callWithBudget calls the function when ExecutionBudget isn't yet spent, and
+// traps the call that spends it.
+//
+// Note: Unless Store.InterruptOnContextDone, this doesn't interrupt a call in
+// progress, so the call which exhausts the budget returns only after it
+// completes.
+func (m *CallContext) callWithBudget(ctx context.Context, ce CallEngine, params []uint64) ([]uint64, error) {
+	// A nested call, e.g. from a host function, is timed by its caller.
+	if ctx.Value(executionBudgetKey{}) == m {
+		return ce.Call(ctx, m, params)
+	}
+
+	m.executionMux.Lock()
+	remaining := m.ExecutionBudget - m.executionSpent
+	m.executionMux.Unlock()
+	if remaining <= 0 {
+		return nil, wasmruntime.ErrRuntimeExecutionBudgetExhausted
+	}
+
+	callCtx := context.WithValue(ctx, executionBudgetKey{}, m)
+	if m.s != nil && m.s.InterruptOnContextDone {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(callCtx, remaining)
+		defer cancel()
+		callCtx = context.WithValue(callCtx, wasmruntime.InterruptKey{}, struct{}{})
+	}
+
+	nanotime := m.executionNanotime
+	if nanotime == nil {
+		nanotime = platform.Nanotime
+	}
+	start := nanotime()
+	ret, err := ce.Call(callCtx, m, params)
+	elapsed := time.Duration(nanotime() - start)
+
+	m.executionMux.Lock()
+	m.executionSpent += elapsed
+	exhausted := m.executionSpent > m.ExecutionBudget
+	m.executionMux.Unlock()
+
+	// The call crossed the budget, or was interrupted when it did.
+	if exhausted && (err == nil || (errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil)) {
+		return nil, wasmruntime.ErrRuntimeExecutionBudgetExhausted
+	}
+	return ret, err
+}
+
+// InterruptsOnContextDone returns true if a call to the function is
+// interrupted when its context is done.
+func InterruptsOnContextDone(fn api.Function) bool {
+	f, ok := fn.(*function)
+	if !ok {
+		return false
+	}
+	s := f.fi.Module.CallCtx.s
+	return s != nil && s.InterruptOnContextDone
 }
 
 // GlobalVal is an internal hack to get the lower 64 bits of a global.
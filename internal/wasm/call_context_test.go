@@ -5,11 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/tetratelabs/wazero/internal/sys"
 	testfs "github.com/tetratelabs/wazero/internal/testing/fs"
 	"github.com/tetratelabs/wazero/internal/testing/require"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
 )
 
 func TestCallContext_WithMemory(t *testing.T) {
@@ -285,3 +289,88 @@ func TestCallContext_CallDynamic(t *testing.T) {
 		require.False(t, ok, "expected no opened files")
 	})
 }
+
+// budgetCallEngine advances a fake clock by cost on each call, after calling
+// nested when non-nil.
+type budgetCallEngine struct {
+	clock  *int64
+	cost   int64
+	calls  int32
+	nested func(ctx context.Context) error
+}
+
+func (ce *budgetCallEngine) Call(ctx context.Context, _ *CallContext, _ []uint64) ([]uint64, error) {
+	atomic.AddInt32(&ce.calls, 1)
+	if ce.nested != nil {
+		if err := ce.nested(ctx); err != nil {
+			return nil, err
+		}
+	}
+	atomic.AddInt64(ce.clock, ce.cost)
+	return []uint64{1}, nil
+}
+
+func TestCallContext_callWithBudget(t *testing.T) {
+	newCallContext := func(budget int64) (*CallContext, *int64) {
+		var clock int64
+		m := &CallContext{ExecutionBudget: time.Duration(budget)}
+		m.executionNanotime = func() int64 { return atomic.LoadInt64(&clock) }
+		return m, &clock
+	}
+
+	t.Run("crossing call traps", func(t *testing.T) {
+		m, clock := newCallContext(25)
+		ce := &budgetCallEngine{clock: clock, cost: 10}
+
+		for i := 0; i < 2; i++ {
+			results, err := m.callWithBudget(testCtx, ce, nil)
+			require.NoError(t, err)
+			require.Equal(t, []uint64{1}, results)
+		}
+
+		// The third call runs, but crosses the budget.
+		_, err := m.callWithBudget(testCtx, ce, nil)
+		require.Equal(t, wasmruntime.ErrRuntimeExecutionBudgetExhausted, err)
+		require.Equal(t, int32(3), ce.calls)
+
+		// Later calls trap without running.
+		_, err = m.callWithBudget(testCtx, ce, nil)
+		require.Equal(t, wasmruntime.ErrRuntimeExecutionBudgetExhausted, err)
+		require.Equal(t, int32(3), ce.calls)
+	})
+
+	t.Run("nested call timed by its caller", func(t *testing.T) {
+		m, clock := newCallContext(25)
+		inner := &budgetCallEngine{clock: clock, cost: 10}
+		outer := &budgetCallEngine{clock: clock, cost: 10, nested: func(ctx context.Context) error {
+			_, err := m.callWithBudget(ctx, inner, nil)
+			return err
+		}}
+
+		_, err := m.callWithBudget(testCtx, outer, nil)
+		require.NoError(t, err)
+		require.Equal(t, time.Duration(20), m.executionSpent)
+	})
+
+	t.Run("concurrent calls", func(t *testing.T) {
+		m, clock := newCallContext(1000)
+		ce := &budgetCallEngine{clock: clock, cost: 1}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					_, err := m.callWithBudget(testCtx, ce, nil)
+					require.NoError(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		// Each call is timed from its own context, so none is skipped.
+		require.Equal(t, int32(40), ce.calls)
+		require.True(t, m.executionSpent >= 40, "spent %d", m.executionSpent)
+	})
+}
@@ -34,7 +34,8 @@ func (m *Module) ExportedFunctions() map[string]api.FunctionDefinition {
 // Note: This is exported for tests who don't use wazero.Runtime or
 // NewHostModule to compile the module.
 func (m *Module) BuildFunctionDefinitions() {
-	if len(m.FunctionSection) == 0 {
+	importCount := m.ImportFuncCount()
+	if importCount == 0 && len(m.FunctionSection) == 0 {
 		return
 	}
 
@@ -48,7 +49,6 @@ func (m *Module) BuildFunctionDefinitions() {
 		resultNames = m.NameSection.ResultNames
 	}
 
-	importCount := m.ImportFuncCount()
 	m.FunctionDefinitionSection = make([]*FunctionDefinition, 0, importCount+uint32(len(m.FunctionSection)))
 
 	importFuncIdx := Index(0)
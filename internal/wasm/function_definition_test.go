@@ -192,6 +192,20 @@ func TestModule_BuildFunctionDefinitions(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "only imports",
+			m: &Module{
+				TypeSection:   []*FunctionType{v_v},
+				ImportSection: []*Import{{Module: "i", Name: "f", Type: ExternTypeFunc}},
+			},
+			expected: []*FunctionDefinition{
+				{index: 0, debugName: ".$0", importDesc: &[2]string{"i", "f"}, funcType: v_v},
+			},
+			expectedImports: []api.FunctionDefinition{
+				&FunctionDefinition{index: 0, debugName: ".$0", importDesc: &[2]string{"i", "f"}, funcType: v_v},
+			},
+			expectedExports: map[string]api.FunctionDefinition{},
+		},
 		{
 			name: "with names",
 			m: &Module{
@@ -3,12 +3,15 @@ package wasm
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math"
 	"reflect"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/internal/platform"
 )
 
 const (
@@ -38,6 +41,52 @@ type MemoryInstance struct {
 	mux sync.RWMutex
 	// definition is known at compile time.
 	definition api.MemoryDefinition
+	// growthLimit when non-nil limits the pages Grow adds within a window.
+	growthLimit *growthLimit
+	// allocator when non-nil replaces the buffer when Grow exceeds Cap.
+	allocator MemoryAllocator
+}
+
+// MemoryAllocator returns a zeroed buffer of at least min bytes to back a
+// MemoryInstance. max is the most bytes the memory can grow to, so returning
+// a buffer with that capacity avoids further allocation on Grow.
+type MemoryAllocator func(min, max uint64) []byte
+
+// growthLimit limits the count of pages grown within a time window.
+type growthLimit struct {
+	pages       uint32
+	window      time.Duration
+	nanotime    func() int64
+	windowStart int64
+	grown       uint32
+}
+
+// allow returns true and records the delta if growing by it doesn't exceed
+// the limit of the current window.
+func (l *growthLimit) allow(delta uint32) bool {
+	now := l.nanotime()
+	if time.Duration(now-l.windowStart) >= l.window {
+		l.windowStart = now
+		l.grown = 0
+	}
+	if uint64(l.grown)+uint64(delta) > uint64(l.pages) {
+		return false
+	}
+	l.grown += delta
+	return true
+}
+
+// SetGrowthLimit limits Grow to add at most the given pages within each
+// window of time. Grows which would exceed it fail until the window resets.
+//
+// The window is measured with nanotime, or platform.Nanotime when nil.
+func (m *MemoryInstance) SetGrowthLimit(pages uint32, window time.Duration, nanotime func() int64) {
+	if nanotime == nil {
+		nanotime = platform.Nanotime
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.growthLimit = &growthLimit{pages: pages, window: window, nanotime: nanotime, windowStart: nanotime()}
 }
 
 // NewMemoryInstance creates a new instance based on the parameters in the SectionIDMemory.
@@ -52,6 +101,26 @@ func NewMemoryInstance(memSec *Memory) *MemoryInstance {
 	}
 }
 
+// NewMemoryInstanceWithAllocator is like NewMemoryInstance, except the buffer
+// is from the allocator, including when growing past its capacity.
+//
+// An error is returned if the allocator returns less than the minimum length.
+func NewMemoryInstanceWithAllocator(memSec *Memory, allocator MemoryAllocator) (*MemoryInstance, error) {
+	min := MemoryPagesToBytesNum(memSec.Min)
+	buf := allocator(min, MemoryPagesToBytesNum(memSec.Max))
+	if uint64(cap(buf)) < min {
+		return nil, fmt.Errorf("memory allocator returned %d bytes, less than the minimum %d", cap(buf), min)
+	}
+	buf = buf[:min]
+	return &MemoryInstance{
+		Buffer:    buf,
+		Min:       memSec.Min,
+		Cap:       memoryBytesNumToPages(uint64(cap(buf))),
+		Max:       memSec.Max,
+		allocator: allocator,
+	}, nil
+}
+
 // Definition implements the same method as documented on api.Memory.
 func (m *MemoryInstance) Definition() api.MemoryDefinition {
 	return m.definition
@@ -114,6 +183,44 @@ func (m *MemoryInstance) Read(offset, byteCount uint32) ([]byte, bool) {
 	return m.Buffer[offset : offset+byteCount : offset+byteCount], true
 }
 
+// ReadInto implements the same method as documented on api.Memory.
+func (m *MemoryInstance) ReadInto(offset uint32, dst []byte) (uint32, bool) {
+	if offset > m.size() {
+		return 0, false
+	}
+	return uint32(copy(dst, m.Buffer[offset:])), true
+}
+
+// Reader implements the same method as documented on api.Memory.
+func (m *MemoryInstance) Reader(offset, byteCount uint32) io.Reader {
+	return &memoryReader{m: m, offset: uint64(offset), end: uint64(offset) + uint64(byteCount)}
+}
+
+// memoryReader reads a region of memory, looking up the buffer on each read
+// as it is replaced when memory grows.
+type memoryReader struct {
+	m           *MemoryInstance
+	offset, end uint64
+}
+
+// Read implements io.Reader
+func (r *memoryReader) Read(p []byte) (int, error) {
+	if r.offset >= r.end {
+		return 0, io.EOF
+	}
+	size := uint64(len(r.m.Buffer))
+	if r.offset >= size {
+		return 0, io.ErrUnexpectedEOF
+	}
+	end := r.end
+	if end > size {
+		end = size
+	}
+	n := copy(p, r.m.Buffer[r.offset:end])
+	r.offset += uint64(n)
+	return n, nil
+}
+
 // WriteByte implements the same method as documented on api.Memory.
 func (m *MemoryInstance) WriteByte(offset uint32, v byte) bool {
 	if offset >= m.size() {
@@ -161,6 +268,14 @@ func (m *MemoryInstance) Write(offset uint32, val []byte) bool {
 	return true
 }
 
+// WritePartial implements the same method as documented on api.Memory.
+func (m *MemoryInstance) WritePartial(offset uint32, val []byte) uint32 {
+	if uint64(offset) >= uint64(len(m.Buffer)) {
+		return 0
+	}
+	return uint32(copy(m.Buffer[offset:], val))
+}
+
 // WriteString implements the same method as documented on api.Memory.
 func (m *MemoryInstance) WriteString(offset uint32, val string) bool {
 	if !m.hasSize(offset, uint32(len(val))) {
@@ -190,6 +305,19 @@ func (m *MemoryInstance) Grow(delta uint32) (result uint32, ok bool) {
 	newPages := currentPages + delta
 	if newPages > m.Max {
 		return 0, false
+	} else if m.growthLimit != nil && !m.growthLimit.allow(delta) {
+		return 0, false
+	} else if newPages > m.Cap && m.allocator != nil { // replace the memory.
+		newLen := MemoryPagesToBytesNum(newPages)
+		buf := m.allocator(newLen, MemoryPagesToBytesNum(m.Max))
+		if uint64(cap(buf)) < newLen {
+			return 0, false // the allocator misbehaved, so fail as if out of memory.
+		}
+		buf = buf[:newLen]
+		copy(buf, m.Buffer)
+		m.Buffer = buf
+		m.Cap = memoryBytesNumToPages(uint64(cap(buf)))
+		return currentPages, true
 	} else if newPages > m.Cap { // grow the memory.
 		m.Buffer = append(m.Buffer, make([]byte, MemoryPagesToBytesNum(delta))...)
 		m.Cap = newPages
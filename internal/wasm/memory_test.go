@@ -1,9 +1,12 @@
 package wasm
 
 import (
+	"bytes"
+	"io"
 	"math"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/internal/testing/require"
@@ -88,6 +91,92 @@ func TestMemoryInstance_Grow_Size(t *testing.T) {
 	}
 }
 
+func TestMemoryInstance_Grow_limit(t *testing.T) {
+	var now int64
+	m := &MemoryInstance{Max: 10}
+	m.SetGrowthLimit(3, time.Second, func() int64 { return now })
+
+	// Grows within the limit succeed.
+	for i := uint32(0); i < 3; i++ {
+		res, ok := m.Grow(1)
+		require.True(t, ok)
+		require.Equal(t, i, res)
+	}
+
+	// Grows beyond the limit fail until the window resets.
+	_, ok := m.Grow(1)
+	require.False(t, ok)
+	now += int64(time.Second - 1)
+	_, ok = m.Grow(1)
+	require.False(t, ok)
+
+	// Grows of zero pages aren't limited.
+	res, ok := m.Grow(0)
+	require.True(t, ok)
+	require.Equal(t, uint32(3), res)
+
+	// Once the window resets, grows succeed again, up to the limit.
+	now++
+	_, ok = m.Grow(4)
+	require.False(t, ok)
+	res, ok = m.Grow(2)
+	require.True(t, ok)
+	require.Equal(t, uint32(3), res)
+	require.Equal(t, uint32(5), m.PageSize())
+}
+
+func TestMemoryInstance_Grow_allocator(t *testing.T) {
+	var calls [][2]uint64
+	allocator := func(min, max uint64) []byte {
+		calls = append(calls, [2]uint64{min, max})
+		return make([]byte, min)
+	}
+
+	m, err := NewMemoryInstanceWithAllocator(&Memory{Min: 1, Max: 3}, allocator)
+	require.NoError(t, err)
+	require.Equal(t, [][2]uint64{{65536, 3 * 65536}}, calls)
+	require.Equal(t, uint32(1), m.PageSize())
+	require.Equal(t, uint32(1), m.Cap)
+
+	// Growing past the capacity re-allocates, retaining the contents.
+	require.True(t, m.WriteByte(1, 'a'))
+	res, ok := m.Grow(1)
+	require.True(t, ok)
+	require.Equal(t, uint32(1), res)
+	require.Equal(t, [][2]uint64{{65536, 3 * 65536}, {2 * 65536, 3 * 65536}}, calls)
+	require.Equal(t, uint32(2), m.PageSize())
+	b, ok := m.ReadByte(1)
+	require.True(t, ok)
+	require.Equal(t, byte('a'), b)
+
+	// Growing past the max doesn't allocate.
+	_, ok = m.Grow(2)
+	require.False(t, ok)
+	require.Equal(t, 2, len(calls))
+}
+
+func TestMemoryInstance_Grow_allocatorShort(t *testing.T) {
+	// allocator returns one byte less than the minimum after the first call.
+	var calls int
+	allocator := func(min, max uint64) []byte {
+		calls++
+		if calls > 1 {
+			return make([]byte, min-1)
+		}
+		return make([]byte, min)
+	}
+
+	m, err := NewMemoryInstanceWithAllocator(&Memory{Min: 1, Max: 3}, allocator)
+	require.NoError(t, err)
+
+	_, ok := m.Grow(1)
+	require.False(t, ok)
+	require.Equal(t, uint32(1), m.PageSize())
+
+	_, err = NewMemoryInstanceWithAllocator(&Memory{Min: 1, Max: 3}, allocator)
+	require.EqualError(t, err, "memory allocator returned 65535 bytes, less than the minimum 65536")
+}
+
 func TestMemoryInstance_ReadByte(t *testing.T) {
 	mem := &MemoryInstance{Buffer: []byte{0, 0, 0, 0, 0, 0, 0, 16}, Min: 1}
 	v, ok := mem.ReadByte(7)
@@ -463,6 +552,95 @@ func TestMemoryInstance_Read(t *testing.T) {
 	require.False(t, ok)
 }
 
+func TestMemoryInstance_ReadInto(t *testing.T) {
+	mem := &MemoryInstance{Buffer: []byte{0, 0, 0, 0, 16, 0, 0, 4}, Min: 1}
+
+	tests := []struct {
+		name       string
+		offset     uint32
+		dst        []byte
+		expectedN  uint32
+		expectedOk bool
+		expected   []byte
+	}{
+		{
+			name:       "full copy",
+			offset:     4,
+			dst:        make([]byte, 4),
+			expectedN:  4,
+			expectedOk: true,
+			expected:   []byte{16, 0, 0, 4},
+		},
+		{
+			name:       "short dst",
+			offset:     4,
+			dst:        make([]byte, 2),
+			expectedN:  2,
+			expectedOk: true,
+			expected:   []byte{16, 0},
+		},
+		{
+			name:       "dst past end of memory",
+			offset:     6,
+			dst:        make([]byte, 4),
+			expectedN:  2,
+			expectedOk: true,
+			expected:   []byte{0, 4, 0, 0},
+		},
+		{
+			name:     "out of range",
+			offset:   9,
+			dst:      make([]byte, 4),
+			expected: []byte{0, 0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			n, ok := mem.ReadInto(tc.offset, tc.dst)
+			require.Equal(t, tc.expectedOk, ok)
+			require.Equal(t, tc.expectedN, n)
+			require.Equal(t, tc.expected, tc.dst)
+		})
+	}
+
+	// Test no write-through
+	dst := make([]byte, 4)
+	_, ok := mem.ReadInto(4, dst)
+	require.True(t, ok)
+	dst[0] = 1
+	require.Equal(t, []byte{0, 0, 0, 0, 16, 0, 0, 4}, mem.Buffer)
+}
+
+// TestMemoryInstance_ReadInto_Grow shows the difference documented on
+// api.Memory Read: a slice from Read is no longer shared after Grow, while
+// data copied by ReadInto is unaffected.
+func TestMemoryInstance_ReadInto_Grow(t *testing.T) {
+	mem := &MemoryInstance{Min: 1, Cap: 1, Max: 2, Buffer: make([]byte, MemoryPageSize)}
+	require.True(t, mem.Write(0, []byte("wazero")))
+
+	view, ok := mem.Read(0, 6)
+	require.True(t, ok)
+	copied := make([]byte, 6)
+	n, ok := mem.ReadInto(0, copied)
+	require.True(t, ok)
+	require.Equal(t, uint32(6), n)
+
+	// Growing past the capacity re-allocates the underlying buffer.
+	_, ok = mem.Grow(1)
+	require.True(t, ok)
+	require.True(t, mem.Write(0, []byte("WAZERO")))
+
+	require.Equal(t, "wazero", string(view)) // no longer shared with memory
+	require.Equal(t, "wazero", string(copied))
+
+	current, ok := mem.Read(0, 6)
+	require.True(t, ok)
+	require.Equal(t, "WAZERO", string(current))
+}
+
 func TestMemoryInstance_WriteUint16Le(t *testing.T) {
 	memory := &MemoryInstance{Buffer: make([]byte, 100)}
 
@@ -735,6 +913,104 @@ func TestMemoryInstance_Write(t *testing.T) {
 	require.False(t, ok)
 }
 
+func TestMemoryInstance_WritePartial(t *testing.T) {
+	tests := []struct {
+		name           string
+		offset         uint32
+		buf            []byte
+		expectedN      uint32
+		expectedBuffer []byte
+	}{
+		{
+			name:           "fits exactly",
+			offset:         4,
+			buf:            []byte{1, 2, 3, 4},
+			expectedN:      4,
+			expectedBuffer: []byte{0, 0, 0, 0, 1, 2, 3, 4},
+		},
+		{
+			name:           "fits partially",
+			offset:         6,
+			buf:            []byte{1, 2, 3, 4},
+			expectedN:      2,
+			expectedBuffer: []byte{0, 0, 0, 0, 0, 0, 1, 2},
+		},
+		{
+			name:           "offset at end",
+			offset:         8,
+			buf:            []byte{1, 2, 3, 4},
+			expectedN:      0,
+			expectedBuffer: []byte{0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name:           "offset past end",
+			offset:         math.MaxUint32,
+			buf:            []byte{1, 2, 3, 4},
+			expectedN:      0,
+			expectedBuffer: []byte{0, 0, 0, 0, 0, 0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			mem := &MemoryInstance{Buffer: make([]byte, 8), Min: 1}
+
+			require.Equal(t, tc.expectedN, mem.WritePartial(tc.offset, tc.buf))
+			require.Equal(t, tc.expectedBuffer, mem.Buffer)
+		})
+	}
+}
+
+func TestMemoryInstance_Reader(t *testing.T) {
+	mem := &MemoryInstance{Buffer: []byte("?wazero?"), Min: 1}
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, mem.Reader(1, 6))
+	require.NoError(t, err)
+	require.Equal(t, int64(6), n)
+	require.Equal(t, "wazero", buf.String())
+
+	t.Run("stops at the region boundary", func(t *testing.T) {
+		r := mem.Reader(1, 2)
+		p := make([]byte, 8)
+		n, err := r.Read(p)
+		require.NoError(t, err)
+		require.Equal(t, "wa", string(p[:n]))
+
+		n, err = r.Read(p)
+		require.Equal(t, io.EOF, err)
+		require.Zero(t, n)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		b, err := io.ReadAll(mem.Reader(8, 0))
+		require.NoError(t, err)
+		require.Zero(t, len(b))
+	})
+
+	t.Run("past the end of memory", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := io.Copy(&buf, mem.Reader(6, 4))
+		require.Equal(t, io.ErrUnexpectedEOF, err)
+		require.Equal(t, "o?", buf.String())
+
+		_, err = io.Copy(&buf, mem.Reader(math.MaxUint32, 4))
+		require.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+
+	t.Run("memory grows", func(t *testing.T) {
+		mem := &MemoryInstance{Buffer: []byte("wazero"), Min: 1}
+		r := mem.Reader(0, 4)
+		mem.Buffer = append([]byte{}, "WAZERO"...) // replaced as on grow
+
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "WAZE", string(b))
+	})
+}
+
 func TestMemoryInstance_WriteString(t *testing.T) {
 	mem := &MemoryInstance{Buffer: []byte{0, 0, 0, 0, 16, 0, 0, 0}, Min: 1}
 
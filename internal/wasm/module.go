@@ -160,6 +160,11 @@ type Module struct {
 	// See https://www.w3.org/TR/2019/REC-wasm-core-1-20191205/#custom-section%E2%91%A0
 	CustomSections []*CustomSection
 
+	// TrailingData are bytes after the module in the binary, such as an
+	// appended signature. This is only set when the caller defined the
+	// module length, as the binary format doesn't.
+	TrailingData []byte
+
 	// validatedActiveElementSegments are built on Validate when
 	// SectionIDElement is non-empty and all inputs are valid.
 	//
@@ -189,10 +194,17 @@ type Module struct {
 	// MemoryDefinitionSection is a wazero-specific section built on Validate.
 	MemoryDefinitionSection []*MemoryDefinition
 
+	// TableDefinitionSection is a wazero-specific section built on Validate.
+	TableDefinitionSection []*TableDefinition
+
 	// DWARFLines is used to emit DWARF based stack trace. This is created from the multiple custom sections
 	// as described in https://yurydelendik.github.io/webassembly-dwarf/, though it is not specified in the Wasm
 	// specification: https://github.com/WebAssembly/debugging/issues/1
 	DWARFLines *wasmdebug.DWARFLines
+
+	// StackTraceEnabled is true when errors from trapped calls into this
+	// module should carry the call stack as an experimental.StackTraceError.
+	StackTraceEnabled bool
 }
 
 // ModuleID represents sha256 hash value uniquely assigned to Module.
@@ -447,8 +459,13 @@ func (m *Module) validateMemory(memory *Memory, globals []*GlobalType, _ api.Cor
 }
 
 func (m *Module) validateImports(enabledFeatures api.CoreFeatures) error {
+	typeCount := uint32(len(m.TypeSection))
 	for _, i := range m.ImportSection {
 		switch i.Type {
+		case ExternTypeFunc:
+			if i.DescFunc >= typeCount {
+				return fmt.Errorf("invalid import[%q.%q] function: type index %d out of range", i.Module, i.Name, i.DescFunc)
+			}
 		case ExternTypeGlobal:
 			if !i.DescGlobal.Mutable {
 				continue
@@ -650,10 +667,16 @@ func paramNames(localNames IndirectNameMap, funcIdx uint32, paramLen int) []stri
 	return nil
 }
 
-func (m *Module) buildMemory() (mem *MemoryInstance) {
+func (m *Module) buildMemory(allocator MemoryAllocator) (mem *MemoryInstance, err error) {
 	memSec := m.MemorySection
 	if memSec != nil {
-		mem = NewMemoryInstance(memSec)
+		if allocator != nil {
+			if mem, err = NewMemoryInstanceWithAllocator(memSec, allocator); err != nil {
+				return
+			}
+		} else {
+			mem = NewMemoryInstance(memSec)
+		}
 		mem.definition = m.MemoryDefinitionSection[0]
 	}
 	return
@@ -577,6 +577,12 @@ func TestModule_validateImports(t *testing.T) {
 			enabledFeatures: api.CoreFeaturesV1,
 			i:               &Import{Module: "m", Name: "n", Type: ExternTypeFunc, DescFunc: 0},
 		},
+		{
+			name:            "func type index out of range",
+			enabledFeatures: api.CoreFeaturesV1,
+			i:               &Import{Module: "m", Name: "n", Type: ExternTypeFunc, DescFunc: 1},
+			expectedErr:     `invalid import["m"."n"] function: type index 1 out of range`,
+		},
 		{
 			name:            "global var disabled",
 			enabledFeatures: api.CoreFeaturesV1.SetEnabled(api.CoreFeatureMutableGlobal, false),
@@ -613,7 +619,7 @@ func TestModule_validateImports(t *testing.T) {
 	for _, tt := range tests {
 		tc := tt
 		t.Run(tc.name, func(t *testing.T) {
-			m := Module{}
+			m := Module{TypeSection: []*FunctionType{v_v}}
 			if tc.i != nil {
 				m.ImportSection = []*Import{tc.i}
 			}
@@ -836,7 +842,8 @@ func TestModule_buildFunctions(t *testing.T) {
 func TestModule_buildMemoryInstance(t *testing.T) {
 	t.Run("nil", func(t *testing.T) {
 		m := Module{}
-		mem := m.buildMemory()
+		mem, err := m.buildMemory(nil)
+		require.NoError(t, err)
 		require.Nil(t, mem)
 	})
 	t.Run("non-nil", func(t *testing.T) {
@@ -847,7 +854,8 @@ func TestModule_buildMemoryInstance(t *testing.T) {
 			MemorySection:           &Memory{Min: min, Cap: min, Max: max},
 			MemoryDefinitionSection: []*MemoryDefinition{mDef},
 		}
-		mem := m.buildMemory()
+		mem, err := m.buildMemory(nil)
+		require.NoError(t, err)
 		require.Equal(t, min, mem.Min)
 		require.Equal(t, max, mem.Max)
 		require.Equal(t, mDef, mem.definition)
@@ -39,6 +39,22 @@ type (
 		// Engine is a global context for a Store which is in responsible for compilation and execution of Wasm modules.
 		Engine Engine
 
+		// ZeroOnClose is true when memory and globals defined by a module are
+		// zeroed when it is closed.
+		ZeroOnClose bool
+
+		// MaxInstances when positive limits the count of modules
+		// instantiated at the same time, including host modules.
+		MaxInstances int
+
+		// MemoryAllocator when non-nil allocates the memory defined by
+		// modules, including when it grows.
+		MemoryAllocator MemoryAllocator
+
+		// InterruptOnContextDone is true when calls are interrupted when
+		// their context is done. See wasmruntime.InterruptKey
+		InterruptOnContextDone bool
+
 		// typeIDs maps each FunctionType.String() to a unique FunctionTypeID. This is used at runtime to
 		// do type-checks on indirect function calls.
 		typeIDs map[string]FunctionTypeID
@@ -84,6 +100,12 @@ type (
 		// ElementInstances holds the element instance, and each holds the references to either functions
 		// or external objects (unimplemented).
 		ElementInstances []ElementInstance
+
+		// definedGlobals and definedMemory are the globals and memory defined
+		// by this module, as opposed to imported. These are only set when
+		// Store.ZeroOnClose is true.
+		definedGlobals []*GlobalInstance
+		definedMemory  *MemoryInstance
 	}
 
 	// DataInstance holds bytes corresponding to the data segment in a module.
@@ -156,6 +178,47 @@ func (m *ModuleInstance) addSections(module *Module, importedGlobals, globals []
 	m.BuildExports(module.ExportSection)
 }
 
+// zeroDefined zeroes the memory and globals defined by this module, so that
+// values such as secrets do not outlive it.
+//
+// Memory and globals imported by any module in the list besides this one are
+// skipped, as zeroing them would corrupt a module that is still running.
+func (m *ModuleInstance) zeroDefined(modules *moduleListNode) {
+	if m.definedGlobals == nil && m.definedMemory == nil {
+		return // not defined or Store.ZeroOnClose is false.
+	}
+
+	var importedGlobals map[*GlobalInstance]struct{}
+	memoryImported := false
+	for node := modules; node != nil; node = node.next {
+		other := node.module
+		if other == nil || other == m {
+			continue
+		}
+		if other.Memory != nil && other.Memory == m.definedMemory {
+			memoryImported = true
+		}
+		for _, g := range other.Globals {
+			if importedGlobals == nil {
+				importedGlobals = map[*GlobalInstance]struct{}{}
+			}
+			importedGlobals[g] = struct{}{}
+		}
+	}
+
+	for _, g := range m.definedGlobals {
+		if _, ok := importedGlobals[g]; !ok {
+			g.Val, g.ValHi = 0, 0
+		}
+	}
+	if mem := m.definedMemory; mem != nil && !memoryImported {
+		buf := mem.Buffer[:cap(mem.Buffer)]
+		for i := range buf {
+			buf[i] = 0
+		}
+	}
+}
+
 func (m *ModuleInstance) buildElementInstances(elements []*ElementSegment) {
 	m.ElementInstances = make([]ElementInstance, len(elements))
 	for i, elm := range elements {
@@ -278,14 +341,8 @@ func (s *Store) Instantiate(
 	name string,
 	sys *internalsys.Context,
 ) (*CallContext, error) {
-	// Collect any imported modules to avoid locking the store too long.
-	importedModuleNames := map[string]struct{}{}
-	for _, i := range module.ImportSection {
-		importedModuleNames[i.Module] = struct{}{}
-	}
-
 	// Read-Lock the store and ensure imports needed are present.
-	importedModules, err := s.requireModules(importedModuleNames)
+	importedModules, err := s.requireModules(module.ImportSection)
 	if err != nil {
 		return nil, err
 	}
@@ -343,10 +400,17 @@ func (s *Store) instantiate(
 		return nil, err
 	}
 
-	globals, memory := module.buildGlobals(importedGlobals, m.Engine.FunctionInstanceReference), module.buildMemory()
+	globals := module.buildGlobals(importedGlobals, m.Engine.FunctionInstanceReference)
+	memory, err := module.buildMemory(s.MemoryAllocator)
+	if err != nil {
+		return nil, err
+	}
 
 	// Now we have all instances from imports and local ones, so ready to create a new ModuleInstance.
 	m.addSections(module, importedGlobals, globals, tables, importedMemory, memory)
+	if s.ZeroOnClose {
+		m.definedGlobals, m.definedMemory = globals, memory
+	}
 
 	// As of reference types proposal, data segment validation must happen after instantiation,
 	// and the side effect must persist even if there's out of bounds error after instantiation.
@@ -403,13 +467,14 @@ func resolveImports(module *Module, modules map[string]*ModuleInstance) (
 	for idx, i := range module.ImportSection {
 		m, ok := modules[i.Module]
 		if !ok {
-			err = fmt.Errorf("module[%s] not instantiated", i.Module)
+			err = errorInvalidImport(i, idx, fmt.Errorf("module[%s] not instantiated", i.Module))
 			return
 		}
 
 		var imported ExportInstance
 		imported, err = m.getExport(i.Name, i.Type)
 		if err != nil {
+			err = errorInvalidImport(i, idx, err)
 			return
 		}
 
@@ -459,6 +524,11 @@ func resolveImports(module *Module, modules map[string]*ModuleInstance) (
 		case ExternTypeMemory:
 			expected := i.DescMem
 			importedMemory = m.Memory
+			if importedMemory == nil {
+				// Guard against a memory export without a memory, as the guest would otherwise crash on first access.
+				err = errorInvalidImport(i, idx, fmt.Errorf("memory not defined in module %q", m.Name))
+				return
+			}
 
 			if expected.Min > memoryBytesNumToPages(uint64(len(importedMemory.Buffer))) {
 				err = errorMinSizeMismatch(i, idx, expected.Min, importedMemory.Min)
@@ -638,6 +708,7 @@ func (s *Store) CloseWithExitCode(ctx context.Context, exitCode uint32) (err err
 			if _, e := m.CallCtx.close(ctx, exitCode); e != nil && err == nil {
 				err = e // first error
 			}
+			m.zeroDefined(nil) // all modules are closing
 		}
 	}
 	s.moduleList = nil
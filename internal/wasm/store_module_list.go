@@ -48,6 +48,14 @@ func (s *Store) deleteModule(moduleName string) error {
 	return nil
 }
 
+// zeroDefined zeroes the memory and globals defined by the module, except
+// those another module in this store still imports.
+func (s *Store) zeroDefined(m *ModuleInstance) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	m.zeroDefined(s.moduleList)
+}
+
 // module returns the module of the given name or error if not in this store
 func (s *Store) module(moduleName string) (*ModuleInstance, error) {
 	s.mux.RLock()
@@ -64,19 +72,23 @@ func (s *Store) module(moduleName string) (*ModuleInstance, error) {
 	return node.module, nil
 }
 
-// requireModules returns all instantiated modules whose names equal the keys in the input, or errs if any are missing.
-func (s *Store) requireModules(moduleNames map[string]struct{}) (map[string]*ModuleInstance, error) {
-	ret := make(map[string]*ModuleInstance, len(moduleNames))
+// requireModules returns all instantiated modules imported, keyed by name, or
+// errs naming the first import whose module is missing.
+func (s *Store) requireModules(imports []*Import) (map[string]*ModuleInstance, error) {
+	ret := map[string]*ModuleInstance{}
 
 	s.mux.RLock()
 	defer s.mux.RUnlock()
 
-	for n := range moduleNames {
-		node, ok := s.nameToNode[n]
+	for idx, i := range imports {
+		if _, ok := ret[i.Module]; ok {
+			continue
+		}
+		node, ok := s.nameToNode[i.Module]
 		if !ok {
-			return nil, fmt.Errorf("module[%s] not instantiated", n)
+			return nil, errorInvalidImport(i, idx, fmt.Errorf("module[%s] not instantiated", i.Module))
 		}
-		ret[n] = node.module
+		ret[i.Module] = node.module
 	}
 	return ret, nil
 }
@@ -89,6 +101,9 @@ func (s *Store) requireModuleName(moduleName string) error {
 	if _, ok := s.nameToNode[moduleName]; ok {
 		return fmt.Errorf("module[%s] has already been instantiated", moduleName)
 	}
+	if s.MaxInstances > 0 && len(s.nameToNode) >= s.MaxInstances {
+		return fmt.Errorf("module[%s] exceeds the limit of %d instances", moduleName, s.MaxInstances)
+	}
 
 	// add the newest node to the moduleNamesList as the head.
 	node := &moduleListNode{
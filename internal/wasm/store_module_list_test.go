@@ -106,21 +106,24 @@ func TestStore_requireModules(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		s, m1, _ := newTestStore()
 
-		modules, err := s.requireModules(map[string]struct{}{m1.Name: {}})
+		modules, err := s.requireModules([]*Import{
+			{Type: ExternTypeFunc, Module: m1.Name, Name: "a"},
+			{Type: ExternTypeFunc, Module: m1.Name, Name: "b"},
+		})
 		require.NoError(t, err)
 		require.Equal(t, map[string]*ModuleInstance{m1.Name: m1}, modules)
 	})
 	t.Run("module not instantiated", func(t *testing.T) {
 		s, _, _ := newTestStore()
 
-		_, err := s.requireModules(map[string]struct{}{"unknown": {}})
-		require.EqualError(t, err, "module[unknown] not instantiated")
+		_, err := s.requireModules([]*Import{{Type: ExternTypeFunc, Module: "unknown", Name: "fn"}})
+		require.EqualError(t, err, "import[0] func[unknown.fn]: module[unknown] not instantiated")
 	})
 	t.Run("store closed", func(t *testing.T) {
 		s, _, _ := newTestStore()
 		require.NoError(t, s.CloseWithExitCode(context.Background(), 0))
 
-		_, err := s.requireModules(map[string]struct{}{"unknown": {}})
+		_, err := s.requireModules([]*Import{{Type: ExternTypeFunc, Module: "unknown", Name: "fn"}})
 		require.Error(t, err)
 	})
 }
@@ -151,6 +154,17 @@ func TestStore_requireModuleName(t *testing.T) {
 		err := s.requireModuleName("m2")
 		require.EqualError(t, err, "module[m2] has already been instantiated")
 	})
+	t.Run("max instances", func(t *testing.T) {
+		s.MaxInstances = 2
+		defer func() { s.MaxInstances = 0 }()
+
+		err := s.requireModuleName("m3")
+		require.EqualError(t, err, "module[m3] exceeds the limit of 2 instances")
+
+		// Deleting a module makes room for another.
+		require.NoError(t, s.deleteModule("m2"))
+		require.NoError(t, s.requireModuleName("m3"))
+	})
 }
 
 func TestStore_AliasModule(t *testing.T) {
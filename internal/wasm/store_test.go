@@ -268,7 +268,7 @@ func TestStore_Instantiate_Errors(t *testing.T) {
 				{Type: ExternTypeFunc, Module: "non-exist", Name: "fn", DescFunc: 0},
 			},
 		}, importingModuleName, nil)
-		require.EqualError(t, err, "module[non-exist] not instantiated")
+		require.EqualError(t, err, "import[1] func[non-exist.fn]: module[non-exist] not instantiated")
 	})
 
 	t.Run("creating engine failed", func(t *testing.T) {
@@ -616,14 +616,14 @@ func Test_resolveImports(t *testing.T) {
 	t.Run("module not instantiated", func(t *testing.T) {
 		modules := map[string]*ModuleInstance{}
 		_, _, _, _, err := resolveImports(&Module{ImportSection: []*Import{{Module: "unknown", Name: "unknown"}}}, modules)
-		require.EqualError(t, err, "module[unknown] not instantiated")
+		require.EqualError(t, err, "import[0] func[unknown.unknown]: module[unknown] not instantiated")
 	})
 	t.Run("export instance not found", func(t *testing.T) {
 		modules := map[string]*ModuleInstance{
 			moduleName: {Exports: map[string]ExportInstance{}, Name: moduleName},
 		}
 		_, _, _, _, err := resolveImports(&Module{ImportSection: []*Import{{Module: moduleName, Name: "unknown"}}}, modules)
-		require.EqualError(t, err, "\"unknown\" is not exported in module \"test\"")
+		require.EqualError(t, err, "import[0] func[test.unknown]: \"unknown\" is not exported in module \"test\"")
 	})
 	t.Run("func", func(t *testing.T) {
 		t.Run("ok", func(t *testing.T) {
@@ -765,6 +765,18 @@ func Test_resolveImports(t *testing.T) {
 			_, _, _, _, err := resolveImports(&Module{ImportSection: []*Import{{Module: moduleName, Name: name, Type: ExternTypeMemory, DescMem: importMemoryType}}}, modules)
 			require.EqualError(t, err, "import[0] memory[test.target]: maximum size mismatch: 10 < 65536")
 		})
+		t.Run("memory not defined", func(t *testing.T) {
+			modules := map[string]*ModuleInstance{
+				moduleName: {
+					Exports: map[string]ExportInstance{name: {
+						Type: ExternTypeMemory,
+					}},
+					Name: moduleName,
+				},
+			}
+			_, _, _, _, err := resolveImports(&Module{ImportSection: []*Import{{Module: moduleName, Name: name, Type: ExternTypeMemory, DescMem: &Memory{}}}}, modules)
+			require.EqualError(t, err, "import[0] memory[test.target]: memory not defined in module \"test\"")
+		})
 	})
 }
 
@@ -101,6 +101,9 @@ type TableInstance struct {
 	// Type is either RefTypeFuncref or RefTypeExternRef.
 	Type RefType
 
+	// definition is known at compile time.
+	definition api.TableDefinition
+
 	// mux is used to prevent overlapping calls to Grow.
 	mux sync.RWMutex
 }
@@ -247,12 +250,16 @@ func (m *Module) validateTable(enabledFeatures api.CoreFeatures, tables []*Table
 func (m *Module) buildTables(importedTables []*TableInstance, importedGlobals []*GlobalInstance, skipBoundCheck bool) (tables []*TableInstance, inits []tableInitEntry, err error) {
 	tables = importedTables
 
-	for _, tsec := range m.TableSection {
+	for i, tsec := range m.TableSection {
 		// The module defining the table is the one that sets its Min/Max etc.
-		tables = append(tables, &TableInstance{
+		table := &TableInstance{
 			References: make([]Reference, tsec.Min), Min: tsec.Min, Max: tsec.Max,
 			Type: tsec.Type,
-		})
+		}
+		if m.TableDefinitionSection != nil {
+			table.definition = m.TableDefinitionSection[len(importedTables)+i]
+		}
+		tables = append(tables, table)
 	}
 
 	elementSegments := m.validatedActiveElementSegments
@@ -0,0 +1,93 @@
+package wasm
+
+// BuildTableDefinitions generates table metadata that can be parsed from
+// the module. This must be called after all validation.
+//
+// Note: This is exported for wazero.Runtime `CompileModule`.
+func (m *Module) BuildTableDefinitions() {
+	var moduleName string
+	if m.NameSection != nil {
+		moduleName = m.NameSection.ModuleName
+	}
+
+	tableCount := m.ImportTableCount() + uint32(len(m.TableSection))
+	if tableCount == 0 {
+		return
+	}
+
+	m.TableDefinitionSection = make([]*TableDefinition, 0, tableCount)
+	importTableIdx := Index(0)
+	for _, i := range m.ImportSection {
+		if i.Type != ExternTypeTable {
+			continue
+		}
+
+		m.TableDefinitionSection = append(m.TableDefinitionSection, &TableDefinition{
+			importDesc: &[2]string{i.Module, i.Name},
+			index:      importTableIdx,
+			table:      i.DescTable,
+		})
+		importTableIdx++
+	}
+
+	for i, t := range m.TableSection {
+		m.TableDefinitionSection = append(m.TableDefinitionSection, &TableDefinition{
+			index: importTableIdx + Index(i),
+			table: t,
+		})
+	}
+
+	for _, d := range m.TableDefinitionSection {
+		d.moduleName = moduleName
+		for _, e := range m.ExportSection {
+			if e.Type == ExternTypeTable && e.Index == d.index {
+				d.exportNames = append(d.exportNames, e.Name)
+			}
+		}
+	}
+}
+
+// TableDefinition implements api.TableDefinition
+type TableDefinition struct {
+	moduleName  string
+	index       Index
+	importDesc  *[2]string
+	exportNames []string
+	table       *Table
+}
+
+// ModuleName implements the same method as documented on api.TableDefinition.
+func (f *TableDefinition) ModuleName() string {
+	return f.moduleName
+}
+
+// Index implements the same method as documented on api.TableDefinition.
+func (f *TableDefinition) Index() uint32 {
+	return f.index
+}
+
+// Import implements the same method as documented on api.TableDefinition.
+func (f *TableDefinition) Import() (moduleName, name string, isImport bool) {
+	if importDesc := f.importDesc; importDesc != nil {
+		moduleName, name, isImport = importDesc[0], importDesc[1], true
+	}
+	return
+}
+
+// ExportNames implements the same method as documented on api.TableDefinition.
+func (f *TableDefinition) ExportNames() []string {
+	return f.exportNames
+}
+
+// Min implements the same method as documented on api.TableDefinition.
+func (f *TableDefinition) Min() uint32 {
+	return f.table.Min
+}
+
+// Max implements the same method as documented on api.TableDefinition.
+func (f *TableDefinition) Max() (max uint32, encoded bool) {
+	if f.table.Max != nil {
+		max, encoded = *f.table.Max, true
+	}
+	return
+}
@@ -0,0 +1,64 @@
+package wasm
+
+import (
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestModule_BuildTableDefinitions(t *testing.T) {
+	max := uint32(3)
+
+	tests := []struct {
+		name     string
+		m        *Module
+		expected []*TableDefinition
+	}{
+		{
+			name: "no tables",
+			m:    &Module{},
+		},
+		{
+			name: "defines table{0,}",
+			m:    &Module{TableSection: []*Table{{Min: 0}}},
+			expected: []*TableDefinition{
+				{index: 0, table: &Table{Min: 0}},
+			},
+		},
+		{
+			name: "exports imported table{0,} and defined table{2,3}",
+			m: &Module{
+				ImportSection: []*Import{{
+					Type:      ExternTypeTable,
+					DescTable: &Table{Min: 0},
+				}},
+				ExportSection: []*Export{
+					{Name: "imported_table", Type: ExternTypeTable, Index: 0},
+					{Name: "table_index=1", Type: ExternTypeTable, Index: 1},
+				},
+				TableSection: []*Table{{Min: 2, Max: &max}},
+			},
+			expected: []*TableDefinition{
+				{
+					index:       0,
+					importDesc:  &[2]string{"", ""},
+					exportNames: []string{"imported_table"},
+					table:       &Table{Min: 0},
+				},
+				{
+					index:       1,
+					exportNames: []string{"table_index=1"},
+					table:       &Table{Min: 2, Max: &max},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.m.BuildTableDefinitions()
+			require.Equal(t, tc.expected, tc.m.TableDefinitionSection)
+		})
+	}
+}
@@ -4,6 +4,7 @@
 package wasmdebug
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"runtime/debug"
@@ -128,6 +129,11 @@ func (s *stackTrace) FromRecovered(recovered interface{}) error {
 		return fmt.Errorf("wasm error: %w\nwasm stack trace:\n\t%s", wasmErr, stack)
 	}
 
+	// If the context of the call was done, the engine interrupted it.
+	if ctxErr, ok := recovered.(error); ok && (ctxErr == context.Canceled || ctxErr == context.DeadlineExceeded) {
+		return fmt.Errorf("wasm error: %w\nwasm stack trace:\n\t%s", ctxErr, stack)
+	}
+
 	// If we have a runtime.Error, something severe happened which should include the stack trace. This could be
 	// a nil pointer from wazero or a user-defined function from HostModuleBuilder.
 	if runtimeErr, ok := recovered.(runtime.Error); ok {
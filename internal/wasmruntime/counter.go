@@ -0,0 +1,6 @@
+package wasmruntime
+
+// InstructionCounterKey is a context.Context Value key. Its associated value
+// is a *uint64, which engines that support counting increment once per
+// instruction executed.
+type InstructionCounterKey struct{}
@@ -27,6 +27,9 @@ var (
 	ErrRuntimeInvalidTableAccess = New("invalid table access")
 	// ErrRuntimeIndirectCallTypeMismatch indicates that the type check failed during call_indirect.
 	ErrRuntimeIndirectCallTypeMismatch = New("indirect call type mismatch")
+	// ErrRuntimeExecutionBudgetExhausted indicates that calls into a module
+	// exceeded the cumulative wall-clock time it was configured with.
+	ErrRuntimeExecutionBudgetExhausted = New("execution budget exhausted")
 )
 
 // Error is returned by a wasm.Engine during the execution of Wasm functions, and they indicate that the Wasm runtime
@@ -0,0 +1,5 @@
+package wasmruntime
+
+// InterruptKey is a context.Context Value key. When present, engines that
+// support it interrupt a call when the context is done.
+type InterruptKey struct{}
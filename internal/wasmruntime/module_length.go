@@ -0,0 +1,6 @@
+package wasmruntime
+
+// ModuleLengthKey is a context.Context Value key. Its associated value is an
+// int, which is the length of the module at the start of a binary. Any bytes
+// after it are trailing data, not sections.
+type ModuleLengthKey struct{}
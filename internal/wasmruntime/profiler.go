@@ -0,0 +1,29 @@
+package wasmruntime
+
+import "github.com/tetratelabs/wazero/api"
+
+// ProfilerKey is a context.Context Value key. Its associated value is a
+// *Profiler, which engines that support sampling tick once per instruction
+// executed.
+type ProfilerKey struct{}
+
+// Profiler samples the function executing every Interval instructions.
+type Profiler struct {
+	// Interval is the count of instructions between samples.
+	Interval uint64
+
+	// Samples is the count of samples per function.
+	Samples map[api.FunctionDefinition]uint64
+
+	// count is the count of instructions since the last sample.
+	count uint64
+}
+
+// Tick is called once per instruction executed by the function fn.
+func (p *Profiler) Tick(fn api.FunctionDefinition) {
+	if p.count++; p.count < p.Interval {
+		return
+	}
+	p.count = 0
+	p.Samples[fn]++
+}
@@ -0,0 +1,18 @@
+package wasmruntime
+
+import "github.com/tetratelabs/wazero/api"
+
+// StackKey is a context.Context Value key. Its associated value is a *Stack,
+// which engines that support it fill in for the duration of each host
+// function call.
+type StackKey struct{}
+
+// Stack is the state of the Wasm caller of a host function.
+type Stack struct {
+	// Caller is the definition of the calling function, or nil when the host
+	// function was called directly by api.Function Call.
+	Caller api.FunctionDefinition
+
+	// Values are the operand stack values, bottom first.
+	Values []uint64
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/tetratelabs/wazero/api"
 	experimentalapi "github.com/tetratelabs/wazero/experimental"
@@ -12,6 +13,7 @@ import (
 	"github.com/tetratelabs/wazero/internal/version"
 	"github.com/tetratelabs/wazero/internal/wasm"
 	binaryformat "github.com/tetratelabs/wazero/internal/wasm/binary"
+	"github.com/tetratelabs/wazero/internal/wasmruntime"
 	"github.com/tetratelabs/wazero/sys"
 )
 
@@ -69,6 +71,23 @@ type Runtime interface {
 	//   - To avoid using configuration defaults, use InstantiateModule instead.
 	InstantiateModuleFromBinary(ctx context.Context, source []byte) (api.Module, error)
 
+	// InstantiateWithConfig compiles the WebAssembly binary (%.wasm) and
+	// instantiates it with the configuration, or errs if either step failed.
+	//
+	// Here's an example:
+	//	module, compiled, _ := r.InstantiateWithConfig(ctx, wasm, wazero.NewModuleConfig().WithName("prod"))
+	//	// Instantiate the same source again, without compiling it again.
+	//	module2, _ := r.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName("test"))
+	//
+	// # Notes
+	//
+	//   - This is a convenience utility that chains CompileModule with
+	//     InstantiateModule. Unlike InstantiateModuleFromBinary, it accepts a
+	//     configuration and returns the CompiledModule for reuse.
+	//   - The CompiledModule is not closed with the module, so close it when
+	//     no longer needed. On error, it is closed and nil is returned.
+	InstantiateWithConfig(ctx context.Context, source []byte, config ModuleConfig) (api.Module, CompiledModule, error)
+
 	// CloseWithExitCode closes all the modules that have been initialized in this Runtime with the provided exit code.
 	// An error is returned if any module returns an error when closed.
 	//
@@ -111,6 +130,11 @@ func NewRuntimeWithConfig(ctx context.Context, rConfig RuntimeConfig) Runtime {
 		ctx = context.WithValue(ctx, version.WazeroVersionKey{}, wazeroVersion)
 	}
 	config := rConfig.(*runtimeConfig)
+	// Compiled code doesn't check the context, so it can't be interrupted.
+	// This panics instead of returning an error as it is a programming error.
+	if config.interruptOnDone && config.engineKind != engineKindInterpreter {
+		panic(errors.New("WithInterruptOnContextDone requires NewRuntimeConfigInterpreter"))
+	}
 	var engine wasm.Engine
 	var cacheImpl *cache
 	if c := config.cache; c != nil {
@@ -122,6 +146,10 @@ func NewRuntimeWithConfig(ctx context.Context, rConfig RuntimeConfig) Runtime {
 		engine = config.newEngine(ctx, config.enabledFeatures, nil)
 	}
 	store := wasm.NewStore(config.enabledFeatures, engine)
+	store.ZeroOnClose = config.zeroOnClose
+	store.MaxInstances = config.maxInstances
+	store.MemoryAllocator = config.memoryAllocator
+	store.InterruptOnContextDone = config.interruptOnDone
 	return &runtime{
 		cache:                 cacheImpl,
 		store:                 store,
@@ -129,6 +157,8 @@ func NewRuntimeWithConfig(ctx context.Context, rConfig RuntimeConfig) Runtime {
 		memoryLimitPages:      config.memoryLimitPages,
 		memoryCapacityFromMax: config.memoryCapacityFromMax,
 		dwarfDisabled:         config.dwarfDisabled,
+		stackTrace:            config.stackTrace,
+		strictClocks:          config.strictClocks,
 	}
 }
 
@@ -140,6 +170,8 @@ type runtime struct {
 	memoryLimitPages      uint32
 	memoryCapacityFromMax bool
 	dwarfDisabled         bool
+	stackTrace            bool
+	strictClocks          bool
 }
 
 // Module implements Runtime.Module.
@@ -153,12 +185,23 @@ func (r *runtime) CompileModule(ctx context.Context, binary []byte) (CompiledMod
 		return nil, errors.New("binary == nil")
 	}
 
+	var trailingData []byte
+	if moduleLen, ok := ctx.Value(wasmruntime.ModuleLengthKey{}).(int); ok {
+		if moduleLen < 0 || moduleLen > len(binary) {
+			return nil, fmt.Errorf("module length %d out of range [0, %d]", moduleLen, len(binary))
+		}
+		if moduleLen < len(binary) {
+			trailingData = binary[moduleLen:]
+		}
+		binary = binary[:moduleLen]
+	}
+
 	if len(binary) < 4 || !bytes.Equal(binary[0:4], binaryformat.Magic) {
 		return nil, errors.New("invalid binary")
 	}
 
 	internal, err := binaryformat.DecodeModule(binary, r.enabledFeatures,
-		r.memoryLimitPages, r.memoryCapacityFromMax, !r.dwarfDisabled, false)
+		r.memoryLimitPages, r.memoryCapacityFromMax, !r.dwarfDisabled, true)
 	if err != nil {
 		return nil, err
 	} else if err = internal.Validate(r.enabledFeatures); err != nil {
@@ -168,10 +211,14 @@ func (r *runtime) CompileModule(ctx context.Context, binary []byte) (CompiledMod
 	}
 
 	internal.AssignModuleID(binary)
+	internal.TrailingData = trailingData
+	internal.StackTraceEnabled = r.stackTrace
 
-	// Now that the module is validated, cache the function and memory definitions.
+	// Now that the module is validated, cache the function, memory and table
+	// definitions.
 	internal.BuildFunctionDefinitions()
 	internal.BuildMemoryDefinitions()
+	internal.BuildTableDefinitions()
 
 	c := &compiledModule{module: internal, compiledEngine: r.store.Engine}
 
@@ -211,6 +258,20 @@ func (r *runtime) InstantiateModuleFromBinary(ctx context.Context, binary []byte
 	}
 }
 
+// InstantiateWithConfig implements Runtime.InstantiateWithConfig
+func (r *runtime) InstantiateWithConfig(ctx context.Context, source []byte, config ModuleConfig) (api.Module, CompiledModule, error) {
+	compiled, err := r.CompileModule(ctx, source)
+	if err != nil {
+		return nil, nil, err
+	}
+	mod, err := r.InstantiateModule(ctx, compiled, config)
+	if err != nil {
+		_ = compiled.Close(ctx)
+		return nil, nil, err
+	}
+	return mod, compiled, nil
+}
+
 // InstantiateModule implements Runtime.InstantiateModule.
 func (r *runtime) InstantiateModule(
 	ctx context.Context,
@@ -220,11 +281,23 @@ func (r *runtime) InstantiateModule(
 	code := compiled.(*compiledModule)
 	config := mConfig.(*moduleConfig)
 
+	if config.memoryGrowthLimitPages > 0 && code.module.ImportMemoryCount() > 0 {
+		err = errors.New("memory growth limit can't apply to an imported memory")
+		return
+	}
+
 	var sysCtx *internalsys.Context
 	if sysCtx, err = config.toSysContext(); err != nil {
 		return
 	}
 
+	sysCtx.StrictClocks = r.strictClocks
+
+	// Test to see if the caller is observing files using an experimental feature.
+	if fl, ok := ctx.Value(experimentalapi.FileListenerKey{}).(experimentalapi.FileListener); ok {
+		sysCtx.FS().FileListener = fl
+	}
+
 	name := config.name
 	if name == "" && code.module.NameSection != nil && code.module.NameSection.ModuleName != "" {
 		name = code.module.NameSection.ModuleName
@@ -245,6 +318,14 @@ func (r *runtime) InstantiateModule(
 		mod.(*wasm.CallContext).CodeCloser = code
 	}
 
+	// Attach the exit code policy, execution budget and memory growth limit
+	// before calling any start functions.
+	mod.(*wasm.CallContext).ExitCodePolicy = config.exitCodePolicy
+	mod.(*wasm.CallContext).ExecutionBudget = config.executionBudget
+	if mem, ok := mod.Memory().(*wasm.MemoryInstance); ok && config.memoryGrowthLimitPages > 0 {
+		mem.SetGrowthLimit(config.memoryGrowthLimitPages, config.memoryGrowthLimitWindow, config.memoryGrowthNanotime)
+	}
+
 	// Now, invoke any start functions, failing at first error.
 	for _, fn := range config.startFunctions {
 		start := mod.ExportedFunction(fn)
@@ -279,3 +360,24 @@ func (r *runtime) CloseWithExitCode(ctx context.Context, exitCode uint32) error
 	}
 	return err
 }
+
+// CallWithTimeout calls the function with a context derived from ctx, whose
+// deadline is the timeout from now.
+//
+// When the deadline interrupts the call, the error returned satisfies
+// errors.Is(err, context.DeadlineExceeded), which distinguishes it from other
+// traps.
+//
+// This returns an error without calling the function unless the runtime was
+// configured with RuntimeConfig.WithInterruptOnContextDone, which requires
+// NewRuntimeConfigInterpreter. Otherwise, the deadline couldn't interrupt
+// guest code. Notably, this is the case for NewRuntime on platforms that
+// support the compiler.
+func CallWithTimeout(ctx context.Context, fn api.Function, timeout time.Duration, params ...uint64) ([]uint64, error) {
+	if !wasm.InterruptsOnContextDone(fn) {
+		return nil, errors.New("CallWithTimeout requires RuntimeConfig.WithInterruptOnContextDone")
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn.Call(ctx, params...)
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	_ "embed"
 	"errors"
+	"fmt"
+	"math"
+	"os"
 	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/tetratelabs/wazero/api"
@@ -156,6 +160,368 @@ func TestRuntime_CompileModule(t *testing.T) {
 	}
 }
 
+// TestRuntime_CompileModule_auditImports shows how an embedder can deny-list
+// modules which import specific functions, prior to instantiation.
+func TestRuntime_CompileModule_auditImports(t *testing.T) {
+	imports := func(compiled CompiledModule, moduleName, name string) bool {
+		for _, f := range compiled.ImportedFunctions() {
+			if m, n, _ := f.Import(); m == moduleName && n == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	tests := []struct {
+		name     string
+		imported string
+		expected bool
+	}{
+		{name: "imports path_unlink_file", imported: "path_unlink_file", expected: true},
+		{name: "doesn't import path_unlink_file", imported: "fd_write", expected: false},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRuntime(testCtx)
+			defer r.Close(testCtx)
+
+			compiled, err := r.CompileModule(testCtx, binaryformat.EncodeModule(&wasm.Module{
+				TypeSection: []*wasm.FunctionType{{}},
+				ImportSection: []*wasm.Import{{
+					Module: "wasi_snapshot_preview1", Name: tc.imported,
+					Type: wasm.ExternTypeFunc, DescFunc: 0,
+				}},
+			}))
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expected, imports(compiled, "wasi_snapshot_preview1", "path_unlink_file"))
+		})
+	}
+}
+
+func TestRuntime_CompileModule_customSections(t *testing.T) {
+	// The encoder doesn't support custom sections, so append one manually.
+	producers := []byte{1, 12, 'p', 'r', 'o', 'c', 'e', 's', 's', 'e', 'd', '-', 'b', 'y', 0}
+	name := "producers"
+	customSection := append([]byte{wasm.SectionIDCustom, byte(1 + len(name) + len(producers)), byte(len(name))}, name...)
+	customSection = append(customSection, producers...)
+
+	bin := append(binaryformat.EncodeModule(&wasm.Module{}), customSection...)
+
+	// Custom sections are retained even when debug info is disabled.
+	r := NewRuntimeWithConfig(testCtx, NewRuntimeConfig().WithDebugInfoEnabled(false))
+	defer r.Close(testCtx)
+
+	compiled, err := r.CompileModule(testCtx, bin)
+	require.NoError(t, err)
+
+	require.Equal(t, []CustomSection{{Name: name, Data: producers}}, compiled.CustomSections())
+
+	t.Run("none", func(t *testing.T) {
+		compiled, err := r.CompileModule(testCtx, binaryNamedZero)
+		require.NoError(t, err)
+		require.Nil(t, compiled.CustomSections())
+	})
+}
+
+func TestRuntime_CompileModule_trailingData(t *testing.T) {
+	module := binaryNamedZero
+
+	tests := []struct {
+		name         string
+		trailingData []byte
+		// decodesAsSection is true when the trailing data is also a valid
+		// section, so can't be rejected without the module length.
+		decodesAsSection bool
+	}{
+		{
+			name:         "signature",
+			trailingData: []byte("wazero-signature"),
+		},
+		{
+			name:             "starts with custom section ID",
+			trailingData:     []byte{0x00, 0x0e, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14},
+			decodesAsSection: true,
+		},
+		{
+			name: "starts with element section ID",
+			trailingData: []byte{
+				0x09, 0x08, 0x8d, 0xf7, 0x82, 0xce, 0x4f, 0x21,
+				0x9b, 0x6a, 0x13, 0xe7, 0x55, 0xc0, 0x3d, 0x92,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		require.Equal(t, 16, len(tc.trailingData))
+		bin := append(append([]byte{}, module...), tc.trailingData...)
+
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRuntime(testCtx)
+			defer r.Close(testCtx)
+
+			t.Run("module length", func(t *testing.T) {
+				ctx := experimental.WithModuleLength(testCtx, len(module))
+				compiled, err := r.CompileModule(ctx, bin)
+				require.NoError(t, err)
+				require.Equal(t, tc.trailingData, compiled.TrailingData())
+				require.Equal(t, "0", compiled.Name())
+			})
+
+			t.Run("default", func(t *testing.T) {
+				compiled, err := r.CompileModule(testCtx, bin)
+				if tc.decodesAsSection {
+					require.NoError(t, err)
+					require.Nil(t, compiled.TrailingData())
+				} else {
+					require.Error(t, err)
+				}
+			})
+		})
+	}
+
+	t.Run("no trailing data", func(t *testing.T) {
+		r := NewRuntime(testCtx)
+		defer r.Close(testCtx)
+
+		ctx := experimental.WithModuleLength(testCtx, len(module))
+		compiled, err := r.CompileModule(ctx, module)
+		require.NoError(t, err)
+		require.Nil(t, compiled.TrailingData())
+	})
+
+	t.Run("module length out of range", func(t *testing.T) {
+		r := NewRuntime(testCtx)
+		defer r.Close(testCtx)
+
+		ctx := experimental.WithModuleLength(testCtx, len(module)+1)
+		_, err := r.CompileModule(ctx, module)
+		require.EqualError(t, err, fmt.Sprintf("module length %d out of range [0, %d]", len(module)+1, len(module)))
+	})
+}
+
+// TestRuntime_CompileModule_bulkMemory ensures modules using bulk memory
+// instructions are rejected unless the feature is enabled.
+func TestRuntime_CompileModule_bulkMemory(t *testing.T) {
+	// fill(offset, value, len) calls memory.fill
+	bin := binaryformat.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{{
+			Params: []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32, wasm.ValueTypeI32},
+		}},
+		FunctionSection: []wasm.Index{0},
+		MemorySection:   &wasm.Memory{Min: 1},
+		CodeSection: []*wasm.Code{{Body: []byte{
+			wasm.OpcodeLocalGet, 0,
+			wasm.OpcodeLocalGet, 1,
+			wasm.OpcodeLocalGet, 2,
+			wasm.OpcodeMiscPrefix, wasm.OpcodeMiscMemoryFill, 0,
+			wasm.OpcodeEnd,
+		}}},
+		ExportSection: []*wasm.Export{
+			{Name: "fill", Type: wasm.ExternTypeFunc, Index: 0},
+			{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+		},
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		features := api.CoreFeaturesV2.SetEnabled(api.CoreFeatureBulkMemoryOperations, false)
+		r := NewRuntimeWithConfig(testCtx, NewRuntimeConfig().WithCoreFeatures(features))
+		defer r.Close(testCtx)
+
+		_, err := r.CompileModule(testCtx, bin)
+		require.EqualError(t, err, `invalid function[0] export["fill"]: memory.fill invalid as feature "bulk-memory-operations" is disabled`)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		r := NewRuntimeWithConfig(testCtx, NewRuntimeConfig().WithCoreFeatures(api.CoreFeaturesV2))
+		defer r.Close(testCtx)
+
+		mod, err := r.InstantiateModuleFromBinary(testCtx, bin)
+		require.NoError(t, err)
+
+		_, err = mod.ExportedFunction("fill").Call(testCtx, 8, 'a', 4)
+		require.NoError(t, err)
+
+		filled, ok := mod.Memory().Read(7, 6)
+		require.True(t, ok)
+		require.Equal(t, []byte{0, 'a', 'a', 'a', 'a', 0}, filled)
+	})
+}
+
+// TestRuntime_CompileModule_nonTrappingFloatToInt ensures modules using
+// saturating conversions or sign extension are rejected with a descriptive
+// error unless the corresponding feature is enabled.
+func TestRuntime_CompileModule_nonTrappingFloatToInt(t *testing.T) {
+	// truncSat(f32) calls i32.trunc_sat_f32_s
+	truncSat := binaryformat.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{{
+			Params:  []wasm.ValueType{wasm.ValueTypeF32},
+			Results: []wasm.ValueType{wasm.ValueTypeI32},
+		}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection: []*wasm.Code{{Body: []byte{
+			wasm.OpcodeLocalGet, 0,
+			wasm.OpcodeMiscPrefix, wasm.OpcodeMiscI32TruncSatF32S,
+			wasm.OpcodeEnd,
+		}}},
+		ExportSection: []*wasm.Export{{Name: "truncSat", Type: wasm.ExternTypeFunc, Index: 0}},
+	})
+
+	// extend8(i32) calls i32.extend8_s
+	extend8 := binaryformat.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{{
+			Params:  []wasm.ValueType{wasm.ValueTypeI32},
+			Results: []wasm.ValueType{wasm.ValueTypeI32},
+		}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection: []*wasm.Code{{Body: []byte{
+			wasm.OpcodeLocalGet, 0,
+			wasm.OpcodeI32Extend8S,
+			wasm.OpcodeEnd,
+		}}},
+		ExportSection: []*wasm.Export{{Name: "extend8", Type: wasm.ExternTypeFunc, Index: 0}},
+	})
+
+	tests := []struct {
+		name        string
+		bin         []byte
+		feature     api.CoreFeatures
+		expectedErr string
+	}{
+		{
+			name:        "i32.trunc_sat_f32_s",
+			bin:         truncSat,
+			feature:     api.CoreFeatureNonTrappingFloatToIntConversion,
+			expectedErr: `invalid function[0] export["truncSat"]: i32.trunc_sat_f32_s invalid as feature "nontrapping-float-to-int-conversion" is disabled`,
+		},
+		{
+			name:        "i32.extend8_s",
+			bin:         extend8,
+			feature:     api.CoreFeatureSignExtensionOps,
+			expectedErr: `invalid function[0] export["extend8"]: i32.extend8_s invalid as feature "sign-extension-ops" is disabled`,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name+" disabled", func(t *testing.T) {
+			features := api.CoreFeaturesV2.SetEnabled(tc.feature, false)
+			r := NewRuntimeWithConfig(testCtx, NewRuntimeConfig().WithCoreFeatures(features))
+			defer r.Close(testCtx)
+
+			_, err := r.CompileModule(testCtx, tc.bin)
+			require.EqualError(t, err, tc.expectedErr)
+		})
+	}
+
+	t.Run("i32.trunc_sat_f32_s enabled", func(t *testing.T) {
+		r := NewRuntimeWithConfig(testCtx, NewRuntimeConfig().WithCoreFeatures(api.CoreFeaturesV2))
+		defer r.Close(testCtx)
+
+		mod, err := r.InstantiateModuleFromBinary(testCtx, truncSat)
+		require.NoError(t, err)
+		fn := mod.ExportedFunction("truncSat")
+
+		for _, c := range []struct {
+			name     string
+			in       float32
+			expected int32
+		}{
+			{name: "NaN", in: float32(math.NaN()), expected: 0},
+			{name: "+Inf", in: float32(math.Inf(1)), expected: math.MaxInt32},
+			{name: "-Inf", in: float32(math.Inf(-1)), expected: math.MinInt32},
+			{name: "in range", in: -1.5, expected: -1},
+		} {
+			results, err := fn.Call(testCtx, api.EncodeF32(c.in))
+			require.NoError(t, err, c.name)
+			require.Equal(t, c.expected, int32(results[0]), c.name)
+		}
+	})
+}
+
+func TestRuntime_InstantiateModule_memoryDefinition(t *testing.T) {
+	tests := []struct {
+		name           string
+		memory         *wasm.Memory
+		expectedMin    uint32
+		expectedMax    uint32
+		expectedHasMax bool
+	}{
+		{
+			name:           "(memory 1 3)",
+			memory:         &wasm.Memory{Min: 1, Max: 3, IsMaxEncoded: true},
+			expectedMin:    1,
+			expectedMax:    3,
+			expectedHasMax: true,
+		},
+		{
+			name:        "(memory 1)",
+			memory:      &wasm.Memory{Min: 1},
+			expectedMin: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRuntime(testCtx)
+			defer r.Close(testCtx)
+
+			mod, err := r.InstantiateModuleFromBinary(testCtx, binaryformat.EncodeModule(&wasm.Module{
+				MemorySection: tc.memory,
+			}))
+			require.NoError(t, err)
+
+			def := mod.Memory().Definition()
+			require.Equal(t, tc.expectedMin, def.Min())
+			max, hasMax := def.Max()
+			require.Equal(t, tc.expectedHasMax, hasMax)
+			if hasMax {
+				require.Equal(t, tc.expectedMax, max)
+			}
+		})
+	}
+}
+
+func TestRuntime_InstantiateModule_exportedDefinitions(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	tableMax := uint32(4)
+	mod, err := r.InstantiateModuleFromBinary(testCtx, binaryformat.EncodeModule(&wasm.Module{
+		MemorySection: &wasm.Memory{Min: 1, Max: 3, IsMaxEncoded: true},
+		TableSection:  []*wasm.Table{{Min: 2, Max: &tableMax, Type: wasm.RefTypeFuncref}},
+		ExportSection: []*wasm.Export{
+			{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0},
+			{Name: "__indirect_function_table", Type: wasm.ExternTypeTable, Index: 0},
+		},
+	}))
+	require.NoError(t, err)
+
+	memories := mod.ExportedMemoryDefinitions()
+	require.Equal(t, 1, len(memories))
+	mem := memories["memory"]
+	require.Equal(t, []string{"memory"}, mem.ExportNames())
+	require.Equal(t, uint32(1), mem.Min())
+	max, hasMax := mem.Max()
+	require.True(t, hasMax)
+	require.Equal(t, uint32(3), max)
+
+	tables := mod.ExportedTableDefinitions()
+	require.Equal(t, 1, len(tables))
+	table := tables["__indirect_function_table"]
+	require.Equal(t, []string{"__indirect_function_table"}, table.ExportNames())
+	require.Equal(t, uint32(0), table.Index())
+	require.Equal(t, uint32(2), table.Min())
+	max, hasMax = table.Max()
+	require.True(t, hasMax)
+	require.Equal(t, tableMax, max)
+}
+
 func TestRuntime_CompileModule_Errors(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -330,6 +696,56 @@ func TestModule_Global(t *testing.T) {
 	}
 }
 
+func TestRuntime_InstantiateWithConfig(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	binary := binaryformat.EncodeModule(&wasm.Module{
+		TypeSection:     []*wasm.FunctionType{{Results: []wasm.ValueType{wasm.ValueTypeI32}}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection:     []*wasm.Code{{Body: []byte{wasm.OpcodeI32Const, 42, wasm.OpcodeEnd}}},
+		ExportSection:   []*wasm.Export{{Name: "answer", Type: wasm.ExternTypeFunc, Index: 0}},
+	})
+
+	mod, compiled, err := r.InstantiateWithConfig(testCtx, binary, NewModuleConfig().WithName("one"))
+	require.NoError(t, err)
+	require.Equal(t, "one", mod.Name())
+
+	// The result is the same as compiling and instantiating separately.
+	twoStepCompiled, err := r.CompileModule(testCtx, binary)
+	require.NoError(t, err)
+	twoStep, err := r.InstantiateModule(testCtx, twoStepCompiled, NewModuleConfig().WithName("two-step"))
+	require.NoError(t, err)
+	require.Equal(t, twoStepCompiled.ExportedFunctions(), compiled.ExportedFunctions())
+
+	expected, err := twoStep.ExportedFunction("answer").Call(testCtx)
+	require.NoError(t, err)
+	actual, err := mod.ExportedFunction("answer").Call(testCtx)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	// The compiled module can be reused, even after the first module closes.
+	require.NoError(t, mod.Close(testCtx))
+	mod2, err := r.InstantiateModule(testCtx, compiled, NewModuleConfig().WithName("two"))
+	require.NoError(t, err)
+	actual, err = mod2.ExportedFunction("answer").Call(testCtx)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	t.Run("compile error", func(t *testing.T) {
+		_, compiled, err := r.InstantiateWithConfig(testCtx, []byte("invalid"), NewModuleConfig())
+		require.Error(t, err)
+		require.Nil(t, compiled)
+	})
+
+	t.Run("instantiate error", func(t *testing.T) {
+		// The name "two" is already in use.
+		_, compiled, err := r.InstantiateWithConfig(testCtx, binary, NewModuleConfig().WithName("two"))
+		require.EqualError(t, err, "module[two] has already been instantiated")
+		require.Nil(t, compiled)
+	})
+}
+
 func TestRuntime_InstantiateModule_UsesContext(t *testing.T) {
 	r := NewRuntime(testCtx)
 	defer r.Close(testCtx)
@@ -496,6 +912,321 @@ func TestRuntime_InstantiateModule_ExitError(t *testing.T) {
 	require.Equal(t, err, sys.NewExitError("call-exit", 2))
 }
 
+func TestRuntime_InstantiateModule_MemoryGrowthLimit(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	// Define a function which grows memory by its parameter, returning the
+	// previous page count or -1 on failure.
+	compiled, err := r.CompileModule(testCtx, binaryformat.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{{
+			Params:  []wasm.ValueType{wasm.ValueTypeI32},
+			Results: []wasm.ValueType{wasm.ValueTypeI32},
+		}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection: []*wasm.Code{{Body: []byte{
+			wasm.OpcodeLocalGet, 0,
+			wasm.OpcodeMemoryGrow, 0,
+			wasm.OpcodeEnd,
+		}}},
+		MemorySection: &wasm.Memory{Min: 1, Max: 100, IsMaxEncoded: true},
+		ExportSection: []*wasm.Export{{Name: "grow", Type: wasm.ExternTypeFunc, Index: 0}},
+	}))
+	require.NoError(t, err)
+
+	var now int64
+	config := NewModuleConfig().WithMemoryGrowthLimit(2, time.Second).(*moduleConfig)
+	config.memoryGrowthNanotime = func() int64 { return now }
+	mod, err := r.InstantiateModule(testCtx, compiled, config)
+	require.NoError(t, err)
+	grow := mod.ExportedFunction("grow")
+
+	// Grows within the limit succeed.
+	for _, expected := range []uint64{1, 2} {
+		res, err := grow.Call(testCtx, 1)
+		require.NoError(t, err)
+		require.Equal(t, expected, res[0])
+	}
+
+	// Grows beyond the limit fail, until the window resets.
+	res, err := grow.Call(testCtx, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(math.MaxUint32), res[0])
+
+	now += int64(time.Second)
+	res, err = grow.Call(testCtx, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), res[0])
+}
+
+func TestRuntime_InstantiateModule_MemoryGrowthLimit_imported(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	compiled, err := r.CompileModule(testCtx, binaryformat.EncodeModule(&wasm.Module{
+		ImportSection: []*wasm.Import{{
+			Module: "env", Name: "memory", Type: wasm.ExternTypeMemory,
+			DescMem: &wasm.Memory{Min: 1, Max: 100, IsMaxEncoded: true},
+		}},
+	}))
+	require.NoError(t, err)
+
+	// The limit isn't allowed to change the memory of the exporting module.
+	_, err = r.InstantiateModule(testCtx, compiled, NewModuleConfig().WithMemoryGrowthLimit(2, time.Second))
+	require.EqualError(t, err, "memory growth limit can't apply to an imported memory")
+}
+
+func TestRuntime_InstantiateModule_ExecutionBudget(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	// Each call sleeps longer than the budget, so the first call crosses it.
+	var sleeps int
+	sleep := func() {
+		sleeps++
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	_, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(sleep).Export("sleep").
+		Instantiate(testCtx)
+	require.NoError(t, err)
+
+	code, err := r.CompileModule(testCtx, binaryformat.EncodeModule(&wasm.Module{
+		TypeSection:     []*wasm.FunctionType{{}},
+		ImportSection:   []*wasm.Import{{Module: "env", Name: "sleep", Type: wasm.ExternTypeFunc, DescFunc: 0}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{wasm.OpcodeCall, 0, wasm.OpcodeEnd}}, // Call the imported env.sleep.
+		},
+		ExportSection: []*wasm.Export{{Type: wasm.ExternTypeFunc, Index: 1, Name: "work"}},
+	}))
+	require.NoError(t, err)
+
+	mod, err := r.InstantiateModule(testCtx, code, NewModuleConfig().WithExecutionBudget(time.Millisecond))
+	require.NoError(t, err)
+	work := mod.ExportedFunction("work")
+
+	_, err = work.Call(testCtx)
+	require.EqualError(t, err, "execution budget exhausted")
+	require.Equal(t, 1, sleeps) // the call that crossed the budget ran.
+
+	// Later calls trap without running.
+	_, err = work.Call(testCtx)
+	require.EqualError(t, err, "execution budget exhausted")
+	require.Equal(t, 1, sleeps)
+}
+
+// TestRuntime_InstantiateModule_ExecutionBudget_interrupt ensures the call
+// which crosses the budget is stopped when the engine interrupts calls.
+func TestRuntime_InstantiateModule_ExecutionBudget_interrupt(t *testing.T) {
+	r := NewRuntimeWithConfig(testCtx, NewRuntimeConfigInterpreter().WithInterruptOnContextDone(true))
+	defer r.Close(testCtx)
+
+	code, err := r.CompileModule(testCtx, binaryformat.EncodeModule(&wasm.Module{
+		TypeSection:     []*wasm.FunctionType{{}},
+		FunctionSection: []wasm.Index{0},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{wasm.OpcodeLoop, 0x40, wasm.OpcodeBr, 0, wasm.OpcodeEnd, wasm.OpcodeEnd}},
+		},
+		ExportSection: []*wasm.Export{{Type: wasm.ExternTypeFunc, Index: 0, Name: "infinite_loop"}},
+	}))
+	require.NoError(t, err)
+
+	mod, err := r.InstantiateModule(testCtx, code, NewModuleConfig().WithExecutionBudget(10*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = mod.ExportedFunction("infinite_loop").Call(testCtx)
+	require.EqualError(t, err, "execution budget exhausted")
+}
+
+func TestCallWithTimeout(t *testing.T) {
+	r := NewRuntimeWithConfig(testCtx, NewRuntimeConfigInterpreter().WithInterruptOnContextDone(true))
+	defer r.Close(testCtx)
+
+	bin := binaryformat.EncodeModule(&wasm.Module{
+		TypeSection:     []*wasm.FunctionType{{}},
+		FunctionSection: []wasm.Index{0, 0},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{wasm.OpcodeLoop, 0x40, wasm.OpcodeBr, 0, wasm.OpcodeEnd, wasm.OpcodeEnd}},
+			{Body: []byte{wasm.OpcodeUnreachable, wasm.OpcodeEnd}},
+		},
+		ExportSection: []*wasm.Export{
+			{Type: wasm.ExternTypeFunc, Index: 0, Name: "infinite_loop"},
+			{Type: wasm.ExternTypeFunc, Index: 1, Name: "unreachable"},
+		},
+	})
+	mod, err := r.InstantiateModuleFromBinary(testCtx, bin)
+	require.NoError(t, err)
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := CallWithTimeout(testCtx, mod.ExportedFunction("infinite_loop"), 50*time.Millisecond)
+		require.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	})
+
+	t.Run("other trap", func(t *testing.T) {
+		_, err := CallWithTimeout(testCtx, mod.ExportedFunction("unreachable"), 50*time.Millisecond)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("not interruptible", func(t *testing.T) {
+		r := NewRuntimeWithConfig(testCtx, NewRuntimeConfigInterpreter())
+		defer r.Close(testCtx)
+
+		mod, err := r.InstantiateModuleFromBinary(testCtx, bin)
+		require.NoError(t, err)
+
+		_, err = CallWithTimeout(testCtx, mod.ExportedFunction("infinite_loop"), 50*time.Millisecond)
+		require.EqualError(t, err, "CallWithTimeout requires RuntimeConfig.WithInterruptOnContextDone")
+	})
+}
+
+func TestNewRuntimeWithConfig_InterruptOnContextDone_compiler(t *testing.T) {
+	if !platform.CompilerSupported() {
+		t.Skip()
+	}
+
+	err := require.CapturePanic(func() {
+		NewRuntimeWithConfig(testCtx, NewRuntimeConfigCompiler().WithInterruptOnContextDone(true))
+	})
+	require.EqualError(t, err, "WithInterruptOnContextDone requires NewRuntimeConfigInterpreter")
+}
+
+func TestRuntime_InstantiateModule_ExitCodePolicy(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	// exit is like "proc_exit" in "wasi_snapshot_preview1".
+	exit := func(ctx context.Context, m api.Module, exitCode uint32) {
+		require.NoError(t, m.CloseWithExitCode(ctx, exitCode))
+		panic(sys.NewExitError(m.Name(), exitCode))
+	}
+
+	_, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(exit).Export("exit").
+		Instantiate(testCtx)
+	require.NoError(t, err)
+
+	binary := binaryformat.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{
+			{Params: []wasm.ValueType{wasm.ValueTypeI32}},
+			{Params: []wasm.ValueType{wasm.ValueTypeI32}, Results: []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI64}},
+		},
+		ImportSection:   []*wasm.Import{{Module: "env", Name: "exit", Type: wasm.ExternTypeFunc, DescFunc: 0}},
+		FunctionSection: []wasm.Index{1},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{
+				wasm.OpcodeLocalGet, 0, wasm.OpcodeCall, 0, // Call the imported env.exit.
+				wasm.OpcodeI32Const, 1, wasm.OpcodeI64Const, 2, // unreachable results
+				wasm.OpcodeEnd,
+			}},
+		},
+		ExportSection: []*wasm.Export{{Type: wasm.ExternTypeFunc, Index: 1, Name: "exit"}},
+	})
+
+	code, err := r.CompileModule(testCtx, binary)
+	require.NoError(t, err)
+
+	errExit := errors.New("exit")
+	config := NewModuleConfig().WithExitCodePolicy(func(exitCode uint32) error {
+		if exitCode == 0 {
+			return nil
+		}
+		return fmt.Errorf("%w: %d", errExit, exitCode)
+	})
+
+	tests := []struct {
+		name        string
+		exitCode    uint32
+		expectedErr string
+	}{
+		{name: "exit code 0", exitCode: 0},
+		{name: "exit code 2", exitCode: 2, expectedErr: "exit: 2"},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(tc.name, func(t *testing.T) {
+			mod, err := r.InstantiateModule(testCtx, code, config.WithName(tc.name))
+			require.NoError(t, err)
+
+			results, err := mod.ExportedFunction("exit").Call(testCtx, uint64(tc.exitCode))
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				require.Equal(t, []uint64{0, 0}, results)
+			} else {
+				require.Nil(t, results)
+				require.ErrorIs(t, err, errExit)
+				require.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+// TestRuntime_InstantiateModule_importedMemory ensures a guest can import its
+// memory, so that the host and guest share the same api.Memory.
+func TestRuntime_InstantiateModule_importedMemory(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	// Host modules can't define memory, so the host provides it with a module
+	// that only exports a memory: (module (memory (export "memory") 1))
+	env, err := r.InstantiateModuleFromBinary(testCtx, binaryformat.EncodeModule(&wasm.Module{
+		NameSection:   &wasm.NameSection{ModuleName: "env"},
+		MemorySection: &wasm.Memory{Min: 1},
+		ExportSection: []*wasm.Export{{Name: "memory", Type: wasm.ExternTypeMemory, Index: 0}},
+	}))
+	require.NoError(t, err)
+
+	// The guest imports the memory and exports functions to load and store.
+	guest, err := r.InstantiateModuleFromBinary(testCtx, binaryformat.EncodeModule(&wasm.Module{
+		TypeSection: []*wasm.FunctionType{
+			{Params: []wasm.ValueType{wasm.ValueTypeI32}, Results: []wasm.ValueType{wasm.ValueTypeI32}},
+			{Params: []wasm.ValueType{wasm.ValueTypeI32, wasm.ValueTypeI32}},
+		},
+		ImportSection: []*wasm.Import{{
+			Module: "env", Name: "memory",
+			Type: wasm.ExternTypeMemory, DescMem: &wasm.Memory{Min: 1},
+		}},
+		FunctionSection: []wasm.Index{0, 1},
+		CodeSection: []*wasm.Code{
+			{Body: []byte{ // load(offset) returns the i32 at offset
+				wasm.OpcodeLocalGet, 0,
+				wasm.OpcodeI32Load, 0x2, 0x0,
+				wasm.OpcodeEnd,
+			}},
+			{Body: []byte{ // store(offset, v) stores the i32 v at offset
+				wasm.OpcodeLocalGet, 0,
+				wasm.OpcodeLocalGet, 1,
+				wasm.OpcodeI32Store, 0x2, 0x0,
+				wasm.OpcodeEnd,
+			}},
+		},
+		ExportSection: []*wasm.Export{
+			{Name: "load", Type: wasm.ExternTypeFunc, Index: 0},
+			{Name: "store", Type: wasm.ExternTypeFunc, Index: 1},
+		},
+	}))
+	require.NoError(t, err)
+
+	mem := env.ExportedMemory("memory")
+	require.Equal(t, mem, guest.Memory())
+
+	// The guest sees writes by the host.
+	require.True(t, mem.WriteUint32Le(8, 42))
+	results, err := guest.ExportedFunction("load").Call(testCtx, 8)
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), results[0])
+
+	// The host sees writes by the guest.
+	_, err = guest.ExportedFunction("store").Call(testCtx, 16, 7)
+	require.NoError(t, err)
+	v, ok := mem.ReadUint32Le(16)
+	require.True(t, ok)
+	require.Equal(t, uint32(7), v)
+}
+
 func TestRuntime_CloseWithExitCode(t *testing.T) {
 	bin := binaryformat.EncodeModule(&wasm.Module{
 		TypeSection:     []*wasm.FunctionType{{}},
@@ -633,6 +1364,308 @@ func TestHostFunctionWithCustomContext(t *testing.T) {
 	require.True(t, calledCall)
 }
 
+func TestRuntime_InstantiateModule_MultipleHostModules(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	_, err := r.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(func() uint32 { return 1 }).Export("one").
+		Instantiate(testCtx)
+	require.NoError(t, err)
+
+	_, err = r.NewHostModuleBuilder("custom").
+		NewFunctionBuilder().WithFunc(func() uint32 { return 2 }).Export("two").
+		Instantiate(testCtx)
+	require.NoError(t, err)
+
+	// guest imports "env"."one" and "custom"."two", re-exporting their sum.
+	guest := func(importModules ...string) []byte {
+		return binaryformat.EncodeModule(&wasm.Module{
+			TypeSection: []*wasm.FunctionType{{Results: []api.ValueType{api.ValueTypeI32}}},
+			ImportSection: []*wasm.Import{
+				{Module: importModules[0], Name: "one", Type: wasm.ExternTypeFunc, DescFunc: 0},
+				{Module: importModules[1], Name: "two", Type: wasm.ExternTypeFunc, DescFunc: 0},
+			},
+			FunctionSection: []wasm.Index{0},
+			CodeSection: []*wasm.Code{
+				{Body: []byte{wasm.OpcodeCall, 0, wasm.OpcodeCall, 1, wasm.OpcodeI32Add, wasm.OpcodeEnd}},
+			},
+			ExportSection: []*wasm.Export{{Type: wasm.ExternTypeFunc, Name: "sum", Index: 2}},
+		})
+	}
+
+	t.Run("both resolve", func(t *testing.T) {
+		mod, err := r.InstantiateModuleFromBinary(testCtx, guest("env", "custom"))
+		require.NoError(t, err)
+		defer mod.Close(testCtx)
+
+		results, err := mod.ExportedFunction("sum").Call(testCtx)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{3}, results)
+	})
+
+	t.Run("missing namespace", func(t *testing.T) {
+		_, err := r.InstantiateModuleFromBinary(testCtx, guest("env", "missing"))
+		require.EqualError(t, err, "import[1] func[missing.two]: module[missing] not instantiated")
+	})
+}
+
+func TestRuntime_InstantiateModule_missingMemory(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	// guest imports "env"."memory", but nothing provides it.
+	guest := binaryformat.EncodeModule(&wasm.Module{
+		ImportSection: []*wasm.Import{
+			{Module: "env", Name: "memory", Type: wasm.ExternTypeMemory, DescMem: &wasm.Memory{Min: 1, Max: 1}},
+		},
+	})
+
+	t.Run("module not instantiated", func(t *testing.T) {
+		_, err := r.InstantiateModuleFromBinary(testCtx, guest)
+		require.EqualError(t, err, "import[0] memory[env.memory]: module[env] not instantiated")
+	})
+
+	t.Run("memory not exported", func(t *testing.T) {
+		env, err := r.NewHostModuleBuilder("env").
+			NewFunctionBuilder().WithFunc(func() uint32 { return 1 }).Export("one").
+			Instantiate(testCtx)
+		require.NoError(t, err)
+		defer env.Close(testCtx)
+
+		_, err = r.InstantiateModuleFromBinary(testCtx, guest)
+		require.EqualError(t, err, `import[0] memory[env.memory]: "memory" is not exported in module "env"`)
+	})
+}
+
+func TestRuntime_InstantiateModule_MemoryAllocator(t *testing.T) {
+	var buffers [][]byte
+	allocator := func(min, max uint64) []byte {
+		buf := make([]byte, min)
+		buffers = append(buffers, buf)
+		return buf
+	}
+
+	r := NewRuntimeWithConfig(testCtx, NewRuntimeConfig().WithMemoryAllocator(allocator))
+	defer r.Close(testCtx)
+
+	// grow adds a page, then stores 42 at the start of it.
+	bin := binaryformat.EncodeModule(&wasm.Module{
+		TypeSection:     []*wasm.FunctionType{{}},
+		FunctionSection: []wasm.Index{0},
+		MemorySection:   &wasm.Memory{Min: 1, Max: 2, IsMaxEncoded: true},
+		CodeSection: []*wasm.Code{{Body: []byte{
+			wasm.OpcodeI32Const, 1,
+			wasm.OpcodeMemoryGrow, 0,
+			wasm.OpcodeDrop,
+			wasm.OpcodeI32Const, 0x80, 0x80, 0x04, // 65536
+			wasm.OpcodeI32Const, 42,
+			wasm.OpcodeI32Store, 0x2, 0x0, // alignment=2 (natural alignment) staticOffset=0
+			wasm.OpcodeEnd,
+		}}},
+		ExportSection: []*wasm.Export{{Name: "grow", Type: wasm.ExternTypeFunc, Index: 0}},
+	})
+
+	mod, err := r.InstantiateModuleFromBinary(testCtx, bin)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(buffers))
+
+	_, err = mod.ExportedFunction("grow").Call(testCtx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(buffers))
+
+	// The guest wrote to the buffer from the allocator.
+	buf := buffers[1]
+	require.Equal(t, 2*65536, len(buf))
+	require.Equal(t, []byte{42, 0, 0, 0}, buf[65536:65540])
+	v, ok := mod.Memory().ReadUint32Le(65536)
+	require.True(t, ok)
+	require.Equal(t, uint32(42), v)
+}
+
+// TestRuntime_InstantiateModule_MemoryAllocator_short ensures an allocator
+// returning less than the minimum length fails instead of panicking.
+func TestRuntime_InstantiateModule_MemoryAllocator_short(t *testing.T) {
+	bin := binaryformat.EncodeModule(&wasm.Module{
+		TypeSection:     []*wasm.FunctionType{{Results: []wasm.ValueType{wasm.ValueTypeI32}}},
+		FunctionSection: []wasm.Index{0},
+		MemorySection:   &wasm.Memory{Min: 1, Max: 2, IsMaxEncoded: true},
+		CodeSection: []*wasm.Code{{Body: []byte{
+			wasm.OpcodeI32Const, 1,
+			wasm.OpcodeMemoryGrow, 0,
+			wasm.OpcodeEnd,
+		}}},
+		ExportSection: []*wasm.Export{{Name: "grow", Type: wasm.ExternTypeFunc, Index: 0}},
+	})
+
+	t.Run("instantiate", func(t *testing.T) {
+		r := NewRuntimeWithConfig(testCtx, NewRuntimeConfig().WithMemoryAllocator(func(min, max uint64) []byte {
+			return make([]byte, min-1)
+		}))
+		defer r.Close(testCtx)
+
+		_, err := r.InstantiateModuleFromBinary(testCtx, bin)
+		require.EqualError(t, err, "memory allocator returned 65535 bytes, less than the minimum 65536")
+	})
+
+	t.Run("grow", func(t *testing.T) {
+		var calls int
+		r := NewRuntimeWithConfig(testCtx, NewRuntimeConfig().WithMemoryAllocator(func(min, max uint64) []byte {
+			if calls++; calls > 1 {
+				return make([]byte, min-1)
+			}
+			return make([]byte, min)
+		}))
+		defer r.Close(testCtx)
+
+		mod, err := r.InstantiateModuleFromBinary(testCtx, bin)
+		require.NoError(t, err)
+
+		results, err := mod.ExportedFunction("grow").Call(testCtx)
+		require.NoError(t, err)
+		require.Equal(t, int32(-1), int32(results[0]))
+		require.Equal(t, uint32(65536), mod.Memory().Size())
+	})
+}
+
+func TestRuntime_InstantiateModule_FileListener(t *testing.T) {
+	r := NewRuntime(testCtx)
+	defer r.Close(testCtx)
+
+	var events []string
+	ctx := context.WithValue(testCtx, experimental.FileListenerKey{}, experimental.FileListener(
+		func(fd uint32, path string, opened bool) {
+			event := "close"
+			if opened {
+				event = "open"
+			}
+			events = append(events, fmt.Sprintf("%s(%d,%s)", event, fd, path))
+		}))
+
+	testFS := fstest.MapFS{"a.txt": &fstest.MapFile{}, "b.txt": &fstest.MapFile{}}
+	compiled, err := r.CompileModule(testCtx, binaryNamedZero)
+	require.NoError(t, err)
+
+	mod, err := r.InstantiateModule(ctx, compiled, NewModuleConfig().WithFS(testFS))
+	require.NoError(t, err)
+	defer mod.Close(testCtx)
+
+	fsc := mod.(*wasm.CallContext).Sys.FS()
+	fdA, err := fsc.OpenFile("a.txt", os.O_RDONLY, 0)
+	require.NoError(t, err)
+	fdB, err := fsc.OpenFile("b.txt", os.O_RDONLY, 0)
+	require.NoError(t, err)
+	require.NoError(t, fsc.CloseFile(fdA))
+	require.NoError(t, fsc.CloseFile(fdB))
+
+	require.Equal(t, []string{"open(4,a.txt)", "open(5,b.txt)", "close(4,a.txt)", "close(5,b.txt)"}, events)
+}
+
+func TestRuntime_InstantiateModule_MaxInstances(t *testing.T) {
+	r := NewRuntimeWithConfig(testCtx, NewRuntimeConfig().WithMaxInstances(2))
+	defer r.Close(testCtx)
+
+	compiled, err := r.CompileModule(testCtx, binaryformat.EncodeModule(&wasm.Module{}))
+	require.NoError(t, err)
+
+	var mods []api.Module
+	for _, name := range []string{"m1", "m2"} {
+		mod, err := r.InstantiateModule(testCtx, compiled, NewModuleConfig().WithName(name))
+		require.NoError(t, err)
+		mods = append(mods, mod)
+	}
+
+	_, err = r.InstantiateModule(testCtx, compiled, NewModuleConfig().WithName("m3"))
+	require.EqualError(t, err, "module[m3] exceeds the limit of 2 instances")
+
+	// Closing a module makes room for another.
+	require.NoError(t, mods[0].Close(testCtx))
+	_, err = r.InstantiateModule(testCtx, compiled, NewModuleConfig().WithName("m3"))
+	require.NoError(t, err)
+}
+
+func TestRuntime_InstantiateModule_ZeroOnClose(t *testing.T) {
+	bin := binaryformat.EncodeModule(&wasm.Module{
+		MemorySection: &wasm.Memory{Min: 1},
+		GlobalSection: []*wasm.Global{{
+			Type: &wasm.GlobalType{ValType: wasm.ValueTypeI64, Mutable: true},
+			Init: &wasm.ConstantExpression{Opcode: wasm.OpcodeI64Const, Data: leb128.EncodeInt64(42)},
+		}},
+		ExportSection: []*wasm.Export{
+			{Type: wasm.ExternTypeMemory, Name: "memory", Index: 0},
+			{Type: wasm.ExternTypeGlobal, Name: "global", Index: 0},
+		},
+	})
+	sentinel := []byte("secret")
+
+	for _, tc := range []struct {
+		name        string
+		zeroOnClose bool
+	}{
+		{name: "default"},
+		{name: "WithZeroOnClose", zeroOnClose: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// Capture the buffer backing the memory, as a pooling allocator
+			// would, to ensure it doesn't hold the secret after close.
+			var buf []byte
+			config := NewRuntimeConfig().WithZeroOnClose(tc.zeroOnClose).
+				WithMemoryAllocator(func(min, max uint64) []byte {
+					buf = make([]byte, min)
+					return buf
+				})
+			r := NewRuntimeWithConfig(testCtx, config)
+			defer r.Close(testCtx)
+
+			mod, err := r.InstantiateModuleFromBinary(testCtx, bin)
+			require.NoError(t, err)
+
+			require.True(t, mod.Memory().Write(0, sentinel))
+			global := mod.ExportedGlobal("global")
+
+			require.NoError(t, mod.Close(testCtx))
+
+			if tc.zeroOnClose {
+				require.Equal(t, make([]byte, len(buf)), buf)
+				require.Zero(t, global.Get())
+			} else {
+				require.Equal(t, sentinel, buf[:len(sentinel)])
+				require.Equal(t, uint64(42), global.Get())
+			}
+		})
+	}
+}
+
+func TestRuntime_InstantiateModule_ZeroOnClose_imported(t *testing.T) {
+	r := NewRuntimeWithConfig(testCtx, NewRuntimeConfig().WithZeroOnClose(true))
+	defer r.Close(testCtx)
+
+	exporter, err := r.InstantiateModuleFromBinary(testCtx, binaryformat.EncodeModule(&wasm.Module{
+		MemorySection: &wasm.Memory{Min: 1},
+		ExportSection: []*wasm.Export{{Type: wasm.ExternTypeMemory, Name: "memory", Index: 0}},
+		NameSection:   &wasm.NameSection{ModuleName: "env"},
+	}))
+	require.NoError(t, err)
+
+	importer, err := r.InstantiateModuleFromBinary(testCtx, binaryformat.EncodeModule(&wasm.Module{
+		ImportSection: []*wasm.Import{{
+			Module: "env", Name: "memory", Type: wasm.ExternTypeMemory,
+			DescMem: &wasm.Memory{Min: 1},
+		}},
+	}))
+	require.NoError(t, err)
+
+	sentinel := []byte("secret")
+	require.True(t, importer.Memory().Write(0, sentinel))
+
+	// Closing the exporter must not zero memory the importer still uses.
+	require.NoError(t, exporter.Close(testCtx))
+	actual, ok := importer.Memory().Read(0, uint32(len(sentinel)))
+	require.True(t, ok)
+	require.Equal(t, sentinel, actual)
+}
+
 func TestRuntime_Close_ClosesCompiledModules(t *testing.T) {
 	for _, tc := range []struct {
 		name                 string
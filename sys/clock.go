@@ -11,6 +11,25 @@ type ClockResolution uint32
 // Walltime returns the current time in epoch seconds with a nanosecond fraction.
 type Walltime func() (sec int64, nsec int32)
 
+// NewWalltime returns a Walltime which delegates to now, a function returning
+// epoch nanoseconds. This allows the same time source to back both a host
+// function the guest imports, such as "env.now", and WASI clocks.
+//
+// For example, to share a custom clock with a guest import:
+//
+//	now := func() int64 { return clock.UnixNano() }
+//	_, err := r.NewHostModuleBuilder("env").
+//		NewFunctionBuilder().WithFunc(func(context.Context) int64 { return now() }).Export("now").
+//		Instantiate(ctx)
+//	// Handle err, then configure the WASI clocks with the same source.
+//	moduleConfig = moduleConfig.WithWalltime(sys.NewWalltime(now), resolution)
+func NewWalltime(now func() int64) Walltime {
+	return func() (sec int64, nsec int32) {
+		ns := now()
+		return ns / 1e9, int32(ns % 1e9)
+	}
+}
+
 // Nanotime returns nanoseconds since an arbitrary start point, used to measure
 // elapsed time. This is sometimes referred to as a tick or monotonic time.
 //
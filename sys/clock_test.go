@@ -0,0 +1,15 @@
+package sys
+
+import (
+	"testing"
+
+	"github.com/tetratelabs/wazero/internal/testing/require"
+)
+
+func TestNewWalltime(t *testing.T) {
+	walltime := NewWalltime(func() int64 { return 1640995200_123456789 })
+
+	sec, nsec := walltime()
+	require.Equal(t, int64(1640995200), sec)
+	require.Equal(t, int32(123456789), nsec)
+}
@@ -38,10 +38,23 @@ func (e *ExitError) ModuleName() string {
 }
 
 // ExitCode returns zero on success, and an arbitrary value otherwise.
+//
+// Note: This is the full uint32 value passed to the closing function, such as
+// "proc_exit". Use ExitCodeByte for the value a POSIX shell would observe.
 func (e *ExitError) ExitCode() uint32 {
 	return e.exitCode
 }
 
+// ExitCodeByte returns the low 8 bits of ExitCode, matching POSIX shell
+// conventions where exit codes are in the range 0-255. For example, an exit
+// code of 256 has ExitCodeByte of zero.
+//
+// This is useful when propagating the exit code to os.Exit, where values
+// outside that range are not portable.
+func (e *ExitError) ExitCodeByte() byte {
+	return byte(e.exitCode & 0xff)
+}
+
 // Error implements the error interface.
 func (e *ExitError) Error() string {
 	return fmt.Sprintf("module %q closed with exit_code(%d)", e.moduleName, e.exitCode)
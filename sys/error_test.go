@@ -2,6 +2,7 @@ package sys
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/tetratelabs/wazero/internal/testing/require"
@@ -61,3 +62,25 @@ func TestIs(t *testing.T) {
 		})
 	}
 }
+
+func TestExitError_ExitCodeByte(t *testing.T) {
+	tests := []struct {
+		exitCode         uint32
+		expectedExitByte byte
+	}{
+		{exitCode: 0, expectedExitByte: 0},
+		{exitCode: 1, expectedExitByte: 1},
+		{exitCode: 255, expectedExitByte: 255},
+		{exitCode: 256, expectedExitByte: 0},
+		{exitCode: 257, expectedExitByte: 1},
+	}
+
+	for _, tt := range tests {
+		tc := tt
+		t.Run(fmt.Sprint(tc.exitCode), func(t *testing.T) {
+			err := NewExitError("some module", tc.exitCode)
+			require.Equal(t, tc.exitCode, err.ExitCode())
+			require.Equal(t, tc.expectedExitByte, err.ExitCodeByte())
+		})
+	}
+}